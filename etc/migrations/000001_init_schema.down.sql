@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `midtrans_transactions`;
+DROP TABLE IF EXISTS `transactions`;
+DROP TABLE IF EXISTS `carts`;
+DROP TABLE IF EXISTS `products`;
+DROP TABLE IF EXISTS `categories`;
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,79 @@
+-- Baseline of the schema gorm AutoMigrate created before the migrations.
+-- Tables are created only when missing so those databases adopt it, every
+-- column added since has a migration of its own.
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `username` longtext,
+  `password` longtext,
+  `name` longtext,
+  PRIMARY KEY (`id`),
+  INDEX `idx_users_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `categories` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` longtext,
+  PRIMARY KEY (`id`),
+  INDEX `idx_categories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `products` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `category_id` bigint unsigned,
+  `name` longtext,
+  `description` longtext,
+  `price` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_products_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `carts` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `user_id` bigint unsigned,
+  `product_id` bigint unsigned,
+  `transaction_id` bigint unsigned,
+  `qty` bigint,
+  `status` longtext,
+  `final_price_per_item` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_carts_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `transactions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `user_id` bigint unsigned,
+  `address_ship` longtext,
+  `total_price` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_transactions_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `midtrans_transactions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `transaction_id` bigint unsigned,
+  `midtrans_id` longtext,
+  `order_id` longtext,
+  `payment_type` bigint,
+  `status` longtext,
+  `payment_data` longtext,
+  PRIMARY KEY (`id`),
+  INDEX `idx_midtrans_transactions_deleted_at` (`deleted_at`)
+);
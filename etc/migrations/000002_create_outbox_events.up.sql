@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `outbox_events` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `type` varchar(191) NOT NULL,
+  `aggregate_id` bigint unsigned,
+  `payload` longtext,
+  `status` varchar(191) DEFAULT 'pending',
+  `attempts` bigint DEFAULT 0,
+  `next_attempt_at` datetime(3) NULL,
+  `last_error` longtext,
+  `dispatched_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_outbox_events_due` (`status`, `next_attempt_at`)
+);
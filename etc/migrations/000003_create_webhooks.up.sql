@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS `webhook_subscriptions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `url` longtext,
+  `secret` longtext,
+  `event_types` longtext,
+  `is_active` boolean,
+  PRIMARY KEY (`id`),
+  INDEX `idx_webhook_subscriptions_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `subscription_id` bigint unsigned,
+  `event_id` bigint unsigned,
+  `event_type` varchar(191),
+  `payload` longtext,
+  `status` varchar(191) DEFAULT 'pending',
+  `attempts` bigint DEFAULT 0,
+  `next_attempt_at` datetime(3) NULL,
+  `response_status` bigint,
+  `response_body` longtext,
+  `last_error` longtext,
+  `delivered_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_webhook_deliveries_event` (`subscription_id`, `event_id`),
+  INDEX `idx_webhook_deliveries_due` (`status`, `next_attempt_at`)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `refunds` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `transaction_id` bigint unsigned,
+  `order_id` longtext,
+  `refund_key` varchar(191),
+  `amount` bigint,
+  `reason` longtext,
+  `items` longtext,
+  `status` varchar(191) DEFAULT 'pending',
+  `user_id` bigint unsigned,
+  `last_error` longtext,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_refunds_refund_key` (`refund_key`),
+  INDEX `idx_refunds_transaction_id` (`transaction_id`),
+  INDEX `idx_refunds_deleted_at` (`deleted_at`)
+);
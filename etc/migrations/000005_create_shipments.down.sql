@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS `shipments`;
+
+ALTER TABLE `transactions`
+  DROP COLUMN `shipping_courier`,
+  DROP COLUMN `shipping_service`,
+  DROP COLUMN `shipping_cost`;
+
+ALTER TABLE `products` DROP COLUMN `weight`;
@@ -0,0 +1,25 @@
+ALTER TABLE `products` ADD COLUMN `weight` bigint DEFAULT 0;
+
+ALTER TABLE `transactions`
+  ADD COLUMN `shipping_courier` longtext,
+  ADD COLUMN `shipping_service` longtext,
+  ADD COLUMN `shipping_cost` bigint DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS `shipments` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `transaction_id` bigint unsigned,
+  `courier` longtext,
+  `service` longtext,
+  `tracking_number` longtext,
+  `items` longtext,
+  `status` varchar(191) DEFAULT 'shipped',
+  `shipped_at` datetime(3) NULL,
+  `delivered_at` datetime(3) NULL,
+  `user_id` bigint unsigned,
+  PRIMARY KEY (`id`),
+  INDEX `idx_shipments_transaction_id` (`transaction_id`),
+  INDEX `idx_shipments_deleted_at` (`deleted_at`)
+);
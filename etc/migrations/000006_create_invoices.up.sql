@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `invoices` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `transaction_id` bigint unsigned,
+  `number` varchar(50),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_invoices_transaction_id` (`transaction_id`),
+  INDEX `idx_invoices_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `invoice_counters` (
+  `prefix` varchar(20) NOT NULL,
+  `year` bigint NOT NULL,
+  `last` bigint,
+  PRIMARY KEY (`prefix`, `year`)
+);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS `addresses`;
+
+ALTER TABLE `transactions`
+  DROP COLUMN `ship_recipient`,
+  DROP COLUMN `ship_phone`,
+  DROP COLUMN `ship_street`,
+  DROP COLUMN `ship_city`,
+  DROP COLUMN `ship_province`,
+  DROP COLUMN `ship_postal_code`;
+
+ALTER TABLE `users`
+  DROP COLUMN `email`,
+  DROP COLUMN `phone`;
@@ -0,0 +1,28 @@
+ALTER TABLE `users`
+  ADD COLUMN `email` longtext,
+  ADD COLUMN `phone` longtext;
+
+ALTER TABLE `transactions`
+  ADD COLUMN `ship_recipient` longtext,
+  ADD COLUMN `ship_phone` longtext,
+  ADD COLUMN `ship_street` longtext,
+  ADD COLUMN `ship_city` longtext,
+  ADD COLUMN `ship_province` longtext,
+  ADD COLUMN `ship_postal_code` longtext;
+
+CREATE TABLE IF NOT EXISTS `addresses` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `user_id` bigint unsigned,
+  `recipient` longtext,
+  `phone` longtext,
+  `street` longtext,
+  `city` longtext,
+  `province` longtext,
+  `postal_code` longtext,
+  `is_default` boolean,
+  PRIMARY KEY (`id`),
+  INDEX `idx_addresses_deleted_at` (`deleted_at`)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE `users`
+  DROP COLUMN `role`,
+  DROP COLUMN `mfa_enabled`,
+  DROP COLUMN `mfa_secret`,
+  DROP COLUMN `mfa_recovery_codes`;
@@ -0,0 +1,5 @@
+ALTER TABLE `users`
+  ADD COLUMN `role` varchar(191) DEFAULT 'customer',
+  ADD COLUMN `mfa_enabled` boolean,
+  ADD COLUMN `mfa_secret` longtext,
+  ADD COLUMN `mfa_recovery_codes` longtext;
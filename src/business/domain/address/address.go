@@ -0,0 +1,120 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/sql"
+
+	"gorm.io/gorm"
+)
+
+type Interface interface {
+	Create(ctx context.Context, address entity.Address) (entity.Address, error)
+	GetList(ctx context.Context, param entity.AddressParam) ([]entity.Address, error)
+	Get(ctx context.Context, param entity.AddressParam) (entity.Address, error)
+	Update(ctx context.Context, selectParam entity.AddressParam, updateParam entity.UpdateAddressParam) error
+	SetDefault(ctx context.Context, param entity.AddressParam) error
+	Delete(ctx context.Context, param entity.AddressParam) error
+}
+
+type address struct {
+	db *gorm.DB
+}
+
+func Init(db *gorm.DB) Interface {
+	a := &address{
+		db: db,
+	}
+
+	return a
+}
+
+func (a *address) Create(ctx context.Context, address entity.Address) (entity.Address, error) {
+	if err := sql.Conn(ctx, a.db).Create(&address).Error; err != nil {
+		return address, err
+	}
+
+	return address, nil
+}
+
+func (a *address) GetList(ctx context.Context, param entity.AddressParam) ([]entity.Address, error) {
+	addresses := []entity.Address{}
+	if err := sql.Conn(ctx, a.db).Where(param).Order("is_default desc").Find(&addresses).Error; err != nil {
+		return addresses, err
+	}
+
+	return addresses, nil
+}
+
+func (a *address) Get(ctx context.Context, param entity.AddressParam) (entity.Address, error) {
+	address := entity.Address{}
+	if err := sql.Conn(ctx, a.db).Where(param).First(&address).Error; err != nil {
+		return address, err
+	}
+
+	return address, nil
+}
+
+func (a *address) Update(ctx context.Context, selectParam entity.AddressParam, updateParam entity.UpdateAddressParam) error {
+	if err := sql.Conn(ctx, a.db).Model(entity.Address{}).Where(selectParam).Updates(updateParam).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetDefault marks the address as the user's default and clears the flag on
+// every other address of the same user.
+func (a *address) SetDefault(ctx context.Context, param entity.AddressParam) error {
+	if param.ID == 0 || param.UserID == 0 {
+		return apperror.Validation("address id and user id are required").WithKey("address.ids_required")
+	}
+
+	return sql.Conn(ctx, a.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(entity.Address{}).Where("user_id = ? AND id <> ?", param.UserID, param.ID).Update("is_default", false).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(entity.Address{}).Where("user_id = ? AND id = ?", param.UserID, param.ID).Update("is_default", true).Error; err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Delete removes the address. When it was the user's default, the most
+// recent remaining address becomes the default in the same transaction.
+func (a *address) Delete(ctx context.Context, param entity.AddressParam) error {
+	return sql.Conn(ctx, a.db).Transaction(func(tx *gorm.DB) error {
+		address := entity.Address{}
+		if err := tx.Where(param).First(&address).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.NotFound("data not found to be deleted").WithKey("error.delete_not_found")
+			}
+			return err
+		}
+
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+
+		if !address.IsDefault {
+			return nil
+		}
+
+		next := entity.Address{}
+		if err := tx.Where("user_id = ?", address.UserID).Order("id desc").Limit(1).Find(&next).Error; err != nil {
+			return err
+		}
+
+		// the last address of the user was deleted
+		if next.ID == 0 {
+			return nil
+		}
+
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
@@ -0,0 +1,553 @@
+package address
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"go-clean/src/business/entity"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func Test_address_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	querySql := "INSERT INTO"
+	query := regexp.QuoteMeta(querySql)
+
+	mockAddress := entity.Address{
+		UserID:    1,
+		Recipient: "mail",
+		City:      "purwakarta",
+	}
+
+	type args struct {
+		address entity.Address
+	}
+	tests := []struct {
+		name        string
+		args        args
+		prepSqlMock func() (*sql.DB, error)
+		want        entity.Address
+		wantErr     bool
+	}{
+		{
+			name: "failed to create address",
+			args: args{
+				address: mockAddress,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    mockAddress,
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				address: mockAddress,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(1, 1))
+				sqlMock.ExpectCommit()
+				sqlMock.ExpectationsWereMet()
+				return sqlServer, err
+			},
+			want:    mockAddress,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			a := Init(sqlClient)
+			_, err = a.Create(context.Background(), tt.args.address)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_address_GetList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	querySql := "SELECT * FROM `addresses` WHERE `addresses`.`deleted_at` IS NULL ORDER BY is_default desc"
+	query := regexp.QuoteMeta(querySql)
+
+	mockParam := entity.AddressParam{}
+
+	type args struct {
+		param entity.AddressParam
+	}
+	tests := []struct {
+		name        string
+		args        args
+		prepSqlMock func() (*sql.DB, error)
+		want        []entity.Address
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    []entity.Address{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				row := sqlmock.NewRows([]string{"user_id"})
+				row.AddRow(1)
+				sqlMock.ExpectQuery(query).WillReturnRows(row)
+				return sqlServer, err
+			},
+			want: []entity.Address{
+				{
+					UserID: 1,
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			a := Init(sqlClient)
+			got, err := a.GetList(context.Background(), tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.GetList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_address_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	querySql := "SELECT * FROM `addresses` WHERE `addresses`.`user_id` = ? AND `addresses`.`deleted_at` IS NULL ORDER BY `addresses`.`id` LIMIT 1"
+	query := regexp.QuoteMeta(querySql)
+
+	mockParam := entity.AddressParam{
+		UserID: 1,
+	}
+
+	type args struct {
+		param entity.AddressParam
+	}
+	tests := []struct {
+		name        string
+		args        args
+		prepSqlMock func() (*sql.DB, error)
+		want        entity.Address
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    entity.Address{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				row := sqlmock.NewRows([]string{"user_id"})
+				row.AddRow(1)
+				sqlMock.ExpectQuery(query).WillReturnRows(row)
+				return sqlServer, err
+			},
+			want: entity.Address{
+				UserID: 1,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			a := Init(sqlClient)
+			got, err := a.Get(context.Background(), tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_address_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	querySql := "UPDATE"
+	query := regexp.QuoteMeta(querySql)
+
+	mockSelectParam := entity.AddressParam{
+		ID: 1,
+	}
+	mockUpdateParam := entity.UpdateAddressParam{
+		City: "bandung",
+	}
+
+	type args struct {
+		selectParam entity.AddressParam
+		updateParam entity.UpdateAddressParam
+	}
+	tests := []struct {
+		name        string
+		args        args
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			args: args{
+				selectParam: mockSelectParam,
+				updateParam: mockUpdateParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				selectParam: mockSelectParam,
+				updateParam: mockUpdateParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				sqlMock.ExpectationsWereMet()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			a := Init(sqlClient)
+			err = a.Update(context.Background(), tt.args.selectParam, tt.args.updateParam)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_address_SetDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	querySql := "UPDATE `addresses` SET `is_default`=?"
+	query := regexp.QuoteMeta(querySql)
+
+	mockParam := entity.AddressParam{
+		ID:     1,
+		UserID: 1,
+	}
+
+	type args struct {
+		param entity.AddressParam
+	}
+	tests := []struct {
+		name        string
+		args        args
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name: "missing id",
+			args: args{
+				param: entity.AddressParam{
+					UserID: 1,
+				},
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, _, err := sqlmock.New()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to unset other default",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(false, sqlmock.AnyArg(), 1, 1).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectExec(query).WithArgs(true, sqlmock.AnyArg(), 1, 1).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			a := Init(sqlClient)
+			err = a.SetDefault(context.Background(), tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.SetDefault() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_address_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `addresses` WHERE `addresses`.`id` = ? AND `addresses`.`user_id` = ? AND `addresses`.`deleted_at` IS NULL ORDER BY `addresses`.`id` LIMIT 1")
+	deleteQuery := regexp.QuoteMeta("UPDATE `addresses` SET `deleted_at`=? WHERE `addresses`.`id` = ? AND `addresses`.`deleted_at` IS NULL")
+	nextQuery := regexp.QuoteMeta("SELECT * FROM `addresses` WHERE user_id = ? AND `addresses`.`deleted_at` IS NULL ORDER BY id desc LIMIT 1")
+	defaultQuery := regexp.QuoteMeta("UPDATE `addresses` SET `is_default`=?,`updated_at`=? WHERE `addresses`.`deleted_at` IS NULL AND `id` = ?")
+
+	mockParam := entity.AddressParam{
+		ID:     1,
+		UserID: 1,
+	}
+
+	type args struct {
+		param entity.AddressParam
+	}
+	tests := []struct {
+		name        string
+		args        args
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectQuery(selectQuery).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "address not found",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectQuery(selectQuery).WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id", "user_id", "is_default"}).AddRow(1, 1, false)
+				sqlMock.ExpectQuery(selectQuery).WithArgs(1, 1).WillReturnRows(rows)
+				sqlMock.ExpectExec(deleteQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+		{
+			name: "all ok last address was the default",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id", "user_id", "is_default"}).AddRow(1, 1, true)
+				sqlMock.ExpectQuery(selectQuery).WithArgs(1, 1).WillReturnRows(rows)
+				sqlMock.ExpectExec(deleteQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectQuery(nextQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+		{
+			name: "all ok most recent address becomes the default",
+			args: args{
+				param: mockParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"id", "user_id", "is_default"}).AddRow(1, 1, true)
+				sqlMock.ExpectQuery(selectQuery).WithArgs(1, 1).WillReturnRows(rows)
+				sqlMock.ExpectExec(deleteQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(driver.RowsAffected(1))
+				nextRows := sqlmock.NewRows([]string{"id", "user_id", "is_default"}).AddRow(3, 1, false)
+				sqlMock.ExpectQuery(nextQuery).WithArgs(1).WillReturnRows(nextRows)
+				sqlMock.ExpectExec(defaultQuery).WithArgs(true, sqlmock.AnyArg(), 3).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			a := Init(sqlClient)
+			err = a.Delete(context.Background(), tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.Delete() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
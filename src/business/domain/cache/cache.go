@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"go-clean/src/lib/redis"
+)
+
+const (
+	namespacePattern = `synapsis:%s:*`
+)
+
+type Interface interface {
+	Flush(ctx context.Context, namespace string) (int64, error)
+}
+
+type cache struct {
+	redis redis.Interface
+}
+
+func Init(redis redis.Interface) Interface {
+	c := &cache{
+		redis: redis,
+	}
+
+	return c
+}
+
+// Flush deletes every key of the namespace and every key tagged with it, and
+// returns the number of deleted keys.
+func (c *cache) Flush(ctx context.Context, namespace string) (int64, error) {
+	deleted, err := c.redis.DelByPattern(ctx, fmt.Sprintf(namespacePattern, namespace))
+	if err != nil {
+		return deleted, err
+	}
+
+	tagged, err := c.redis.InvalidateTags(ctx, namespace)
+	if err != nil {
+		return deleted, err
+	}
+
+	return deleted + tagged, nil
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	mock_redis "go-clean/src/lib/tests/mock/redis"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_cache_Flush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis := mock_redis.NewMockInterface(ctrl)
+
+	pattern := fmt.Sprintf(namespacePattern, "product")
+
+	type mockFields struct {
+		redis *mock_redis.MockInterface
+	}
+
+	mocks := mockFields{
+		redis: mockRedis,
+	}
+
+	type args struct {
+		ctx       context.Context
+		namespace string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		mockFunc func(mock mockFields)
+		want     int64
+		wantErr  bool
+	}{
+		{
+			name: "failed to delete by pattern",
+			args: args{
+				ctx:       context.Background(),
+				namespace: "product",
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().DelByPattern(context.Background(), pattern).Return(int64(0), assert.AnError)
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "failed to invalidate tag",
+			args: args{
+				ctx:       context.Background(),
+				namespace: "product",
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().DelByPattern(context.Background(), pattern).Return(int64(3), nil)
+				mock.redis.EXPECT().InvalidateTags(context.Background(), "product").Return(int64(0), assert.AnError)
+			},
+			want:    3,
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				ctx:       context.Background(),
+				namespace: "product",
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().DelByPattern(context.Background(), pattern).Return(int64(3), nil)
+				mock.redis.EXPECT().InvalidateTags(context.Background(), "product").Return(int64(0), nil)
+			},
+			want:    3,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			c := Init(mockRedis)
+			got, err := c.Flush(tt.args.ctx, tt.args.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("cache.Flush() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
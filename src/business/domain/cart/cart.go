@@ -1,18 +1,20 @@
 package cart
 
 import (
-	"errors"
+	"context"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/sql"
 
 	"gorm.io/gorm"
 )
 
 type Interface interface {
-	Create(cart entity.Cart) (entity.Cart, error)
-	GetList(param entity.CartParam) ([]entity.Cart, error)
-	Get(param entity.CartParam) (entity.Cart, error)
-	Update(selectParam entity.CartParam, updateParam entity.UpdateCartParam) error
-	Delete(param entity.CartParam) error
+	Create(ctx context.Context, cart entity.Cart) (entity.Cart, error)
+	GetList(ctx context.Context, param entity.CartParam) ([]entity.Cart, error)
+	Get(ctx context.Context, param entity.CartParam) (entity.Cart, error)
+	Update(ctx context.Context, selectParam entity.CartParam, updateParam entity.UpdateCartParam) error
+	Delete(ctx context.Context, param entity.CartParam) error
 }
 
 type cart struct {
@@ -27,43 +29,43 @@ func Init(db *gorm.DB) Interface {
 	return c
 }
 
-func (c *cart) Create(cart entity.Cart) (entity.Cart, error) {
-	if err := c.db.Create(&cart).Error; err != nil {
+func (c *cart) Create(ctx context.Context, cart entity.Cart) (entity.Cart, error) {
+	if err := sql.Conn(ctx, c.db).Create(&cart).Error; err != nil {
 		return cart, err
 	}
 
 	return cart, nil
 }
 
-func (c *cart) GetList(param entity.CartParam) ([]entity.Cart, error) {
+func (c *cart) GetList(ctx context.Context, param entity.CartParam) ([]entity.Cart, error) {
 	carts := []entity.Cart{}
-	if err := c.db.Where(param).Find(&carts).Error; err != nil {
+	if err := sql.Conn(ctx, c.db).Where(param).Find(&carts).Error; err != nil {
 		return carts, err
 	}
 
 	return carts, nil
 }
 
-func (c *cart) Get(param entity.CartParam) (entity.Cart, error) {
+func (c *cart) Get(ctx context.Context, param entity.CartParam) (entity.Cart, error) {
 	cart := entity.Cart{}
-	if err := c.db.Where(param).First(&cart).Error; err != nil {
+	if err := sql.Conn(ctx, c.db).Where(param).First(&cart).Error; err != nil {
 		return cart, err
 	}
 
 	return cart, nil
 }
 
-func (c *cart) Update(selectParam entity.CartParam, updateParam entity.UpdateCartParam) error {
-	if err := c.db.Model(entity.Cart{}).Where(selectParam).Updates(updateParam).Error; err != nil {
+func (c *cart) Update(ctx context.Context, selectParam entity.CartParam, updateParam entity.UpdateCartParam) error {
+	if err := sql.Conn(ctx, c.db).Model(entity.Cart{}).Where(selectParam).Updates(updateParam).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *cart) Delete(param entity.CartParam) error {
-	if rowsAffected := c.db.Where(param).Delete(&entity.Cart{}).RowsAffected; rowsAffected == 0 {
-		return errors.New("data not found to be deleted")
+func (c *cart) Delete(ctx context.Context, param entity.CartParam) error {
+	if rowsAffected := sql.Conn(ctx, c.db).Where(param).Delete(&entity.Cart{}).RowsAffected; rowsAffected == 0 {
+		return apperror.NotFound("data not found to be deleted").WithKey("error.delete_not_found")
 	}
 
 	return nil
@@ -1,6 +1,7 @@
 package cart
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"go-clean/src/business/entity"
@@ -84,7 +85,7 @@ func Test_cart_Create(t *testing.T) {
 			}
 
 			c := Init(sqlClient)
-			_, err = c.Create(tt.args.cart)
+			_, err = c.Create(context.Background(), tt.args.cart)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("cart.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -162,7 +163,7 @@ func Test_cart_GetList(t *testing.T) {
 			}
 
 			c := Init(sqlClient)
-			got, err := c.GetList(tt.args.param)
+			got, err := c.GetList(context.Background(), tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("cart.GetList() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -241,7 +242,7 @@ func Test_cart_Get(t *testing.T) {
 			}
 
 			c := Init(sqlClient)
-			got, err := c.Get(tt.args.param)
+			got, err := c.Get(context.Background(), tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("cart.Get() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -322,7 +323,7 @@ func Test_cart_Update(t *testing.T) {
 			}
 
 			c := Init(sqlClient)
-			err = c.Update(tt.args.selectParam, tt.args.updateParam)
+			err = c.Update(context.Background(), tt.args.selectParam, tt.args.updateParam)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("cart.Get() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -396,7 +397,7 @@ func Test_cart_Delete(t *testing.T) {
 			}
 
 			c := Init(sqlClient)
-			err = c.Delete(tt.args.param)
+			err = c.Delete(context.Background(), tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("cart.Delete() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -2,10 +2,12 @@ package category
 
 import (
 	"context"
-	"errors"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"go-clean/src/lib/redis"
-	"log"
+	"go-clean/src/lib/sql"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,41 +15,66 @@ import (
 
 type Interface interface {
 	GetList(ctx context.Context) ([]entity.Category, error)
+	SetConfig(cfg Config)
+}
+
+// Config can be changed on a running instance through SetConfig.
+type Config struct {
+	// CacheTTL is how long a category read is cached, defaults to a minute.
+	CacheTTL time.Duration
+	// CacheStaleTTL is how long an expired read is still served while it is
+	// refreshed in the background, zero disables it.
+	CacheStaleTTL time.Duration
 }
 
 type cateogry struct {
-	db    *gorm.DB
-	redis redis.Interface
+	mu      sync.RWMutex
+	conf    Config
+	log     log.Interface
+	metrics metrics.Interface
+	db      *gorm.DB
+	redis   redis.Interface
 }
 
-func Init(db *gorm.DB, redis redis.Interface) Interface {
+func Init(cfg Config, log log.Interface, metrics metrics.Interface, db *gorm.DB, redis redis.Interface) Interface {
 	c := &cateogry{
-		db:    db,
-		redis: redis,
+		conf:    withDefault(cfg),
+		log:     log,
+		metrics: metrics,
+		db:      db,
+		redis:   redis,
 	}
 
 	return c
 }
 
 func (c *cateogry) GetList(ctx context.Context) ([]entity.Category, error) {
-	cacheResult, err := c.getCacheList(ctx)
-	switch {
-	case errors.Is(err, redis.Nil):
-		log.Printf("error redis is nil, %s\n", err.Error())
-	case err != nil:
-		log.Printf("error redis : %s\n", err.Error())
-	default:
-		return cacheResult, nil
-	}
-
 	categories := []entity.Category{}
-	if err := c.db.Find(&categories).Error; err != nil {
+	err := c.readThrough(ctx, getCategoryList, &categories, func(ctx context.Context) (interface{}, error) {
+		categories := []entity.Category{}
+		err := sql.Conn(ctx, c.db).Find(&categories).Error
 		return categories, err
-	}
+	})
+
+	return categories, err
+}
+
+func (c *cateogry) SetConfig(cfg Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conf = withDefault(cfg)
+}
+
+func (c *cateogry) config() Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conf
+}
 
-	if err := c.upsertCacheList(ctx, categories, time.Minute); err != nil {
-		log.Println(err.Error())
+func withDefault(cfg Config) Config {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = time.Minute
 	}
 
-	return categories, nil
+	return cfg
 }
@@ -2,41 +2,59 @@ package category
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"go-clean/src/business/entity"
-	"time"
+	"go-clean/src/lib/metrics"
+	"go-clean/src/lib/redis"
 )
 
 const (
+	categoryCache = "category"
+
 	getCategoryList = `synapsis:category:get`
 )
 
-func (c *cateogry) getCacheList(ctx context.Context) ([]entity.Category, error) {
-	result := []entity.Category{}
-
-	categoriesRedis, err := c.redis.Get(ctx, getCategoryList)
-	if err != nil {
-		return result, err
+// readThrough fills dest from the cache at key, load runs on a miss and its
+// result is cached for the next callers.
+func (c *cateogry) readThrough(ctx context.Context, key string, dest interface{}, load func(ctx context.Context) (interface{}, error)) error {
+	conf := c.config()
+	result, err := redis.ReadThroughJSON(ctx, c.redis, key, redis.ReadThroughOptions{
+		TTL:      conf.CacheTTL,
+		StaleTTL: conf.CacheStaleTTL,
+	}, dest, load)
+
+	if result.GetErr != nil {
+		c.log.Error(ctx, "failed to get category cache", "error", result.GetErr)
 	}
-
-	data := []byte(categoriesRedis)
-	if err := json.Unmarshal(data, &result); err != nil {
-		return result, fmt.Errorf("failed to unmarshal redis : %v", err.Error())
+	if result.SetErr != nil {
+		c.log.Error(ctx, "failed to set category cache", "error", result.SetErr)
+	}
+	if result.Status == redis.ReadThroughMiss {
+		c.log.Debug(ctx, "category cache miss")
+		c.observeCacheSet(result.SetErr)
 	}
+	c.observeCacheGet(result)
 
-	return result, nil
+	return err
 }
 
-func (c *cateogry) upsertCacheList(ctx context.Context, value []entity.Category, expTime time.Duration) error {
-	categories, err := json.Marshal(value)
-	if err != nil {
-		return err
+func (c *cateogry) observeCacheGet(r redis.ReadThroughResult) {
+	result := metrics.CacheHit
+	switch {
+	case r.GetErr != nil:
+		result = metrics.CacheError
+	case r.Status == redis.ReadThroughStale:
+		result = metrics.CacheStale
+	case r.Status != redis.ReadThroughHit:
+		result = metrics.CacheMiss
 	}
 
-	if err := c.redis.SetEX(ctx, getCategoryList, string(categories), expTime); err != nil {
-		return err
+	c.metrics.IncCache(categoryCache, metrics.CacheOperationGet, result)
+}
+
+func (c *cateogry) observeCacheSet(err error) {
+	result := metrics.CacheOK
+	if err != nil {
+		result = metrics.CacheError
 	}
 
-	return nil
+	c.metrics.IncCache(categoryCache, metrics.CacheOperationSet, result)
 }
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"regexp"
 	"testing"
 	"time"
@@ -36,6 +38,10 @@ func Test_category_GetList(t *testing.T) {
 	marshalledCategories, _ := json.Marshal(categoriesMock)
 	stringCategoriesMock := string(marshalledCategories)
 
+	readThroughOpt := redis.ReadThroughOptions{
+		TTL: time.Minute,
+	}
+
 	type mockFields struct {
 		redis *mock_redis.MockInterface
 	}
@@ -66,25 +72,28 @@ func Test_category_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), getCategoryList).Return(stringCategoriesMock, nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), getCategoryList, readThroughOpt, gomock.Any()).Return(redis.ReadThroughResult{Value: stringCategoriesMock, Status: redis.ReadThroughHit}, nil)
 			},
 			want:    categoriesMock,
 			wantErr: false,
 		},
 		{
-			name: "failed to get cache list",
+			name: "failed to get cache but loaded from db",
 			args: args{
 				ctx: context.Background(),
 			},
 			prepSqlMock: func() (*sql.DB, error) {
-				sqlServer, _, err := sqlmock.New()
+				sqlServer, sqlMock, err := sqlmock.New()
+				row := sqlmock.NewRows([]string{"name"})
+				row.AddRow("category 1")
+				sqlMock.ExpectQuery(query).WillReturnRows(row)
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), getCategoryList).Return("", assert.AnError)
+				mock.redis.EXPECT().ReadThrough(context.Background(), getCategoryList, readThroughOpt, gomock.Any()).DoAndReturn(readThrough(assert.AnError, nil))
 			},
-			want:    []entity.Category{},
-			wantErr: true,
+			want:    categoriesMock,
+			wantErr: false,
 		},
 		{
 			name: "failed to exec query",
@@ -97,7 +106,7 @@ func Test_category_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), getCategoryList).Return("", redis.Nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), getCategoryList, readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, nil))
 			},
 			want:    []entity.Category{},
 			wantErr: true,
@@ -115,8 +124,7 @@ func Test_category_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), getCategoryList).Return("", redis.Nil)
-				mock.redis.EXPECT().SetEX(context.Background(), getCategoryList, string(marshalledCategories), time.Minute).Return(assert.AnError)
+				mock.redis.EXPECT().ReadThrough(context.Background(), getCategoryList, readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, assert.AnError))
 			},
 			want: []entity.Category{
 				{
@@ -138,8 +146,7 @@ func Test_category_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), getCategoryList).Return("", redis.Nil)
-				mock.redis.EXPECT().SetEX(context.Background(), getCategoryList, string(marshalledCategories), time.Minute).Return(nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), getCategoryList, readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, nil))
 			},
 			want: []entity.Category{
 				{
@@ -166,7 +173,7 @@ func Test_category_GetList(t *testing.T) {
 				t.Error(err)
 			}
 
-			u := Init(sqlClient, mockRedis)
+			u := Init(Config{}, log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), sqlClient, mockRedis)
 			got, err := u.GetList(tt.args.ctx)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("category.GetList() error = %v, wantErr %v", err, tt.wantErr)
@@ -176,3 +183,21 @@ func Test_category_GetList(t *testing.T) {
 		})
 	}
 }
+
+// readThrough mimics a cache miss of ReadThrough, the value is loaded and the
+// cache errors are reported back.
+func readThrough(getErr, setErr error) func(ctx context.Context, key string, opt redis.ReadThroughOptions, load redis.LoadFunc) (redis.ReadThroughResult, error) {
+	return func(ctx context.Context, key string, opt redis.ReadThroughOptions, load redis.LoadFunc) (redis.ReadThroughResult, error) {
+		result := redis.ReadThroughResult{
+			GetErr: getErr,
+		}
+
+		value, err := load(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		result.Value, result.Status, result.SetErr = value, redis.ReadThroughMiss, setErr
+		return result, nil
+	}
+}
@@ -1,15 +1,31 @@
 package domain
 
 import (
+	"go-clean/src/business/domain/address"
+	"go-clean/src/business/domain/cache"
 	"go-clean/src/business/domain/cart"
 	"go-clean/src/business/domain/category"
+	eventstream "go-clean/src/business/domain/event_stream"
+	"go-clean/src/business/domain/invoice"
+	loginattempt "go-clean/src/business/domain/login_attempt"
 	"go-clean/src/business/domain/midtrans"
 	midtranstransaction "go-clean/src/business/domain/midtrans_transaction"
+	"go-clean/src/business/domain/outbox"
 	"go-clean/src/business/domain/product"
+	"go-clean/src/business/domain/refund"
+	"go-clean/src/business/domain/report"
+	"go-clean/src/business/domain/shipment"
+	statusstream "go-clean/src/business/domain/status_stream"
 	"go-clean/src/business/domain/transaction"
 	"go-clean/src/business/domain/user"
+	"go-clean/src/business/domain/webhook"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	midtransSdk "go-clean/src/lib/midtrans"
+	"go-clean/src/lib/pdf"
 	"go-clean/src/lib/redis"
+	"go-clean/src/lib/shipping"
+	webhookSdk "go-clean/src/lib/webhook"
 
 	"gorm.io/gorm"
 )
@@ -22,18 +38,55 @@ type Domains struct {
 	Midtrans            midtrans.Interface
 	Transaction         transaction.Interface
 	MidtransTransaction midtranstransaction.Interface
+	LoginAttempt        loginattempt.Interface
+	Address             address.Interface
+	Cache               cache.Interface
+	Outbox              outbox.Interface
+	EventStream         eventstream.Interface
+	Webhook             webhook.Interface
+	StatusStream        statusstream.Interface
+	Refund              refund.Interface
+	Shipment            shipment.Interface
+	Invoice             invoice.Interface
+	Report              report.Interface
 }
 
-func Init(db *gorm.DB, m midtransSdk.Interface, redis redis.Interface) *Domains {
+type Config struct {
+	LoginAttempt loginattempt.Config
+	Product      product.Config
+	Category     category.Config
+	EventStream  eventstream.Config
+	StatusStream statusstream.Config
+	Invoice      invoice.Config
+}
+
+func Init(cfg Config, log log.Interface, metrics metrics.Interface, db *gorm.DB, m midtransSdk.Interface, redis redis.Interface, wh webhookSdk.Interface, sp shipping.Interface, pdf pdf.Interface) *Domains {
 	d := &Domains{
 		User:                user.Init(db),
-		Category:            category.Init(db, redis),
-		Product:             product.Init(db, redis),
+		Category:            category.Init(cfg.Category, log, metrics, db, redis),
+		Product:             product.Init(cfg.Product, log, metrics, db, redis),
 		Cart:                cart.Init(db),
 		Midtrans:            midtrans.Init(m),
 		Transaction:         transaction.Init(db),
 		MidtransTransaction: midtranstransaction.Init(db),
+		LoginAttempt:        loginattempt.Init(cfg.LoginAttempt, redis),
+		Address:             address.Init(db),
+		Cache:               cache.Init(redis),
+		Outbox:              outbox.Init(db),
+		EventStream:         eventstream.Init(cfg.EventStream, redis),
+		Webhook:             webhook.Init(db, wh),
+		StatusStream:        statusstream.Init(cfg.StatusStream, log, redis),
+		Refund:              refund.Init(db),
+		Shipment:            shipment.Init(db, sp),
+		Invoice:             invoice.Init(cfg.Invoice, db, pdf),
+		Report:              report.Init(db),
 	}
 
 	return d
 }
+
+// Reload applies the parts of cfg that can change without a restart.
+func (d *Domains) Reload(cfg Config) {
+	d.Product.SetConfig(cfg.Product)
+	d.Category.SetConfig(cfg.Category)
+}
@@ -0,0 +1,50 @@
+package eventstream
+
+import (
+	"context"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/redis"
+	"time"
+)
+
+type Interface interface {
+	// Publish appends the event to the redis stream. Delivery is at least
+	// once, consumers dedupe on the event id.
+	Publish(ctx context.Context, event entity.OutboxEvent) error
+}
+
+type Config struct {
+	Stream string
+	// MaxLen caps the stream at about that many entries, 0 keeps every entry.
+	MaxLen int64
+}
+
+type eventStream struct {
+	conf  Config
+	redis redis.Interface
+}
+
+func Init(cfg Config, redis redis.Interface) Interface {
+	if cfg.Stream == "" {
+		cfg.Stream = "synapsis:events"
+	}
+
+	e := &eventStream{
+		conf:  cfg,
+		redis: redis,
+	}
+
+	return e
+}
+
+func (e *eventStream) Publish(ctx context.Context, event entity.OutboxEvent) error {
+	_, err := e.redis.XAdd(ctx, e.conf.Stream, e.conf.MaxLen, map[string]interface{}{
+		"id":           event.ID,
+		"type":         event.Type,
+		"aggregate_id": event.AggregateID,
+		"payload":      event.Payload,
+		"created_at":   event.CreatedAt.Format(time.RFC3339Nano),
+	})
+
+	return err
+}
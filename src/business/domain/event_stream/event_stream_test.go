@@ -0,0 +1,90 @@
+package eventstream
+
+import (
+	"context"
+	"go-clean/src/business/entity"
+	mock_redis "go-clean/src/lib/tests/mock/redis"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_eventStream_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis := mock_redis.NewMockInterface(ctrl)
+
+	type mockFields struct {
+		redis *mock_redis.MockInterface
+	}
+
+	mocks := mockFields{
+		redis: mockRedis,
+	}
+
+	createdAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockEvent := entity.OutboxEvent{
+		ID:          1,
+		CreatedAt:   createdAt,
+		Type:        entity.EventOrderCreated,
+		AggregateID: 2,
+		Payload:     `{"transaction_id":2}`,
+	}
+	mockValues := map[string]interface{}{
+		"id":           uint(1),
+		"type":         entity.EventOrderCreated,
+		"aggregate_id": uint(2),
+		"payload":      `{"transaction_id":2}`,
+		"created_at":   createdAt.Format(time.RFC3339Nano),
+	}
+
+	type args struct {
+		ctx   context.Context
+		event entity.OutboxEvent
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		mockFunc func(mock mockFields)
+		wantErr  bool
+	}{
+		{
+			name: "failed to add to stream",
+			args: args{
+				ctx:   context.Background(),
+				event: mockEvent,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().XAdd(context.Background(), "synapsis:events", int64(1000), mockValues).Return("", assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				ctx:   context.Background(),
+				event: mockEvent,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().XAdd(context.Background(), "synapsis:events", int64(1000), mockValues).Return("1-0", nil)
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			e := Init(Config{MaxLen: 1000}, mockRedis)
+			err := e.Publish(tt.args.ctx, tt.args.event)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("eventStream.Publish() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package invoice
+
+import (
+	"context"
+	"fmt"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/pdf"
+	"go-clean/src/lib/sql"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Interface interface {
+	// Create numbers the invoice of a transaction with the next number of
+	// the year, the existing invoice is returned when it is numbered already.
+	Create(ctx context.Context, transactionID uint) (entity.Invoice, error)
+	Get(ctx context.Context, param entity.InvoiceParam) (entity.Invoice, error)
+	// Render lays doc out as a PDF.
+	Render(ctx context.Context, doc entity.InvoiceDocument) ([]byte, error)
+}
+
+type Config struct {
+	// NumberPrefix starts every invoice number, up to 20 characters. Defaults
+	// to INV.
+	NumberPrefix string
+}
+
+type invoice struct {
+	conf Config
+	db   *gorm.DB
+	pdf  pdf.Interface
+}
+
+func Init(cfg Config, db *gorm.DB, pdf pdf.Interface) Interface {
+	if cfg.NumberPrefix == "" {
+		cfg.NumberPrefix = "INV"
+	}
+
+	i := &invoice{
+		conf: cfg,
+		db:   db,
+		pdf:  pdf,
+	}
+
+	return i
+}
+
+func (i *invoice) Create(ctx context.Context, transactionID uint) (entity.Invoice, error) {
+	now := time.Now()
+	result := entity.Invoice{
+		Model:         gorm.Model{CreatedAt: now},
+		TransactionID: transactionID,
+	}
+
+	err := sql.Conn(ctx, i.db).Transaction(func(tx *gorm.DB) error {
+		// the counter of the year is locked until the invoice is committed,
+		// a number is only used by a committed invoice and numbers have no
+		// gaps
+		counter := entity.InvoiceCounter{
+			Prefix: i.conf.NumberPrefix,
+			Year:   now.Year(),
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&counter).Error; err != nil {
+			return err
+		}
+
+		next := counter.Last + 1
+		result.Number = fmt.Sprintf("%s-%d-%06d", counter.Prefix, counter.Year, next)
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&result)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		// numbered by a concurrent request, the counter is left as it is
+		if res.RowsAffected == 0 {
+			result = entity.Invoice{}
+			return tx.Where(entity.InvoiceParam{TransactionID: transactionID}).First(&result).Error
+		}
+
+		return tx.Model(&counter).Update("last", next).Error
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (i *invoice) Get(ctx context.Context, param entity.InvoiceParam) (entity.Invoice, error) {
+	invoice := entity.Invoice{}
+	if err := sql.Conn(ctx, i.db).Where(param).First(&invoice).Error; err != nil {
+		return invoice, err
+	}
+
+	return invoice, nil
+}
+
+func (i *invoice) Render(ctx context.Context, doc entity.InvoiceDocument) ([]byte, error) {
+	return i.pdf.Render(layout(doc))
+}
@@ -0,0 +1,248 @@
+package invoice
+
+import (
+	"fmt"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/pdf"
+	"strconv"
+	"strings"
+)
+
+const (
+	margin     = 50
+	lineHeight = 14
+	fontSize   = 9
+	// footerY is the lowest a table row goes before the page breaks.
+	footerY = 90
+)
+
+// columns of the items table, amounts are right aligned.
+const (
+	colItem      = margin
+	colQty       = 360
+	colUnitPrice = 460
+	colAmount    = pdf.PageWidth - margin
+)
+
+// page is the invoice being laid out, it moves to a new pdf page when the
+// current one is full.
+type page struct {
+	doc   pdf.Document
+	y     float64
+	title string
+}
+
+func layout(doc entity.InvoiceDocument) pdf.Document {
+	p := &page{
+		doc: pdf.Document{
+			Title: "Invoice " + doc.Number,
+		},
+		title: doc.Number,
+	}
+	p.newPage()
+
+	p.text(margin, p.y, 20, true, pdf.AlignLeft, "INVOICE")
+	sellerY := p.y
+	p.text(colAmount, sellerY, 11, true, pdf.AlignRight, doc.Seller.Name)
+	for _, l := range wrap(doc.Seller.Address, 200, false) {
+		sellerY -= lineHeight
+		p.text(colAmount, sellerY, fontSize, false, pdf.AlignRight, l)
+	}
+	if doc.Seller.TaxID != "" {
+		sellerY -= lineHeight
+		p.text(colAmount, sellerY, fontSize, false, pdf.AlignRight, "NPWP "+doc.Seller.TaxID)
+	}
+
+	p.y -= 2 * lineHeight
+	p.field("Invoice number", doc.Number)
+	p.field("Invoice date", doc.IssuedAt.Format("2 January 2006"))
+	p.field("Order ID", doc.OrderID)
+	p.field("Payment method", doc.PaymentMethod)
+
+	p.y = min(p.y, sellerY) - 2*lineHeight
+	addressY := p.y
+	p.text(margin, p.y, fontSize, true, pdf.AlignLeft, "Bill to")
+	p.y -= lineHeight
+	p.text(margin, p.y, fontSize, false, pdf.AlignLeft, doc.CustomerName)
+	p.y -= lineHeight
+	p.text(margin, p.y, fontSize, false, pdf.AlignLeft, doc.CustomerEmail)
+
+	shipY := addressY
+	p.text(300, shipY, fontSize, true, pdf.AlignLeft, "Ship to")
+	for _, l := range wrap(doc.ShippingAddress, colAmount-300, false) {
+		shipY -= lineHeight
+		p.text(300, shipY, fontSize, false, pdf.AlignLeft, l)
+	}
+
+	p.y = min(p.y, shipY) - 2*lineHeight
+	p.tableHeader()
+	for _, item := range doc.Items {
+		if p.y < footerY {
+			p.newPage()
+			p.tableHeader()
+		}
+
+		name := wrap(item.Name, colQty-colItem-60, false)
+		p.text(colItem, p.y, fontSize, false, pdf.AlignLeft, name[0])
+		p.text(colQty, p.y, fontSize, false, pdf.AlignRight, strconv.Itoa(item.Qty))
+		p.text(colUnitPrice, p.y, fontSize, false, pdf.AlignRight, money(item.UnitPrice))
+		p.text(colAmount, p.y, fontSize, false, pdf.AlignRight, money(item.Amount))
+		for _, l := range name[1:] {
+			p.y -= lineHeight
+			p.text(colItem, p.y, fontSize, false, pdf.AlignLeft, l)
+		}
+		p.y -= lineHeight
+	}
+
+	// the totals are kept together
+	totals := 4
+	if doc.TaxRate > 0 {
+		totals += 3
+	}
+	if p.y-float64(totals*lineHeight) < footerY {
+		p.newPage()
+	}
+
+	p.rule(colUnitPrice-120, p.y+lineHeight-4)
+	p.total("Subtotal", doc.Subtotal, false)
+	shipping := "Shipping"
+	if doc.ShippingCourier != "" {
+		shipping = fmt.Sprintf("Shipping (%s %s)", strings.ToUpper(doc.ShippingCourier), doc.ShippingService)
+	}
+	p.total(shipping, doc.ShippingCost, false)
+	p.total("Total", doc.Total, true)
+
+	if doc.TaxRate > 0 {
+		p.y -= lineHeight
+		p.total("Tax base (DPP)", doc.TaxBase, false)
+		p.total(fmt.Sprintf("%s %s%%", doc.TaxName, strconv.FormatFloat(doc.TaxRate, 'f', -1, 64)), doc.Tax, false)
+		p.y -= lineHeight / 2
+		p.text(colAmount, p.y, 8, false, pdf.AlignRight, fmt.Sprintf("Prices include %s.", doc.TaxName))
+	}
+
+	pages := len(p.doc.Pages)
+	for i := range p.doc.Pages {
+		p.doc.Pages[i].Texts = append(p.doc.Pages[i].Texts, pdf.Text{
+			X:     colAmount,
+			Y:     margin - 10,
+			Size:  8,
+			Align: pdf.AlignRight,
+			Value: fmt.Sprintf("%s - page %d of %d", p.title, i+1, pages),
+		})
+	}
+
+	return p.doc
+}
+
+func (p *page) newPage() {
+	p.doc.Pages = append(p.doc.Pages, pdf.Page{})
+	p.y = pdf.PageHeight - margin - 20
+}
+
+func (p *page) text(x, y, size float64, bold bool, align int, value string) {
+	if value == "" {
+		return
+	}
+
+	current := &p.doc.Pages[len(p.doc.Pages)-1]
+	current.Texts = append(current.Texts, pdf.Text{
+		X:     x,
+		Y:     y,
+		Size:  size,
+		Bold:  bold,
+		Align: align,
+		Value: value,
+	})
+}
+
+func (p *page) rule(x1, y float64) {
+	current := &p.doc.Pages[len(p.doc.Pages)-1]
+	current.Lines = append(current.Lines, pdf.Line{
+		X1: x1,
+		Y1: y,
+		X2: colAmount,
+		Y2: y,
+	})
+}
+
+func (p *page) field(label, value string) {
+	p.text(margin, p.y, fontSize, true, pdf.AlignLeft, label)
+	p.text(margin+90, p.y, fontSize, false, pdf.AlignLeft, value)
+	p.y -= lineHeight
+}
+
+func (p *page) tableHeader() {
+	p.text(colItem, p.y, fontSize, true, pdf.AlignLeft, "Item")
+	p.text(colQty, p.y, fontSize, true, pdf.AlignRight, "Qty")
+	p.text(colUnitPrice, p.y, fontSize, true, pdf.AlignRight, "Unit price")
+	p.text(colAmount, p.y, fontSize, true, pdf.AlignRight, "Amount")
+	p.rule(colItem, p.y-5)
+	p.y -= lineHeight + 4
+}
+
+func (p *page) total(label string, amount int64, bold bool) {
+	p.text(colUnitPrice, p.y, fontSize, bold, pdf.AlignRight, label)
+	p.text(colAmount, p.y, fontSize, bold, pdf.AlignRight, money(amount))
+	p.y -= lineHeight
+}
+
+// wrap breaks s into lines that fit width, a word longer than width is cut.
+// There is always at least one line.
+func wrap(s string, width float64, bold bool) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+
+		if pdf.TextWidth(next, fontSize, bold) <= width {
+			line = next
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+		runes := []rune(word)
+		for pdf.TextWidth(string(runes), fontSize, bold) > width && len(runes) > 1 {
+			cut := len(runes) - 1
+			for cut > 1 && pdf.TextWidth(string(runes[:cut]), fontSize, bold) > width {
+				cut--
+			}
+			lines = append(lines, string(runes[:cut]))
+			runes = runes[cut:]
+		}
+		line = string(runes)
+	}
+
+	return append(lines, line)
+}
+
+// money formats an amount of rupiah, e.g. Rp 1.250.000.
+func money(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	groups := []string{}
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	groups = append([]string{digits}, groups...)
+
+	return sign + "Rp " + strings.Join(groups, ".")
+}
+
+func min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,244 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/pdf"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func Test_invoice_Create(t *testing.T) {
+	year := time.Now().Year()
+
+	counterInsertQuery := regexp.QuoteMeta("INSERT INTO `invoice_counters` (`prefix`,`year`,`last`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `prefix`=`prefix`")
+	counterSelectQuery := regexp.QuoteMeta("SELECT * FROM `invoice_counters` WHERE `invoice_counters`.`prefix` = ? AND `invoice_counters`.`year` = ? ORDER BY `invoice_counters`.`prefix` LIMIT 1 FOR UPDATE")
+	counterUpdateQuery := regexp.QuoteMeta("UPDATE `invoice_counters` SET `last`=? WHERE `prefix` = ? AND `year` = ?")
+	insertQuery := regexp.QuoteMeta("INSERT INTO `invoices`")
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `invoices` WHERE `invoices`.`transaction_id` = ? AND `invoices`.`deleted_at` IS NULL ORDER BY `invoices`.`id` LIMIT 1")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		wantNumber  string
+		wantErr     bool
+	}{
+		{
+			name: "failed to create counter",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(counterInsertQuery).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to lock counter",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(counterInsertQuery).WithArgs("INV", year, 0).WillReturnResult(sqlmock.NewResult(0, 0))
+				sqlMock.ExpectQuery(counterSelectQuery).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to create invoice",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(counterInsertQuery).WithArgs("INV", year, 0).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"prefix", "year", "last"}).AddRow("INV", year, 41)
+				sqlMock.ExpectQuery(counterSelectQuery).WillReturnRows(rows)
+				sqlMock.ExpectExec(insertQuery).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "numbered by a concurrent request",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(counterInsertQuery).WithArgs("INV", year, 0).WillReturnResult(sqlmock.NewResult(0, 0))
+				counterRows := sqlmock.NewRows([]string{"prefix", "year", "last"}).AddRow("INV", year, 7)
+				sqlMock.ExpectQuery(counterSelectQuery).WillReturnRows(counterRows)
+				sqlMock.ExpectExec(insertQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"id", "transaction_id", "number"}).
+					AddRow(7, 1, "INV-2026-000007")
+				sqlMock.ExpectQuery(selectQuery).WithArgs(1).WillReturnRows(rows)
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantNumber: "INV-2026-000007",
+			wantErr:    false,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(counterInsertQuery).WithArgs("INV", year, 0).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"prefix", "year", "last"}).AddRow("INV", year, 41)
+				sqlMock.ExpectQuery(counterSelectQuery).WillReturnRows(rows)
+				sqlMock.ExpectExec(insertQuery).WillReturnResult(sqlmock.NewResult(87, 1))
+				sqlMock.ExpectExec(counterUpdateQuery).WithArgs(42, "INV", year).WillReturnResult(sqlmock.NewResult(0, 1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantNumber: fmt.Sprintf("INV-%d-000042", year),
+			wantErr:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			i := Init(Config{}, sqlClient, nil)
+			got, err := i.Create(context.Background(), 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("invoice.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantNumber, got.Number)
+			}
+		})
+	}
+}
+
+func Test_invoice_Get(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT * FROM `invoices` WHERE `invoices`.`transaction_id` = ? AND `invoices`.`deleted_at` IS NULL ORDER BY `invoices`.`id` LIMIT 1")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        entity.Invoice
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    entity.Invoice{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"id", "transaction_id", "number"}).
+					AddRow(1, 1, "INV-2026-000001")
+				sqlMock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+				return sqlServer, err
+			},
+			want: entity.Invoice{
+				Model:         gorm.Model{ID: 1},
+				TransactionID: 1,
+				Number:        "INV-2026-000001",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			i := Init(Config{}, sqlClient, nil)
+			got, err := i.Get(context.Background(), entity.InvoiceParam{TransactionID: 1})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("invoice.Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_invoice_Render(t *testing.T) {
+	items := []entity.InvoiceItem{}
+	for n := 0; n < 60; n++ {
+		items = append(items, entity.InvoiceItem{Name: "Kemeja (batik)", Qty: 2, UnitPrice: 125000, Amount: 250000})
+	}
+
+	i := Init(Config{}, nil, pdf.Init(pdf.Config{}))
+	got, err := i.Render(context.Background(), entity.InvoiceDocument{
+		Number:          "INV-2026-000001",
+		IssuedAt:        time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		PaymentMethod:   "GoPay",
+		Items:           items,
+		Subtotal:        15000000,
+		ShippingCourier: "jne",
+		ShippingService: "REG",
+		ShippingCost:    9000,
+		TaxName:         "PPN",
+		TaxRate:         11,
+		TaxBase:         13521622,
+		Tax:             1487378,
+		Total:           15009000,
+	})
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(got, []byte("%PDF-1.4")))
+	assert.Contains(t, string(got), "/Count 2")
+	assert.Contains(t, string(got), "(Kemeja \\(batik\\))")
+	assert.Contains(t, string(got), "(Shipping \\(JNE REG\\))")
+	assert.Contains(t, string(got), "(PPN 11%)")
+	assert.Contains(t, string(got), "(Rp 15.009.000)")
+	assert.Contains(t, string(got), "(INV-2026-000001 - page 2 of 2)")
+}
+
+func Test_wrap(t *testing.T) {
+	assert.Equal(t, []string{""}, wrap("", 100, false))
+	assert.Equal(t, []string{"Jl. Merdeka 10"}, wrap("Jl. Merdeka 10", 100, false))
+	assert.Equal(t, []string{"Jl. Merdeka", "10"}, wrap("Jl. Merdeka 10", 50, false))
+	assert.Equal(t, []string{"aaaaaaaaa", "aaaaaa"}, wrap("aaaaaaaaaaaaaaa", 50, false))
+}
+
+func Test_money(t *testing.T) {
+	assert.Equal(t, "Rp 0", money(0))
+	assert.Equal(t, "Rp 999", money(999))
+	assert.Equal(t, "Rp 1.000", money(1000))
+	assert.Equal(t, "Rp 1.250.000", money(1250000))
+	assert.Equal(t, "-Rp 9.000", money(-9000))
+}
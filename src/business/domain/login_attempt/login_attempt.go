@@ -0,0 +1,157 @@
+package loginattempt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/redis"
+	"time"
+)
+
+const (
+	failedAttemptKey = `synapsis:login:fail:%s:%s`
+	lockoutKey       = `synapsis:login:lock:%s:%s`
+
+	scopeUsername = "user"
+	scopeIP       = "ip"
+)
+
+type Interface interface {
+	GetLockout(ctx context.Context, param entity.LoginAttemptParam) (time.Duration, error)
+	RecordFailure(ctx context.Context, param entity.LoginAttemptParam) (time.Duration, error)
+	Reset(ctx context.Context, param entity.LoginAttemptParam) error
+}
+
+type Config struct {
+	MaxAttemptsPerUsername int64
+	MaxAttemptsPerIP       int64
+	Window                 time.Duration
+	LockoutDuration        time.Duration
+	MaxLockoutDuration     time.Duration
+}
+
+type loginAttempt struct {
+	conf  Config
+	redis redis.Interface
+}
+
+func Init(cfg Config, redis redis.Interface) Interface {
+	if cfg.MaxAttemptsPerUsername <= 0 {
+		cfg.MaxAttemptsPerUsername = 5
+	}
+	if cfg.MaxAttemptsPerIP <= 0 {
+		cfg.MaxAttemptsPerIP = 20
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 15 * time.Minute
+	}
+	if cfg.LockoutDuration <= 0 {
+		cfg.LockoutDuration = 30 * time.Second
+	}
+	if cfg.MaxLockoutDuration < cfg.LockoutDuration {
+		cfg.MaxLockoutDuration = time.Hour
+	}
+
+	la := &loginAttempt{
+		conf:  cfg,
+		redis: redis,
+	}
+
+	return la
+}
+
+// GetLockout returns the remaining lockout of the username or the ip address,
+// whichever is longer. Zero means the login may proceed.
+func (la *loginAttempt) GetLockout(ctx context.Context, param entity.LoginAttemptParam) (time.Duration, error) {
+	var lockout time.Duration
+	for _, s := range la.scopes(param) {
+		ttl, err := la.redis.TTL(ctx, fmt.Sprintf(lockoutKey, s.name, s.id))
+		if err != nil {
+			return lockout, err
+		}
+
+		if ttl > lockout {
+			lockout = ttl
+		}
+	}
+
+	return lockout, nil
+}
+
+// RecordFailure increments the failed attempt counters and locks every scope
+// that has reached its limit. The lockout doubles on each further failure
+// within the window, capped at MaxLockoutDuration.
+func (la *loginAttempt) RecordFailure(ctx context.Context, param entity.LoginAttemptParam) (time.Duration, error) {
+	var lockout time.Duration
+	for _, s := range la.scopes(param) {
+		key := fmt.Sprintf(failedAttemptKey, s.name, s.id)
+		attempts, err := la.redis.IncrEX(ctx, key, la.conf.Window)
+		if err != nil {
+			return lockout, err
+		}
+
+		maxAttempts := la.conf.MaxAttemptsPerUsername
+		if s.name == scopeIP {
+			maxAttempts = la.conf.MaxAttemptsPerIP
+		}
+
+		if attempts < maxAttempts {
+			continue
+		}
+
+		duration := la.lockoutDuration(attempts - maxAttempts)
+		if err := la.redis.SetEX(ctx, fmt.Sprintf(lockoutKey, s.name, s.id), fmt.Sprint(attempts), duration); err != nil {
+			return lockout, err
+		}
+
+		if duration > lockout {
+			lockout = duration
+		}
+	}
+
+	return lockout, nil
+}
+
+// Reset clears the username counters after a successful login. The ip counters
+// are left to expire so one valid account cannot be used to reset them.
+func (la *loginAttempt) Reset(ctx context.Context, param entity.LoginAttemptParam) error {
+	if param.Username == "" {
+		return errors.New("username is required")
+	}
+
+	return la.redis.Del(ctx,
+		fmt.Sprintf(failedAttemptKey, scopeUsername, param.Username),
+		fmt.Sprintf(lockoutKey, scopeUsername, param.Username),
+	)
+}
+
+type scope struct {
+	name string
+	id   string
+}
+
+func (la *loginAttempt) scopes(param entity.LoginAttemptParam) []scope {
+	scopes := []scope{}
+	if param.Username != "" {
+		scopes = append(scopes, scope{name: scopeUsername, id: param.Username})
+	}
+	if param.IPAddress != "" {
+		scopes = append(scopes, scope{name: scopeIP, id: param.IPAddress})
+	}
+
+	return scopes
+}
+
+func (la *loginAttempt) lockoutDuration(exceeded int64) time.Duration {
+	duration := la.conf.LockoutDuration
+	for i := int64(0); i < exceeded && duration < la.conf.MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+
+	if duration > la.conf.MaxLockoutDuration {
+		duration = la.conf.MaxLockoutDuration
+	}
+
+	return duration
+}
@@ -0,0 +1,286 @@
+package loginattempt
+
+import (
+	"context"
+	"fmt"
+	"go-clean/src/business/entity"
+	mock_redis "go-clean/src/lib/tests/mock/redis"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_loginAttempt_GetLockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis := mock_redis.NewMockInterface(ctrl)
+
+	mockParam := entity.LoginAttemptParam{
+		Username:  "mail",
+		IPAddress: "127.0.0.1",
+	}
+
+	userKey := fmt.Sprintf(lockoutKey, scopeUsername, mockParam.Username)
+	ipKey := fmt.Sprintf(lockoutKey, scopeIP, mockParam.IPAddress)
+
+	type mockFields struct {
+		redis *mock_redis.MockInterface
+	}
+
+	mocks := mockFields{
+		redis: mockRedis,
+	}
+
+	type args struct {
+		ctx   context.Context
+		param entity.LoginAttemptParam
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		mockFunc func(mock mockFields)
+		want     time.Duration
+		wantErr  bool
+	}{
+		{
+			name: "failed to get ttl",
+			args: args{
+				ctx:   context.Background(),
+				param: mockParam,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().TTL(context.Background(), userKey).Return(time.Duration(0), assert.AnError)
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "not locked",
+			args: args{
+				ctx:   context.Background(),
+				param: mockParam,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().TTL(context.Background(), userKey).Return(time.Duration(-2), nil)
+				mock.redis.EXPECT().TTL(context.Background(), ipKey).Return(time.Duration(-2), nil)
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "locked by ip",
+			args: args{
+				ctx:   context.Background(),
+				param: mockParam,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().TTL(context.Background(), userKey).Return(10*time.Second, nil)
+				mock.redis.EXPECT().TTL(context.Background(), ipKey).Return(time.Minute, nil)
+			},
+			want:    time.Minute,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			la := Init(Config{}, mockRedis)
+			got, err := la.GetLockout(tt.args.ctx, tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loginAttempt.GetLockout() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_loginAttempt_RecordFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis := mock_redis.NewMockInterface(ctrl)
+
+	mockConfig := Config{
+		MaxAttemptsPerUsername: 3,
+		MaxAttemptsPerIP:       10,
+		Window:                 time.Minute,
+		LockoutDuration:        time.Second,
+		MaxLockoutDuration:     5 * time.Second,
+	}
+
+	mockParam := entity.LoginAttemptParam{
+		Username:  "mail",
+		IPAddress: "127.0.0.1",
+	}
+
+	userKey := fmt.Sprintf(failedAttemptKey, scopeUsername, mockParam.Username)
+	ipKey := fmt.Sprintf(failedAttemptKey, scopeIP, mockParam.IPAddress)
+	userLockKey := fmt.Sprintf(lockoutKey, scopeUsername, mockParam.Username)
+
+	type mockFields struct {
+		redis *mock_redis.MockInterface
+	}
+
+	mocks := mockFields{
+		redis: mockRedis,
+	}
+
+	type args struct {
+		ctx   context.Context
+		param entity.LoginAttemptParam
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		mockFunc func(mock mockFields)
+		want     time.Duration
+		wantErr  bool
+	}{
+		{
+			name: "failed to incr",
+			args: args{
+				ctx:   context.Background(),
+				param: mockParam,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().IncrEX(context.Background(), userKey, time.Minute).Return(int64(0), assert.AnError)
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "first failure sets window",
+			args: args{
+				ctx:   context.Background(),
+				param: mockParam,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().IncrEX(context.Background(), userKey, time.Minute).Return(int64(1), nil)
+				mock.redis.EXPECT().IncrEX(context.Background(), ipKey, time.Minute).Return(int64(1), nil)
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "limit reached",
+			args: args{
+				ctx:   context.Background(),
+				param: mockParam,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().IncrEX(context.Background(), userKey, time.Minute).Return(int64(3), nil)
+				mock.redis.EXPECT().SetEX(context.Background(), userLockKey, "3", time.Second).Return(nil)
+				mock.redis.EXPECT().IncrEX(context.Background(), ipKey, time.Minute).Return(int64(3), nil)
+			},
+			want:    time.Second,
+			wantErr: false,
+		},
+		{
+			name: "lockout backs off exponentially",
+			args: args{
+				ctx:   context.Background(),
+				param: mockParam,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().IncrEX(context.Background(), userKey, time.Minute).Return(int64(5), nil)
+				mock.redis.EXPECT().SetEX(context.Background(), userLockKey, "5", 4*time.Second).Return(nil)
+				mock.redis.EXPECT().IncrEX(context.Background(), ipKey, time.Minute).Return(int64(5), nil)
+			},
+			want:    4 * time.Second,
+			wantErr: false,
+		},
+		{
+			name: "lockout is capped",
+			args: args{
+				ctx:   context.Background(),
+				param: mockParam,
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().IncrEX(context.Background(), userKey, time.Minute).Return(int64(9), nil)
+				mock.redis.EXPECT().SetEX(context.Background(), userLockKey, "9", 5*time.Second).Return(nil)
+				mock.redis.EXPECT().IncrEX(context.Background(), ipKey, time.Minute).Return(int64(9), nil)
+			},
+			want:    5 * time.Second,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			la := Init(mockConfig, mockRedis)
+			got, err := la.RecordFailure(tt.args.ctx, tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loginAttempt.RecordFailure() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_loginAttempt_Reset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis := mock_redis.NewMockInterface(ctrl)
+
+	type mockFields struct {
+		redis *mock_redis.MockInterface
+	}
+
+	mocks := mockFields{
+		redis: mockRedis,
+	}
+
+	type args struct {
+		ctx   context.Context
+		param entity.LoginAttemptParam
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		mockFunc func(mock mockFields)
+		wantErr  bool
+	}{
+		{
+			name: "empty username",
+			args: args{
+				ctx:   context.Background(),
+				param: entity.LoginAttemptParam{},
+			},
+			mockFunc: func(mock mockFields) {},
+			wantErr:  true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				ctx: context.Background(),
+				param: entity.LoginAttemptParam{
+					Username: "mail",
+				},
+			},
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().Del(context.Background(), fmt.Sprintf(failedAttemptKey, scopeUsername, "mail"), fmt.Sprintf(lockoutKey, scopeUsername, "mail")).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			la := Init(Config{}, mockRedis)
+			err := la.Reset(tt.args.ctx, tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loginAttempt.Reset() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
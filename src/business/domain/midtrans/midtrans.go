@@ -1,14 +1,17 @@
 package midtrans
 
 import (
+	"context"
 	midtransSdk "go-clean/src/lib/midtrans"
 
 	"github.com/midtrans/midtrans-go/coreapi"
 )
 
 type Interface interface {
-	Create(params midtransSdk.CreateOrderParam) (*coreapi.ChargeResponse, error)
-	HandleNotification(id string) (*coreapi.TransactionStatusResponse, error)
+	Create(ctx context.Context, params midtransSdk.CreateOrderParam) (*coreapi.ChargeResponse, error)
+	HandleNotification(ctx context.Context, id string) (*coreapi.TransactionStatusResponse, error)
+	Cancel(ctx context.Context, id string) (*coreapi.CancelResponse, error)
+	Refund(ctx context.Context, id string, req *coreapi.RefundReq) (*coreapi.RefundResponse, error)
 }
 
 type midtrans struct {
@@ -23,8 +26,8 @@ func Init(m midtransSdk.Interface) Interface {
 	return ms
 }
 
-func (m *midtrans) Create(params midtransSdk.CreateOrderParam) (*coreapi.ChargeResponse, error) {
-	result, err := m.m.CreateOrder(params)
+func (m *midtrans) Create(ctx context.Context, params midtransSdk.CreateOrderParam) (*coreapi.ChargeResponse, error) {
+	result, err := m.m.CreateOrder(ctx, params)
 	if err != nil {
 		return result, err
 	}
@@ -32,8 +35,26 @@ func (m *midtrans) Create(params midtransSdk.CreateOrderParam) (*coreapi.ChargeR
 	return result, nil
 }
 
-func (m *midtrans) HandleNotification(id string) (*coreapi.TransactionStatusResponse, error) {
-	result, err := m.m.HandleNotification(id)
+func (m *midtrans) HandleNotification(ctx context.Context, id string) (*coreapi.TransactionStatusResponse, error) {
+	result, err := m.m.HandleNotification(ctx, id)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (m *midtrans) Cancel(ctx context.Context, id string) (*coreapi.CancelResponse, error) {
+	result, err := m.m.CancelOrder(ctx, id)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (m *midtrans) Refund(ctx context.Context, id string, req *coreapi.RefundReq) (*coreapi.RefundResponse, error) {
+	result, err := m.m.RefundOrder(ctx, id, req)
 	if err != nil {
 		return result, err
 	}
@@ -1,15 +1,18 @@
 package midtranstransaction
 
 import (
+	"context"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/sql"
 
 	"gorm.io/gorm"
 )
 
 type Interface interface {
-	Create(midtransTransaction entity.MidtransTransaction) (entity.MidtransTransaction, error)
-	Get(param entity.MidtransTransactionParam) (entity.MidtransTransaction, error)
-	Update(selectParam entity.MidtransTransactionParam, updateParam entity.UpdateMidtransTransactionParam) error
+	Create(ctx context.Context, midtransTransaction entity.MidtransTransaction) (entity.MidtransTransaction, error)
+	Get(ctx context.Context, param entity.MidtransTransactionParam) (entity.MidtransTransaction, error)
+	Update(ctx context.Context, selectParam entity.MidtransTransactionParam, updateParam entity.UpdateMidtransTransactionParam) error
+	UpdateStatusFrom(ctx context.Context, id uint, from string, to string) (bool, error)
 }
 
 type midtransTransaction struct {
@@ -24,27 +27,40 @@ func Init(db *gorm.DB) Interface {
 	return mt
 }
 
-func (mt *midtransTransaction) Create(midtransTransaction entity.MidtransTransaction) (entity.MidtransTransaction, error) {
-	if err := mt.db.Create(&midtransTransaction).Error; err != nil {
+func (mt *midtransTransaction) Create(ctx context.Context, midtransTransaction entity.MidtransTransaction) (entity.MidtransTransaction, error) {
+	if err := sql.Conn(ctx, mt.db).Create(&midtransTransaction).Error; err != nil {
 		return midtransTransaction, err
 	}
 
 	return midtransTransaction, nil
 }
 
-func (mt *midtransTransaction) Get(param entity.MidtransTransactionParam) (entity.MidtransTransaction, error) {
+func (mt *midtransTransaction) Get(ctx context.Context, param entity.MidtransTransactionParam) (entity.MidtransTransaction, error) {
 	result := entity.MidtransTransaction{}
-	if err := mt.db.Where(param).First(&result).Error; err != nil {
+	if err := sql.Conn(ctx, mt.db).Where(param).First(&result).Error; err != nil {
 		return result, err
 	}
 
 	return result, nil
 }
 
-func (mt *midtransTransaction) Update(selectParam entity.MidtransTransactionParam, updateParam entity.UpdateMidtransTransactionParam) error {
-	if err := mt.db.Model(entity.MidtransTransaction{}).Where(selectParam).Updates(updateParam).Error; err != nil {
+func (mt *midtransTransaction) Update(ctx context.Context, selectParam entity.MidtransTransactionParam, updateParam entity.UpdateMidtransTransactionParam) error {
+	if err := sql.Conn(ctx, mt.db).Model(entity.MidtransTransaction{}).Where(selectParam).Updates(updateParam).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// UpdateStatusFrom moves the payment to status to only while it is still in
+// status from, it reports false when another request changed it first.
+func (mt *midtransTransaction) UpdateStatusFrom(ctx context.Context, id uint, from string, to string) (bool, error) {
+	res := sql.Conn(ctx, mt.db).Model(entity.MidtransTransaction{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
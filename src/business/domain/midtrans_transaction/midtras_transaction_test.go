@@ -1,6 +1,7 @@
 package midtranstransaction
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"go-clean/src/business/entity"
@@ -82,7 +83,7 @@ func Test_midtransTransaction_Create(t *testing.T) {
 			}
 
 			u := Init(sqlClient)
-			_, err = u.Create(tt.args.midtransTransaction)
+			_, err = u.Create(context.Background(), tt.args.midtransTransaction)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("midtransTransaction.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -158,7 +159,7 @@ func Test_midtransTransaction_Get(t *testing.T) {
 			}
 
 			u := Init(sqlClient)
-			got, err := u.Get(tt.args.param)
+			got, err := u.Get(context.Background(), tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("midtransTransaction.Get() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -240,7 +241,7 @@ func Test_midtransTransaction_Update(t *testing.T) {
 			}
 
 			u := Init(sqlClient)
-			err = u.Update(tt.args.selectParam, tt.args.updateParam)
+			err = u.Update(context.Background(), tt.args.selectParam, tt.args.updateParam)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("midtransTransaction.Update() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -248,3 +249,76 @@ func Test_midtransTransaction_Update(t *testing.T) {
 		})
 	}
 }
+
+func Test_midtransTransaction_UpdateStatusFrom(t *testing.T) {
+	query := regexp.QuoteMeta("UPDATE `midtrans_transactions` SET `status`=?,`updated_at`=? WHERE (id = ? AND status = ?) AND `midtrans_transactions`.`deleted_at` IS NULL")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "status changed already",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(entity.StatusCancelled, sqlmock.AnyArg(), 1, entity.StatusPending).WillReturnResult(driver.RowsAffected(0))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(entity.StatusCancelled, sqlmock.AnyArg(), 1, entity.StatusPending).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    true,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			u := Init(sqlClient)
+			got, err := u.UpdateStatusFrom(context.Background(), uint(1), entity.StatusPending, entity.StatusCancelled)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("midtransTransaction.UpdateStatusFrom() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -5,6 +5,7 @@
 package mock_cart
 
 import (
+	context "context"
 	entity "go-clean/src/business/entity"
 	reflect "reflect"
 
@@ -35,74 +36,74 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockInterface) Create(cart entity.Cart) (entity.Cart, error) {
+func (m *MockInterface) Create(ctx context.Context, cart entity.Cart) (entity.Cart, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", cart)
+	ret := m.ctrl.Call(m, "Create", ctx, cart)
 	ret0, _ := ret[0].(entity.Cart)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockInterfaceMockRecorder) Create(cart interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Create(ctx, cart interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInterface)(nil).Create), cart)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInterface)(nil).Create), ctx, cart)
 }
 
 // Delete mocks base method.
-func (m *MockInterface) Delete(param entity.CartParam) error {
+func (m *MockInterface) Delete(ctx context.Context, param entity.CartParam) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", param)
+	ret := m.ctrl.Call(m, "Delete", ctx, param)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockInterfaceMockRecorder) Delete(param interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Delete(ctx, param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockInterface)(nil).Delete), param)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockInterface)(nil).Delete), ctx, param)
 }
 
 // Get mocks base method.
-func (m *MockInterface) Get(param entity.CartParam) (entity.Cart, error) {
+func (m *MockInterface) Get(ctx context.Context, param entity.CartParam) (entity.Cart, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", param)
+	ret := m.ctrl.Call(m, "Get", ctx, param)
 	ret0, _ := ret[0].(entity.Cart)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockInterfaceMockRecorder) Get(param interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Get(ctx, param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInterface)(nil).Get), param)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInterface)(nil).Get), ctx, param)
 }
 
 // GetList mocks base method.
-func (m *MockInterface) GetList(param entity.CartParam) ([]entity.Cart, error) {
+func (m *MockInterface) GetList(ctx context.Context, param entity.CartParam) ([]entity.Cart, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetList", param)
+	ret := m.ctrl.Call(m, "GetList", ctx, param)
 	ret0, _ := ret[0].([]entity.Cart)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetList indicates an expected call of GetList.
-func (mr *MockInterfaceMockRecorder) GetList(param interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) GetList(ctx, param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockInterface)(nil).GetList), param)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockInterface)(nil).GetList), ctx, param)
 }
 
 // Update mocks base method.
-func (m *MockInterface) Update(selectParam entity.CartParam, updateParam entity.UpdateCartParam) error {
+func (m *MockInterface) Update(ctx context.Context, selectParam entity.CartParam, updateParam entity.UpdateCartParam) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", selectParam, updateParam)
+	ret := m.ctrl.Call(m, "Update", ctx, selectParam, updateParam)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockInterfaceMockRecorder) Update(selectParam, updateParam interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Update(ctx, selectParam, updateParam interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockInterface)(nil).Update), selectParam, updateParam)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockInterface)(nil).Update), ctx, selectParam, updateParam)
 }
@@ -6,6 +6,7 @@ package mock_category
 
 import (
 	context "context"
+	category "go-clean/src/business/domain/category"
 	entity "go-clean/src/business/entity"
 	reflect "reflect"
 
@@ -49,3 +50,15 @@ func (mr *MockInterfaceMockRecorder) GetList(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockInterface)(nil).GetList), ctx)
 }
+
+// SetConfig mocks base method.
+func (m *MockInterface) SetConfig(cfg category.Config) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConfig", cfg)
+}
+
+// SetConfig indicates an expected call of SetConfig.
+func (mr *MockInterfaceMockRecorder) SetConfig(cfg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConfig", reflect.TypeOf((*MockInterface)(nil).SetConfig), cfg)
+}
@@ -5,6 +5,7 @@
 package mock_midtrans
 
 import (
+	context "context"
 	midtrans "go-clean/src/lib/midtrans"
 	reflect "reflect"
 
@@ -35,32 +36,62 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 	return m.recorder
 }
 
+// Cancel mocks base method.
+func (m *MockInterface) Cancel(ctx context.Context, id string) (*coreapi.CancelResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, id)
+	ret0, _ := ret[0].(*coreapi.CancelResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockInterfaceMockRecorder) Cancel(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockInterface)(nil).Cancel), ctx, id)
+}
+
 // Create mocks base method.
-func (m *MockInterface) Create(params midtrans.CreateOrderParam) (*coreapi.ChargeResponse, error) {
+func (m *MockInterface) Create(ctx context.Context, params midtrans.CreateOrderParam) (*coreapi.ChargeResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", params)
+	ret := m.ctrl.Call(m, "Create", ctx, params)
 	ret0, _ := ret[0].(*coreapi.ChargeResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockInterfaceMockRecorder) Create(params interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Create(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInterface)(nil).Create), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInterface)(nil).Create), ctx, params)
 }
 
 // HandleNotification mocks base method.
-func (m *MockInterface) HandleNotification(id string) (*coreapi.TransactionStatusResponse, error) {
+func (m *MockInterface) HandleNotification(ctx context.Context, id string) (*coreapi.TransactionStatusResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HandleNotification", id)
+	ret := m.ctrl.Call(m, "HandleNotification", ctx, id)
 	ret0, _ := ret[0].(*coreapi.TransactionStatusResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // HandleNotification indicates an expected call of HandleNotification.
-func (mr *MockInterfaceMockRecorder) HandleNotification(id interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) HandleNotification(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleNotification", reflect.TypeOf((*MockInterface)(nil).HandleNotification), ctx, id)
+}
+
+// Refund mocks base method.
+func (m *MockInterface) Refund(ctx context.Context, id string, req *coreapi.RefundReq) (*coreapi.RefundResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, id, req)
+	ret0, _ := ret[0].(*coreapi.RefundResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockInterfaceMockRecorder) Refund(ctx, id, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleNotification", reflect.TypeOf((*MockInterface)(nil).HandleNotification), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockInterface)(nil).Refund), ctx, id, req)
 }
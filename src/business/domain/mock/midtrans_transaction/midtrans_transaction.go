@@ -5,6 +5,7 @@
 package mock_midtranstransaction
 
 import (
+	context "context"
 	entity "go-clean/src/business/entity"
 	reflect "reflect"
 
@@ -35,45 +36,60 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockInterface) Create(midtransTransaction entity.MidtransTransaction) (entity.MidtransTransaction, error) {
+func (m *MockInterface) Create(ctx context.Context, midtransTransaction entity.MidtransTransaction) (entity.MidtransTransaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", midtransTransaction)
+	ret := m.ctrl.Call(m, "Create", ctx, midtransTransaction)
 	ret0, _ := ret[0].(entity.MidtransTransaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockInterfaceMockRecorder) Create(midtransTransaction interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Create(ctx, midtransTransaction interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInterface)(nil).Create), midtransTransaction)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInterface)(nil).Create), ctx, midtransTransaction)
 }
 
 // Get mocks base method.
-func (m *MockInterface) Get(param entity.MidtransTransactionParam) (entity.MidtransTransaction, error) {
+func (m *MockInterface) Get(ctx context.Context, param entity.MidtransTransactionParam) (entity.MidtransTransaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", param)
+	ret := m.ctrl.Call(m, "Get", ctx, param)
 	ret0, _ := ret[0].(entity.MidtransTransaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockInterfaceMockRecorder) Get(param interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Get(ctx, param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInterface)(nil).Get), param)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInterface)(nil).Get), ctx, param)
 }
 
 // Update mocks base method.
-func (m *MockInterface) Update(selectParam entity.MidtransTransactionParam, updateParam entity.UpdateMidtransTransactionParam) error {
+func (m *MockInterface) Update(ctx context.Context, selectParam entity.MidtransTransactionParam, updateParam entity.UpdateMidtransTransactionParam) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", selectParam, updateParam)
+	ret := m.ctrl.Call(m, "Update", ctx, selectParam, updateParam)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockInterfaceMockRecorder) Update(selectParam, updateParam interface{}) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) Update(ctx, selectParam, updateParam interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockInterface)(nil).Update), selectParam, updateParam)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockInterface)(nil).Update), ctx, selectParam, updateParam)
+}
+
+// UpdateStatusFrom mocks base method.
+func (m *MockInterface) UpdateStatusFrom(ctx context.Context, id uint, from, to string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatusFrom", ctx, id, from, to)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatusFrom indicates an expected call of UpdateStatusFrom.
+func (mr *MockInterfaceMockRecorder) UpdateStatusFrom(ctx, id, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusFrom", reflect.TypeOf((*MockInterface)(nil).UpdateStatusFrom), ctx, id, from, to)
 }
@@ -6,6 +6,7 @@ package mock_product
 
 import (
 	context "context"
+	product "go-clean/src/business/domain/product"
 	entity "go-clean/src/business/entity"
 	reflect "reflect"
 
@@ -79,3 +80,15 @@ func (mr *MockInterfaceMockRecorder) GetListByID(ctx, productIDs interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListByID", reflect.TypeOf((*MockInterface)(nil).GetListByID), ctx, productIDs)
 }
+
+// SetConfig mocks base method.
+func (m *MockInterface) SetConfig(cfg product.Config) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConfig", cfg)
+}
+
+// SetConfig indicates an expected call of SetConfig.
+func (mr *MockInterfaceMockRecorder) SetConfig(cfg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConfig", reflect.TypeOf((*MockInterface)(nil).SetConfig), cfg)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: src/business/domain/report/report.go
+
+// Package mock_report is a generated GoMock package.
+package mock_report
+
+import (
+	context "context"
+	entity "go-clean/src/business/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInterface is a mock of Interface interface.
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface.
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance.
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Conversion mocks base method.
+func (m *MockInterface) Conversion(ctx context.Context, param entity.ReportParam) (entity.ConversionReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Conversion", ctx, param)
+	ret0, _ := ret[0].(entity.ConversionReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Conversion indicates an expected call of Conversion.
+func (mr *MockInterfaceMockRecorder) Conversion(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Conversion", reflect.TypeOf((*MockInterface)(nil).Conversion), ctx, param)
+}
+
+// PaymentMethods mocks base method.
+func (m *MockInterface) PaymentMethods(ctx context.Context, param entity.ReportParam) ([]entity.PaymentMethodReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaymentMethods", ctx, param)
+	ret0, _ := ret[0].([]entity.PaymentMethodReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PaymentMethods indicates an expected call of PaymentMethods.
+func (mr *MockInterfaceMockRecorder) PaymentMethods(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaymentMethods", reflect.TypeOf((*MockInterface)(nil).PaymentMethods), ctx, param)
+}
+
+// Revenue mocks base method.
+func (m *MockInterface) Revenue(ctx context.Context, param entity.ReportParam) ([]entity.RevenueReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revenue", ctx, param)
+	ret0, _ := ret[0].([]entity.RevenueReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revenue indicates an expected call of Revenue.
+func (mr *MockInterfaceMockRecorder) Revenue(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revenue", reflect.TypeOf((*MockInterface)(nil).Revenue), ctx, param)
+}
+
+// TopCategories mocks base method.
+func (m *MockInterface) TopCategories(ctx context.Context, param entity.ReportParam) ([]entity.CategoryReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopCategories", ctx, param)
+	ret0, _ := ret[0].([]entity.CategoryReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopCategories indicates an expected call of TopCategories.
+func (mr *MockInterfaceMockRecorder) TopCategories(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopCategories", reflect.TypeOf((*MockInterface)(nil).TopCategories), ctx, param)
+}
+
+// TopProducts mocks base method.
+func (m *MockInterface) TopProducts(ctx context.Context, param entity.ReportParam) ([]entity.ProductReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopProducts", ctx, param)
+	ret0, _ := ret[0].([]entity.ProductReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopProducts indicates an expected call of TopProducts.
+func (mr *MockInterfaceMockRecorder) TopProducts(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopProducts", reflect.TypeOf((*MockInterface)(nil).TopProducts), ctx, param)
+}
@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"context"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/sql"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Interface interface {
+	// WithTx runs fn in one db transaction, the state changes and the events
+	// written with the ctx given to fn are committed together.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Add(ctx context.Context, events ...entity.OutboxEvent) error
+	// Claim leases up to limit due events for lease, other dispatchers skip
+	// them until the lease expires. An event that is not marked before then
+	// is claimed again.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error)
+	MarkDispatched(ctx context.Context, id uint) error
+	MarkRetry(ctx context.Context, id uint, nextAttemptAt time.Time, lastErr string) error
+	MarkFailed(ctx context.Context, id uint, lastErr string) error
+}
+
+type outbox struct {
+	db *gorm.DB
+}
+
+func Init(db *gorm.DB) Interface {
+	o := &outbox{
+		db: db,
+	}
+
+	return o
+}
+
+func (o *outbox) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return sql.WithTx(ctx, o.db, fn)
+}
+
+func (o *outbox) Add(ctx context.Context, events ...entity.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	return sql.Conn(ctx, o.db).Create(&events).Error
+}
+
+func (o *outbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error) {
+	events := []entity.OutboxEvent{}
+
+	err := sql.WithTx(ctx, o.db, func(ctx context.Context) error {
+		now := time.Now()
+		if err := sql.Conn(ctx, o.db).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.OutboxStatusPending, now).
+			Order("id").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(events))
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+
+		return sql.Conn(ctx, o.db).Model(&entity.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+
+	return events, err
+}
+
+func (o *outbox) MarkDispatched(ctx context.Context, id uint) error {
+	return sql.Conn(ctx, o.db).Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":        entity.OutboxStatusDispatched,
+		"attempts":      gorm.Expr("attempts + 1"),
+		"last_error":    "",
+		"dispatched_at": time.Now(),
+	}).Error
+}
+
+func (o *outbox) MarkRetry(ctx context.Context, id uint, nextAttemptAt time.Time, lastErr string) error {
+	return sql.Conn(ctx, o.db).Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastErr,
+	}).Error
+}
+
+func (o *outbox) MarkFailed(ctx context.Context, id uint, lastErr string) error {
+	return sql.Conn(ctx, o.db).Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     entity.OutboxStatusFailed,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": lastErr,
+	}).Error
+}
@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"go-clean/src/business/entity"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func initOutbox(t *testing.T, sqlServer *sql.DB) Interface {
+	sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlServer,
+		SkipInitializeWithVersion: true,
+	}))
+	if err != nil {
+		t.Error(err)
+	}
+
+	return Init(sqlClient)
+}
+
+func Test_outbox_Add(t *testing.T) {
+	query := regexp.QuoteMeta("INSERT INTO `outbox_events`")
+
+	mockEvent := entity.OutboxEvent{
+		Type:        entity.EventUserRegistered,
+		AggregateID: 1,
+		Payload:     `{"user_id":1}`,
+		Status:      entity.OutboxStatusPending,
+	}
+
+	tests := []struct {
+		name        string
+		events      []entity.OutboxEvent
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name:   "no event",
+			events: nil,
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, _, err := sqlmock.New()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+		{
+			name:   "failed to insert",
+			events: []entity.OutboxEvent{mockEvent},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name:   "all ok",
+			events: []entity.OutboxEvent{mockEvent},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(1, 1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			o := initOutbox(t, sqlServer)
+			err = o.Add(context.Background(), tt.events...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("outbox.Add() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_outbox_Claim(t *testing.T) {
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `outbox_events` WHERE status = ? AND next_attempt_at <= ? ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED")
+	updateQuery := regexp.QuoteMeta("UPDATE `outbox_events` SET `next_attempt_at`=? WHERE id IN (?,?)")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        []uint
+		wantErr     bool
+	}{
+		{
+			name: "failed to select",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectQuery(selectQuery).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			want:    []uint{},
+			wantErr: true,
+		},
+		{
+			name: "nothing due",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectQuery(selectQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    []uint{},
+			wantErr: false,
+		},
+		{
+			name: "failed to lease",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectQuery(selectQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				sqlMock.ExpectExec(updateQuery).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			want:    []uint{1, 2},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectQuery(selectQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				sqlMock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 2))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    []uint{1, 2},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			o := initOutbox(t, sqlServer)
+			got, err := o.Claim(context.Background(), 10, time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("outbox.Claim() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			ids := []uint{}
+			for _, e := range got {
+				ids = append(ids, e.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
@@ -3,10 +3,13 @@ package product
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"go-clean/src/lib/redis"
-	"log"
+	"go-clean/src/lib/sql"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,112 +19,106 @@ type Interface interface {
 	GetList(ctx context.Context, param entity.ProductParam) ([]entity.Product, error)
 	GetListByID(ctx context.Context, productIDs []uint) ([]entity.Product, error)
 	Get(ctx context.Context, param entity.ProductParam) (entity.Product, error)
+	SetConfig(cfg Config)
+}
+
+// Config can be changed on a running instance through SetConfig.
+type Config struct {
+	// CacheTTL is how long a product read is cached, defaults to a minute.
+	CacheTTL time.Duration
+	// CacheStaleTTL is how long an expired read is still served while it is
+	// refreshed in the background, zero disables it.
+	CacheStaleTTL time.Duration
 }
 
 type product struct {
-	db    *gorm.DB
-	redis redis.Interface
+	mu      sync.RWMutex
+	conf    Config
+	log     log.Interface
+	metrics metrics.Interface
+	db      *gorm.DB
+	redis   redis.Interface
 }
 
-func Init(db *gorm.DB, redis redis.Interface) Interface {
+func Init(cfg Config, log log.Interface, metrics metrics.Interface, db *gorm.DB, redis redis.Interface) Interface {
 	p := &product{
-		db:    db,
-		redis: redis,
+		conf:    withDefault(cfg),
+		log:     log,
+		metrics: metrics,
+		db:      db,
+		redis:   redis,
 	}
 
 	return p
 }
 
 func (p *product) GetList(ctx context.Context, param entity.ProductParam) ([]entity.Product, error) {
-	marshalledParam, err := json.Marshal(param)
-	if err != nil {
-		log.Println(err.Error())
-	}
-
-	cacheResult, err := p.getCacheList(ctx, marshalledParam)
-	switch {
-	case errors.Is(err, redis.Nil):
-		log.Printf("error redis is nil, %s\n", err.Error())
-	case err != nil:
-		log.Printf("error redis : %s\n", err.Error())
-	default:
-		return cacheResult, nil
-	}
-
 	products := []entity.Product{}
-	if err := p.db.Where(param).Find(&products).Error; err != nil {
-		return products, err
-	}
 
 	key, err := json.Marshal(param)
 	if err != nil {
-		log.Println(err.Error())
+		return products, err
 	}
 
-	if err := p.upsertCacheList(ctx, key, products, time.Minute); err != nil {
-		log.Println(err.Error())
-	}
+	err = p.readThrough(ctx, fmt.Sprintf(getProductList, key), &products, func(ctx context.Context) (interface{}, error) {
+		products := []entity.Product{}
+		err := sql.Conn(ctx, p.db).Where(param).Find(&products).Error
+		return products, err
+	})
 
-	return products, nil
+	return products, err
 }
 
 func (p *product) GetListByID(ctx context.Context, productIDs []uint) ([]entity.Product, error) {
-	marshalledParam, err := json.Marshal(productIDs)
-	if err != nil {
-		log.Println(err.Error())
-	}
-
-	cacheResult, err := p.getCacheList(ctx, marshalledParam)
-	switch {
-	case errors.Is(err, redis.Nil):
-		log.Printf("error redis is nil, %s\n", err.Error())
-	case err != nil:
-		log.Printf("error redis : %s\n", err.Error())
-	default:
-		return cacheResult, nil
-	}
-
 	products := []entity.Product{}
-	if err := p.db.Find(&products, productIDs).Error; err != nil {
-		return products, err
-	}
 
 	key, err := json.Marshal(productIDs)
 	if err != nil {
-		log.Println(err.Error())
+		return products, err
 	}
 
-	if err := p.upsertCacheList(ctx, key, products, time.Minute); err != nil {
-		log.Println(err.Error())
-	}
+	err = p.readThrough(ctx, fmt.Sprintf(getProductList, key), &products, func(ctx context.Context) (interface{}, error) {
+		products := []entity.Product{}
+		err := sql.Conn(ctx, p.db).Find(&products, productIDs).Error
+		return products, err
+	})
 
-	return products, nil
+	return products, err
 }
 
 func (p *product) Get(ctx context.Context, param entity.ProductParam) (entity.Product, error) {
-	marshalledParam, err := json.Marshal(param)
-	if err != nil {
-		log.Println(err.Error())
-	}
+	product := entity.Product{}
 
-	cacheResult, err := p.getCacheByID(ctx, marshalledParam)
-	switch {
-	case errors.Is(err, redis.Nil):
-		log.Printf("error redis is nil, %s\n", err.Error())
-	case err != nil:
-		log.Printf("error redis : %s\n", err.Error())
-	default:
-		return cacheResult, nil
+	key, err := json.Marshal(param)
+	if err != nil {
+		return product, err
 	}
 
-	product := entity.Product{}
-	if err := p.db.Where(param).First(&product).Error; err != nil {
+	err = p.readThrough(ctx, fmt.Sprintf(getProductByIdKey, key), &product, func(ctx context.Context) (interface{}, error) {
+		product := entity.Product{}
+		err := sql.Conn(ctx, p.db).Where(param).First(&product).Error
 		return product, err
-	}
+	})
+
+	return product, err
+}
+
+func (p *product) SetConfig(cfg Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.conf = withDefault(cfg)
+}
+
+func (p *product) config() Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.conf
+}
 
-	if err = p.upsertCacheByID(ctx, marshalledParam, product, time.Minute); err != nil {
-		log.Println(err.Error())
+func withDefault(cfg Config) Config {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = time.Minute
 	}
 
-	return product, nil
+	return cfg
 }
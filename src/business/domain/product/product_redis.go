@@ -2,71 +2,63 @@ package product
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"go-clean/src/business/entity"
-	"time"
+	"go-clean/src/lib/metrics"
+	"go-clean/src/lib/redis"
 )
 
 const (
+	productCache = "product"
+	// productTag tags every product key, so they can be invalidated together.
+	productTag = "product"
+
 	getProductList    = `synapsis:product:get:q:%s`
 	getProductByIdKey = `synapsis:product:get:%s`
 )
 
-func (p *product) getCacheList(ctx context.Context, marshalledParams []byte) ([]entity.Product, error) {
-	result := []entity.Product{}
-
-	productListKey := fmt.Sprintf(getProductList, marshalledParams)
-
-	categoriesRedis, err := p.redis.Get(ctx, productListKey)
-	if err != nil {
-		return result, err
+// readThrough fills dest from the cache at key, load runs on a miss and its
+// result is cached for the next callers.
+func (p *product) readThrough(ctx context.Context, key string, dest interface{}, load func(ctx context.Context) (interface{}, error)) error {
+	conf := p.config()
+	result, err := redis.ReadThroughJSON(ctx, p.redis, key, redis.ReadThroughOptions{
+		TTL:      conf.CacheTTL,
+		StaleTTL: conf.CacheStaleTTL,
+		Tags:     []string{productTag},
+	}, dest, load)
+
+	if result.GetErr != nil {
+		p.log.Error(ctx, "failed to get product cache", "error", result.GetErr)
 	}
-
-	data := []byte(categoriesRedis)
-	if err := json.Unmarshal(data, &result); err != nil {
-		return result, fmt.Errorf("failed to unmarshal redis : %v", err.Error())
+	if result.SetErr != nil {
+		p.log.Error(ctx, "failed to set product cache", "error", result.SetErr)
 	}
-
-	return result, nil
-}
-
-func (p *product) upsertCacheList(ctx context.Context, key []byte, value []entity.Product, expTime time.Duration) error {
-	categories, err := json.Marshal(value)
-	if err != nil {
-		return err
-	}
-
-	if err := p.redis.SetEX(ctx, fmt.Sprintf(getProductList, string(key)), string(categories), expTime); err != nil {
-		return err
+	if result.Status == redis.ReadThroughMiss {
+		p.log.Debug(ctx, "product cache miss")
+		p.observeCacheSet(result.SetErr)
 	}
+	p.observeCacheGet(result)
 
-	return nil
+	return err
 }
 
-func (p *product) getCacheByID(ctx context.Context, marshalledParams []byte) (entity.Product, error) {
-	var result entity.Product
-	parameterByIDKey := fmt.Sprintf(getProductByIdKey, marshalledParams)
-	parameter, err := p.redis.Get(ctx, parameterByIDKey)
-	if err != nil {
-		return result, err
+func (p *product) observeCacheGet(r redis.ReadThroughResult) {
+	result := metrics.CacheHit
+	switch {
+	case r.GetErr != nil:
+		result = metrics.CacheError
+	case r.Status == redis.ReadThroughStale:
+		result = metrics.CacheStale
+	case r.Status != redis.ReadThroughHit:
+		result = metrics.CacheMiss
 	}
 
-	data := []byte(parameter)
-	if err := json.Unmarshal(data, &result); err != nil {
-		return result, fmt.Errorf("failed to unmarshal redis : %v", err.Error())
-	}
-
-	return result, nil
+	p.metrics.IncCache(productCache, metrics.CacheOperationGet, result)
 }
 
-func (p *product) upsertCacheByID(ctx context.Context, marshalledParams []byte, product entity.Product, expTime time.Duration) error {
-	parameterByIDKey := fmt.Sprintf(getProductByIdKey, marshalledParams)
-
-	rawJSON, err := json.Marshal(product)
+func (p *product) observeCacheSet(err error) {
+	result := metrics.CacheOK
 	if err != nil {
-		return fmt.Errorf("failed to marshal redis : %v", err.Error())
+		result = metrics.CacheError
 	}
 
-	return p.redis.SetEX(ctx, parameterByIDKey, string(rawJSON), expTime)
+	p.metrics.IncCache(productCache, metrics.CacheOperationSet, result)
 }
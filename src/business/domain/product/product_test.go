@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"go-clean/src/lib/redis"
 	mock_redis "go-clean/src/lib/tests/mock/redis"
 	"regexp"
@@ -28,6 +30,11 @@ func Test_product_GetList(t *testing.T) {
 
 	mockRedis := mock_redis.NewMockInterface(ctrl)
 
+	readThroughOpt := redis.ReadThroughOptions{
+		TTL:  time.Minute,
+		Tags: []string{productTag},
+	}
+
 	mockParam := entity.ProductParam{}
 	marshalledParam, _ := json.Marshal(mockParam)
 
@@ -70,13 +77,13 @@ func Test_product_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return(string(marshalledResult), nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).Return(redis.ReadThroughResult{Value: string(marshalledResult), Status: redis.ReadThroughHit}, nil)
 			},
 			want:    mockProductResult,
 			wantErr: false,
 		},
 		{
-			name: "failed to get cache list",
+			name: "failed to get cache and to exec query",
 			args: args{
 				ctx:   context.Background(),
 				param: mockParam,
@@ -86,7 +93,7 @@ func Test_product_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return("", assert.AnError)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(assert.AnError, nil))
 			},
 			want:    []entity.Product{},
 			wantErr: true,
@@ -103,7 +110,7 @@ func Test_product_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return("", redis.Nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, nil))
 			},
 			want:    []entity.Product{},
 			wantErr: true,
@@ -122,8 +129,7 @@ func Test_product_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return("", redis.Nil)
-				mock.redis.EXPECT().SetEX(context.Background(), fmt.Sprintf(getProductList, marshalledParam), string(marshalledResult), time.Minute).Return(assert.AnError)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, assert.AnError))
 			},
 			want: []entity.Product{
 				{
@@ -146,8 +152,7 @@ func Test_product_GetList(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return("", redis.Nil)
-				mock.redis.EXPECT().SetEX(context.Background(), fmt.Sprintf(getProductList, marshalledParam), string(marshalledResult), time.Minute).Return(nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, nil))
 			},
 			want: []entity.Product{
 				{
@@ -174,7 +179,7 @@ func Test_product_GetList(t *testing.T) {
 				t.Error(err)
 			}
 
-			u := Init(sqlClient, mockRedis)
+			u := Init(Config{}, log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), sqlClient, mockRedis)
 			got, err := u.GetList(tt.args.ctx, tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("product.GetList() error = %v, wantErr %v", err, tt.wantErr)
@@ -197,6 +202,11 @@ func Test_product_GetListByID(t *testing.T) {
 
 	mockRedis := mock_redis.NewMockInterface(ctrl)
 
+	readThroughOpt := redis.ReadThroughOptions{
+		TTL:  time.Minute,
+		Tags: []string{productTag},
+	}
+
 	mockResult := []entity.Product{
 		{
 			Name: "product 1",
@@ -237,13 +247,13 @@ func Test_product_GetListByID(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return(string(marshalledResult), nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).Return(redis.ReadThroughResult{Value: string(marshalledResult), Status: redis.ReadThroughHit}, nil)
 			},
 			want:    mockResult,
 			wantErr: false,
 		},
 		{
-			name: "failed to get cache list",
+			name: "failed to get cache and to exec query",
 			args: args{
 				ctx:        context.Background(),
 				productIDs: mockParam,
@@ -253,7 +263,7 @@ func Test_product_GetListByID(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return("", assert.AnError)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(assert.AnError, nil))
 			},
 			want:    []entity.Product{},
 			wantErr: true,
@@ -270,7 +280,7 @@ func Test_product_GetListByID(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return("", redis.Nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, nil))
 			},
 			want:    []entity.Product{},
 			wantErr: true,
@@ -289,8 +299,7 @@ func Test_product_GetListByID(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return("", redis.Nil)
-				mock.redis.EXPECT().SetEX(context.Background(), fmt.Sprintf(getProductList, string(marshalledParam)), string(marshalledResult), time.Minute).Return(assert.AnError)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, assert.AnError))
 			},
 			want: []entity.Product{
 				{
@@ -313,8 +322,7 @@ func Test_product_GetListByID(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductList, marshalledParam)).Return("", redis.Nil)
-				mock.redis.EXPECT().SetEX(context.Background(), fmt.Sprintf(getProductList, string(marshalledParam)), string(marshalledResult), time.Minute).Return(nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductList, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, nil))
 			},
 			want: []entity.Product{
 				{
@@ -341,7 +349,7 @@ func Test_product_GetListByID(t *testing.T) {
 				t.Error(err)
 			}
 
-			u := Init(sqlClient, mockRedis)
+			u := Init(Config{}, log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), sqlClient, mockRedis)
 			got, err := u.GetListByID(tt.args.ctx, tt.args.productIDs)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("product.GetListByID() error = %v, wantErr %v", err, tt.wantErr)
@@ -374,6 +382,11 @@ func Test_product_Get(t *testing.T) {
 
 	mockRedis := mock_redis.NewMockInterface(ctrl)
 
+	readThroughOpt := redis.ReadThroughOptions{
+		TTL:  time.Minute,
+		Tags: []string{productTag},
+	}
+
 	resultMock := entity.Product{
 		Model: gorm.Model{
 			ID: 1,
@@ -415,13 +428,13 @@ func Test_product_Get(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam)).Return(string(resultMarshalled), nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam), readThroughOpt, gomock.Any()).Return(redis.ReadThroughResult{Value: string(resultMarshalled), Status: redis.ReadThroughHit}, nil)
 			},
 			want:    resultMock,
 			wantErr: false,
 		},
 		{
-			name: "failed to get from cache",
+			name: "failed to get cache and to exec query",
 			args: args{
 				ctx:   context.Background(),
 				param: mockParam,
@@ -431,7 +444,7 @@ func Test_product_Get(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam)).Return("", assert.AnError)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(assert.AnError, nil))
 			},
 			want:    entity.Product{},
 			wantErr: true,
@@ -448,7 +461,7 @@ func Test_product_Get(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParamEmpty)).Return("", redis.Nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParamEmpty), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, nil))
 			},
 			want:    entity.Product{},
 			wantErr: true,
@@ -467,8 +480,7 @@ func Test_product_Get(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam)).Return("", redis.Nil)
-				mock.redis.EXPECT().SetEX(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam), string(resultMarshalled), time.Minute).Return(assert.AnError)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, assert.AnError))
 			},
 			want:    resultMock,
 			wantErr: false,
@@ -487,8 +499,7 @@ func Test_product_Get(t *testing.T) {
 				return sqlServer, err
 			},
 			mockFunc: func(mock mockFields) {
-				mock.redis.EXPECT().Get(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam)).Return("", redis.Nil)
-				mock.redis.EXPECT().SetEX(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam), string(resultMarshalled), time.Minute).Return(nil)
+				mock.redis.EXPECT().ReadThrough(context.Background(), fmt.Sprintf(getProductByIdKey, marshalledParam), readThroughOpt, gomock.Any()).DoAndReturn(readThrough(nil, nil))
 			},
 			want:    resultMock,
 			wantErr: false,
@@ -511,7 +522,7 @@ func Test_product_Get(t *testing.T) {
 				t.Error(err)
 			}
 
-			u := Init(sqlClient, mockRedis)
+			u := Init(Config{}, log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), sqlClient, mockRedis)
 			got, err := u.Get(tt.args.ctx, tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("product.Get() error = %v, wantErr %v", err, tt.wantErr)
@@ -521,3 +532,21 @@ func Test_product_Get(t *testing.T) {
 		})
 	}
 }
+
+// readThrough mimics a cache miss of ReadThrough, the value is loaded and the
+// cache errors are reported back.
+func readThrough(getErr, setErr error) func(ctx context.Context, key string, opt redis.ReadThroughOptions, load redis.LoadFunc) (redis.ReadThroughResult, error) {
+	return func(ctx context.Context, key string, opt redis.ReadThroughOptions, load redis.LoadFunc) (redis.ReadThroughResult, error) {
+		result := redis.ReadThroughResult{
+			GetErr: getErr,
+		}
+
+		value, err := load(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		result.Value, result.Status, result.SetErr = value, redis.ReadThroughMiss, setErr
+		return result, nil
+	}
+}
@@ -0,0 +1,54 @@
+package refund
+
+import (
+	"context"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/sql"
+
+	"gorm.io/gorm"
+)
+
+type Interface interface {
+	// Create fails on a refund key that is already taken, so two admins
+	// refunding the same order at once cannot both reach midtrans.
+	Create(ctx context.Context, refund entity.Refund) (entity.Refund, error)
+	GetList(ctx context.Context, param entity.RefundParam) ([]entity.Refund, error)
+	Update(ctx context.Context, selectParam entity.RefundParam, updateParam entity.UpdateRefundParam) error
+}
+
+type refund struct {
+	db *gorm.DB
+}
+
+func Init(db *gorm.DB) Interface {
+	r := &refund{
+		db: db,
+	}
+
+	return r
+}
+
+func (r *refund) Create(ctx context.Context, refund entity.Refund) (entity.Refund, error) {
+	if err := sql.Conn(ctx, r.db).Create(&refund).Error; err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+func (r *refund) GetList(ctx context.Context, param entity.RefundParam) ([]entity.Refund, error) {
+	refunds := []entity.Refund{}
+	if err := sql.Conn(ctx, r.db).Where(param).Order("id").Find(&refunds).Error; err != nil {
+		return refunds, err
+	}
+
+	return refunds, nil
+}
+
+func (r *refund) Update(ctx context.Context, selectParam entity.RefundParam, updateParam entity.UpdateRefundParam) error {
+	if err := sql.Conn(ctx, r.db).Model(entity.Refund{}).Where(selectParam).Updates(updateParam).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package refund
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"go-clean/src/business/entity"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func Test_refund_Create(t *testing.T) {
+	query := regexp.QuoteMeta("INSERT INTO `refunds`")
+
+	mockRefund := entity.Refund{
+		TransactionID: 1,
+		RefundKey:     "SYN-1-R1",
+		Amount:        1000,
+		Items: []entity.RefundItem{
+			{CartID: 1, Qty: 1},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name: "refund key taken",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), uint(1), "", "SYN-1-R1", int64(1000), "", `[{"CartID":1,"Qty":1}]`, entity.RefundStatusPending, uint(0), "").WillReturnResult(sqlmock.NewResult(1, 1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			r := Init(sqlClient)
+			got, err := r.Create(context.Background(), mockRefund)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("refund.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, uint(1), got.ID)
+			}
+		})
+	}
+}
+
+func Test_refund_GetList(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT * FROM `refunds` WHERE `refunds`.`transaction_id` = ? AND `refunds`.`deleted_at` IS NULL ORDER BY id")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        []entity.Refund
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    []entity.Refund{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"id", "refund_key", "amount", "items"}).
+					AddRow(1, "SYN-1-R1", 1000, `[{"CartID":1,"Qty":1}]`)
+				sqlMock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+				return sqlServer, err
+			},
+			want: []entity.Refund{
+				{
+					Model:     gorm.Model{ID: 1},
+					RefundKey: "SYN-1-R1",
+					Amount:    1000,
+					Items: []entity.RefundItem{
+						{CartID: 1, Qty: 1},
+					},
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			r := Init(sqlClient)
+			got, err := r.GetList(context.Background(), entity.RefundParam{TransactionID: 1})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("refund.GetList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_refund_Update(t *testing.T) {
+	query := regexp.QuoteMeta("UPDATE `refunds` SET")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			r := Init(sqlClient)
+			err = r.Update(context.Background(), entity.RefundParam{ID: 1}, entity.UpdateRefundParam{
+				Status: entity.RefundStatusSuccess,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("refund.Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package report
+
+import (
+	"context"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/sql"
+
+	"gorm.io/gorm"
+)
+
+// Interface aggregates sales over the orders created from param.From up to,
+// not including, param.To. Revenue is the total price of orders that are
+// paid, partially refunded orders count in full and refunded orders not at
+// all.
+type Interface interface {
+	Revenue(ctx context.Context, param entity.ReportParam) ([]entity.RevenueReport, error)
+	TopProducts(ctx context.Context, param entity.ReportParam) ([]entity.ProductReport, error)
+	TopCategories(ctx context.Context, param entity.ReportParam) ([]entity.CategoryReport, error)
+	PaymentMethods(ctx context.Context, param entity.ReportParam) ([]entity.PaymentMethodReport, error)
+	Conversion(ctx context.Context, param entity.ReportParam) (entity.ConversionReport, error)
+}
+
+// periodFormats group orders in mysql the way RevenueReport.Period reads.
+var periodFormats = map[string]string{
+	entity.ReportIntervalDay:   "%Y-%m-%d",
+	entity.ReportIntervalWeek:  "%x-W%v",
+	entity.ReportIntervalMonth: "%Y-%m",
+}
+
+var sortOrders = map[string]string{
+	entity.ReportSortUnits:   "units DESC",
+	entity.ReportSortRevenue: "revenue DESC",
+}
+
+var (
+	revenueStatuses = []string{entity.StatusSuccess, entity.StatusPartialRefund}
+	paidStatuses    = []string{entity.StatusSuccess, entity.StatusPartialRefund, entity.StatusRefunded}
+	paidCarts       = []string{entity.StatusPaid, entity.StatusRefunded}
+)
+
+type report struct {
+	db *gorm.DB
+}
+
+func Init(db *gorm.DB) Interface {
+	r := &report{
+		db: db,
+	}
+
+	return r
+}
+
+func (r *report) Revenue(ctx context.Context, param entity.ReportParam) ([]entity.RevenueReport, error) {
+	reports := []entity.RevenueReport{}
+	if err := r.paidOrders(ctx, param).
+		Select("DATE_FORMAT(transactions.created_at, ?) AS period, COUNT(*) AS orders, SUM(transactions.total_price) AS revenue", periodFormats[param.Interval]).
+		Group("period").
+		Order("period").
+		Scan(&reports).Error; err != nil {
+		return reports, err
+	}
+
+	return reports, nil
+}
+
+func (r *report) TopProducts(ctx context.Context, param entity.ReportParam) ([]entity.ProductReport, error) {
+	reports := []entity.ProductReport{}
+	if err := r.paidCarts(ctx, param).
+		Select("carts.product_id, products.name, SUM(carts.qty) AS units, SUM(carts.qty * carts.final_price_per_item) AS revenue").
+		Group("carts.product_id, products.name").
+		Order(sortOrders[param.SortBy]).
+		Order("carts.product_id").
+		Limit(param.Limit).
+		Scan(&reports).Error; err != nil {
+		return reports, err
+	}
+
+	return reports, nil
+}
+
+func (r *report) TopCategories(ctx context.Context, param entity.ReportParam) ([]entity.CategoryReport, error) {
+	reports := []entity.CategoryReport{}
+	if err := r.paidCarts(ctx, param).
+		Select("products.category_id, categories.name, SUM(carts.qty) AS units, SUM(carts.qty * carts.final_price_per_item) AS revenue").
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Group("products.category_id, categories.name").
+		Order(sortOrders[param.SortBy]).
+		Order("products.category_id").
+		Limit(param.Limit).
+		Scan(&reports).Error; err != nil {
+		return reports, err
+	}
+
+	return reports, nil
+}
+
+func (r *report) PaymentMethods(ctx context.Context, param entity.ReportParam) ([]entity.PaymentMethodReport, error) {
+	reports := []entity.PaymentMethodReport{}
+	if err := r.paidOrders(ctx, param).
+		Select("midtrans_transactions.payment_type, COUNT(*) AS orders, SUM(transactions.total_price) AS revenue").
+		Group("midtrans_transactions.payment_type").
+		Order("revenue DESC").
+		Scan(&reports).Error; err != nil {
+		return reports, err
+	}
+
+	return reports, nil
+}
+
+func (r *report) Conversion(ctx context.Context, param entity.ReportParam) (entity.ConversionReport, error) {
+	result := entity.ConversionReport{}
+
+	// deleted carts were left in the cart, they count as not converted
+	carts := struct {
+		Carts     int64
+		PaidCarts int64
+	}{}
+	if err := sql.Conn(ctx, r.db).Table("carts").
+		Select("COUNT(*) AS carts, COUNT(CASE WHEN status IN ? THEN 1 END) AS paid_carts", paidCarts).
+		Where("created_at >= ? AND created_at < ?", param.From, param.To).
+		Scan(&carts).Error; err != nil {
+		return result, err
+	}
+
+	orders := struct {
+		Orders     int64
+		PaidOrders int64
+	}{}
+	if err := sql.Conn(ctx, r.db).Table("transactions").
+		Select("COUNT(*) AS orders, COUNT(CASE WHEN midtrans_transactions.status IN ? THEN 1 END) AS paid_orders", paidStatuses).
+		Joins("LEFT JOIN midtrans_transactions ON midtrans_transactions.transaction_id = transactions.id AND midtrans_transactions.deleted_at IS NULL").
+		Where("transactions.deleted_at IS NULL AND transactions.created_at >= ? AND transactions.created_at < ?", param.From, param.To).
+		Scan(&orders).Error; err != nil {
+		return result, err
+	}
+
+	result.Carts = carts.Carts
+	result.PaidCarts = carts.PaidCarts
+	result.Orders = orders.Orders
+	result.PaidOrders = orders.PaidOrders
+
+	return result, nil
+}
+
+func (r *report) paidOrders(ctx context.Context, param entity.ReportParam) *gorm.DB {
+	return sql.Conn(ctx, r.db).Table("transactions").
+		Joins("JOIN midtrans_transactions ON midtrans_transactions.transaction_id = transactions.id AND midtrans_transactions.deleted_at IS NULL").
+		Where("transactions.deleted_at IS NULL AND midtrans_transactions.status IN ?", revenueStatuses).
+		Where("transactions.created_at >= ? AND transactions.created_at < ?", param.From, param.To)
+}
+
+// paidCarts are the carts still paid for, a cart refunded in full is left
+// out.
+func (r *report) paidCarts(ctx context.Context, param entity.ReportParam) *gorm.DB {
+	return sql.Conn(ctx, r.db).Table("carts").
+		Joins("JOIN transactions ON transactions.id = carts.transaction_id").
+		Joins("JOIN products ON products.id = carts.product_id").
+		Where("carts.deleted_at IS NULL AND carts.status = ?", entity.StatusPaid).
+		Where("transactions.created_at >= ? AND transactions.created_at < ?", param.From, param.To)
+}
@@ -0,0 +1,356 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"go-clean/src/business/entity"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func Test_report_Revenue(t *testing.T) {
+	fromMock := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	toMock := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	query := regexp.QuoteMeta("SELECT DATE_FORMAT(transactions.created_at, ?) AS period, COUNT(*) AS orders, SUM(transactions.total_price) AS revenue FROM `transactions` JOIN midtrans_transactions ON midtrans_transactions.transaction_id = transactions.id AND midtrans_transactions.deleted_at IS NULL WHERE (transactions.deleted_at IS NULL AND midtrans_transactions.status IN (?,?)) AND (transactions.created_at >= ? AND transactions.created_at < ?) GROUP BY `period` ORDER BY period")
+
+	tests := []struct {
+		name        string
+		interval    string
+		prepSqlMock func() (*sql.DB, error)
+		want        []entity.RevenueReport
+		wantErr     bool
+	}{
+		{
+			name:     "failed to exec query",
+			interval: entity.ReportIntervalDay,
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    []entity.RevenueReport{},
+			wantErr: true,
+		},
+		{
+			name:     "all ok by week",
+			interval: entity.ReportIntervalWeek,
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"period", "orders", "revenue"}).
+					AddRow("2026-W40", 3, 450000).
+					AddRow("2026-W42", 1, 59000)
+				sqlMock.ExpectQuery(query).WithArgs("%x-W%v", entity.StatusSuccess, entity.StatusPartialRefund, fromMock, toMock).WillReturnRows(rows)
+				return sqlServer, err
+			},
+			want: []entity.RevenueReport{
+				{Period: "2026-W40", Orders: 3, Revenue: 450000},
+				{Period: "2026-W42", Orders: 1, Revenue: 59000},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			r := Init(sqlClient)
+			got, err := r.Revenue(context.Background(), entity.ReportParam{From: fromMock, To: toMock, Interval: tt.interval})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.Revenue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_report_TopProducts(t *testing.T) {
+	fromMock := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	toMock := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	query := regexp.QuoteMeta("SELECT carts.product_id, products.name, SUM(carts.qty) AS units, SUM(carts.qty * carts.final_price_per_item) AS revenue FROM `carts` JOIN transactions ON transactions.id = carts.transaction_id JOIN products ON products.id = carts.product_id WHERE (carts.deleted_at IS NULL AND carts.status = ?) AND (transactions.created_at >= ? AND transactions.created_at < ?) GROUP BY carts.product_id, products.name ORDER BY revenue DESC,carts.product_id LIMIT 5")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        []entity.ProductReport
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    []entity.ProductReport{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"product_id", "name", "units", "revenue"}).
+					AddRow(2, "Sepatu", 1, 300000).
+					AddRow(1, "Kemeja", 4, 200000)
+				sqlMock.ExpectQuery(query).WithArgs(entity.StatusPaid, fromMock, toMock).WillReturnRows(rows)
+				return sqlServer, err
+			},
+			want: []entity.ProductReport{
+				{ProductID: 2, Name: "Sepatu", Units: 1, Revenue: 300000},
+				{ProductID: 1, Name: "Kemeja", Units: 4, Revenue: 200000},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			r := Init(sqlClient)
+			got, err := r.TopProducts(context.Background(), entity.ReportParam{From: fromMock, To: toMock, SortBy: entity.ReportSortRevenue, Limit: 5})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.TopProducts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_report_TopCategories(t *testing.T) {
+	fromMock := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	toMock := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	query := regexp.QuoteMeta("SELECT products.category_id, categories.name, SUM(carts.qty) AS units, SUM(carts.qty * carts.final_price_per_item) AS revenue FROM `carts` JOIN transactions ON transactions.id = carts.transaction_id JOIN products ON products.id = carts.product_id JOIN categories ON categories.id = products.category_id WHERE (carts.deleted_at IS NULL AND carts.status = ?) AND (transactions.created_at >= ? AND transactions.created_at < ?) GROUP BY products.category_id, categories.name ORDER BY units DESC,products.category_id LIMIT 10")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        []entity.CategoryReport
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    []entity.CategoryReport{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"category_id", "name", "units", "revenue"}).
+					AddRow(1, "Pakaian", 5, 350000)
+				sqlMock.ExpectQuery(query).WithArgs(entity.StatusPaid, fromMock, toMock).WillReturnRows(rows)
+				return sqlServer, err
+			},
+			want: []entity.CategoryReport{
+				{CategoryID: 1, Name: "Pakaian", Units: 5, Revenue: 350000},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			r := Init(sqlClient)
+			got, err := r.TopCategories(context.Background(), entity.ReportParam{From: fromMock, To: toMock, SortBy: entity.ReportSortUnits, Limit: 10})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.TopCategories() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_report_PaymentMethods(t *testing.T) {
+	fromMock := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	toMock := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	query := regexp.QuoteMeta("SELECT midtrans_transactions.payment_type, COUNT(*) AS orders, SUM(transactions.total_price) AS revenue FROM `transactions` JOIN midtrans_transactions ON midtrans_transactions.transaction_id = transactions.id AND midtrans_transactions.deleted_at IS NULL WHERE (transactions.deleted_at IS NULL AND midtrans_transactions.status IN (?,?)) AND (transactions.created_at >= ? AND transactions.created_at < ?) GROUP BY `midtrans_transactions`.`payment_type` ORDER BY revenue DESC")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        []entity.PaymentMethodReport
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    []entity.PaymentMethodReport{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"payment_type", "orders", "revenue"}).
+					AddRow(1, 4, 509000)
+				sqlMock.ExpectQuery(query).WithArgs(entity.StatusSuccess, entity.StatusPartialRefund, fromMock, toMock).WillReturnRows(rows)
+				return sqlServer, err
+			},
+			want: []entity.PaymentMethodReport{
+				{PaymentType: 1, Orders: 4, Revenue: 509000},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			r := Init(sqlClient)
+			got, err := r.PaymentMethods(context.Background(), entity.ReportParam{From: fromMock, To: toMock})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.PaymentMethods() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_report_Conversion(t *testing.T) {
+	fromMock := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	toMock := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	cartQuery := regexp.QuoteMeta("SELECT COUNT(*) AS carts, COUNT(CASE WHEN status IN (?,?) THEN 1 END) AS paid_carts FROM `carts` WHERE created_at >= ? AND created_at < ?")
+	orderQuery := regexp.QuoteMeta("SELECT COUNT(*) AS orders, COUNT(CASE WHEN midtrans_transactions.status IN (?,?,?) THEN 1 END) AS paid_orders FROM `transactions` LEFT JOIN midtrans_transactions ON midtrans_transactions.transaction_id = transactions.id AND midtrans_transactions.deleted_at IS NULL WHERE transactions.deleted_at IS NULL AND transactions.created_at >= ? AND transactions.created_at < ?")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        entity.ConversionReport
+		wantErr     bool
+	}{
+		{
+			name: "failed to count carts",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(cartQuery).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to count orders",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(cartQuery).WillReturnRows(sqlmock.NewRows([]string{"carts", "paid_carts"}).AddRow(10, 4))
+				sqlMock.ExpectQuery(orderQuery).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(cartQuery).WithArgs(entity.StatusPaid, entity.StatusRefunded, fromMock, toMock).
+					WillReturnRows(sqlmock.NewRows([]string{"carts", "paid_carts"}).AddRow(10, 4))
+				sqlMock.ExpectQuery(orderQuery).WithArgs(entity.StatusSuccess, entity.StatusPartialRefund, entity.StatusRefunded, fromMock, toMock).
+					WillReturnRows(sqlmock.NewRows([]string{"orders", "paid_orders"}).AddRow(5, 3))
+				return sqlServer, err
+			},
+			want: entity.ConversionReport{
+				Carts:      10,
+				PaidCarts:  4,
+				Orders:     5,
+				PaidOrders: 3,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			r := Init(sqlClient)
+			got, err := r.Conversion(context.Background(), entity.ReportParam{From: fromMock, To: toMock})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.Conversion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package shipment
+
+import (
+	"context"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/shipping"
+	"go-clean/src/lib/sql"
+
+	"gorm.io/gorm"
+)
+
+type Interface interface {
+	Create(ctx context.Context, shipment entity.Shipment) (entity.Shipment, error)
+	GetList(ctx context.Context, param entity.ShipmentParam) ([]entity.Shipment, error)
+	Get(ctx context.Context, param entity.ShipmentParam) (entity.Shipment, error)
+	Update(ctx context.Context, selectParam entity.ShipmentParam, updateParam entity.UpdateShipmentParam) error
+	// Quote prices the delivery of weight grams to destination with every
+	// courier service.
+	Quote(ctx context.Context, weight int, destination string) []entity.ShippingQuote
+}
+
+type shipment struct {
+	db       *gorm.DB
+	shipping shipping.Interface
+}
+
+func Init(db *gorm.DB, sp shipping.Interface) Interface {
+	s := &shipment{
+		db:       db,
+		shipping: sp,
+	}
+
+	return s
+}
+
+func (s *shipment) Create(ctx context.Context, shipment entity.Shipment) (entity.Shipment, error) {
+	if err := sql.Conn(ctx, s.db).Create(&shipment).Error; err != nil {
+		return shipment, err
+	}
+
+	return shipment, nil
+}
+
+func (s *shipment) GetList(ctx context.Context, param entity.ShipmentParam) ([]entity.Shipment, error) {
+	shipments := []entity.Shipment{}
+	if err := sql.Conn(ctx, s.db).Where(param).Order("id").Find(&shipments).Error; err != nil {
+		return shipments, err
+	}
+
+	return shipments, nil
+}
+
+func (s *shipment) Get(ctx context.Context, param entity.ShipmentParam) (entity.Shipment, error) {
+	shipment := entity.Shipment{}
+	if err := sql.Conn(ctx, s.db).Where(param).First(&shipment).Error; err != nil {
+		return shipment, err
+	}
+
+	return shipment, nil
+}
+
+func (s *shipment) Update(ctx context.Context, selectParam entity.ShipmentParam, updateParam entity.UpdateShipmentParam) error {
+	if err := sql.Conn(ctx, s.db).Model(entity.Shipment{}).Where(selectParam).Updates(updateParam).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *shipment) Quote(ctx context.Context, weight int, destination string) []entity.ShippingQuote {
+	quotes := []entity.ShippingQuote{}
+	for _, q := range s.shipping.Quote(ctx, shipping.QuoteParam{
+		Weight:      weight,
+		Destination: destination,
+	}) {
+		quotes = append(quotes, entity.ShippingQuote{
+			Courier:       q.Courier,
+			Service:       q.Service,
+			Cost:          q.Cost,
+			EstimatedDays: q.EstimatedDays,
+		})
+	}
+
+	return quotes
+}
@@ -0,0 +1,242 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/shipping"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func Test_shipment_Create(t *testing.T) {
+	query := regexp.QuoteMeta("INSERT INTO `shipments`")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(1, 1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			s := Init(sqlClient, nil)
+			got, err := s.Create(context.Background(), entity.Shipment{
+				TransactionID:  1,
+				TrackingNumber: "JNE-1",
+				Items:          []entity.ShipmentItem{{CartID: 1, Qty: 1}},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shipment.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, uint(1), got.ID)
+			}
+		})
+	}
+}
+
+func Test_shipment_GetList(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT * FROM `shipments` WHERE `shipments`.`transaction_id` = ? AND `shipments`.`deleted_at` IS NULL ORDER BY id")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        []entity.Shipment
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			want:    []entity.Shipment{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				rows := sqlmock.NewRows([]string{"id", "tracking_number", "items"}).
+					AddRow(1, "JNE-1", `[{"CartID":1,"Qty":2}]`)
+				sqlMock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+				return sqlServer, err
+			},
+			want: []entity.Shipment{
+				{
+					Model:          gorm.Model{ID: 1},
+					TrackingNumber: "JNE-1",
+					Items:          []entity.ShipmentItem{{CartID: 1, Qty: 2}},
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			s := Init(sqlClient, nil)
+			got, err := s.GetList(context.Background(), entity.ShipmentParam{TransactionID: 1})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shipment.GetList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_shipment_Update(t *testing.T) {
+	query := regexp.QuoteMeta("UPDATE `shipments` SET")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			s := Init(sqlClient, nil)
+			err = s.Update(context.Background(), entity.ShipmentParam{ID: 1}, entity.UpdateShipmentParam{
+				Status: entity.ShipmentStatusDelivered,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shipment.Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_shipment_Quote(t *testing.T) {
+	rates := []shipping.Rate{
+		{Courier: "jne", Service: "REG", Price: 9000, EstimatedDays: "2-3"},
+	}
+
+	tests := []struct {
+		name   string
+		config shipping.Config
+		weight int
+		want   []entity.ShippingQuote
+	}{
+		{
+			name:   "flat rate",
+			config: shipping.Config{Calculator: shipping.CalculatorFlat, Rates: rates},
+			weight: 2500,
+			want:   []entity.ShippingQuote{{Courier: "jne", Service: "REG", Cost: 9000, EstimatedDays: "2-3"}},
+		},
+		{
+			name:   "weight rate charges every started kilogram",
+			config: shipping.Config{Calculator: shipping.CalculatorWeight, Rates: rates},
+			weight: 2500,
+			want:   []entity.ShippingQuote{{Courier: "jne", Service: "REG", Cost: 27000, EstimatedDays: "2-3"}},
+		},
+		{
+			name:   "weight rate charges at least one kilogram",
+			config: shipping.Config{Calculator: shipping.CalculatorWeight, Rates: rates},
+			weight: 0,
+			want:   []entity.ShippingQuote{{Courier: "jne", Service: "REG", Cost: 9000, EstimatedDays: "2-3"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Init(nil, shipping.Init(tt.config))
+			assert.Equal(t, tt.want, s.Quote(context.Background(), tt.weight, "41111"))
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package statusstream
+
+import (
+	"context"
+	"encoding/json"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/redis"
+	"sync"
+)
+
+type Interface interface {
+	// Publish sends the update to the subscribers of its user on every
+	// replica. It is best effort, an update published while redis is down is
+	// lost.
+	Publish(ctx context.Context, update entity.TransactionStatusUpdate) error
+	// Subscribe streams the updates of the user's transactions until ctx is
+	// done, the channel is closed then.
+	Subscribe(ctx context.Context, userID uint) <-chan entity.TransactionStatusUpdate
+}
+
+type Config struct {
+	Channel string
+	// Buffer is the number of updates queued for a slow subscriber, further
+	// updates are dropped until it catches up.
+	Buffer int
+}
+
+type statusStream struct {
+	conf  Config
+	log   log.Interface
+	redis redis.Interface
+
+	// every replica holds one redis subscription and fans the updates out to
+	// its own subscribers
+	once        sync.Once
+	mu          sync.RWMutex
+	subscribers map[uint]map[chan entity.TransactionStatusUpdate]struct{}
+}
+
+func Init(cfg Config, log log.Interface, redis redis.Interface) Interface {
+	if cfg.Channel == "" {
+		cfg.Channel = "synapsis:transaction-status"
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 16
+	}
+
+	s := &statusStream{
+		conf:        cfg,
+		log:         log,
+		redis:       redis,
+		subscribers: map[uint]map[chan entity.TransactionStatusUpdate]struct{}{},
+	}
+
+	return s
+}
+
+func (s *statusStream) Publish(ctx context.Context, update entity.TransactionStatusUpdate) error {
+	raw, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	return s.redis.Publish(ctx, s.conf.Channel, string(raw))
+}
+
+func (s *statusStream) Subscribe(ctx context.Context, userID uint) <-chan entity.TransactionStatusUpdate {
+	s.once.Do(func() {
+		go s.listen(s.redis.Subscribe(context.Background(), s.conf.Channel))
+	})
+
+	ch := make(chan entity.TransactionStatusUpdate, s.conf.Buffer)
+
+	s.mu.Lock()
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = map[chan entity.TransactionStatusUpdate]struct{}{}
+	}
+	s.subscribers[userID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		s.mu.Lock()
+		delete(s.subscribers[userID], ch)
+		if len(s.subscribers[userID]) == 0 {
+			delete(s.subscribers, userID)
+		}
+		s.mu.Unlock()
+
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (s *statusStream) listen(sub redis.Subscription) {
+	ctx := context.Background()
+
+	for msg := range sub.Channel() {
+		update := entity.TransactionStatusUpdate{}
+		if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+			s.log.Error(ctx, "failed to decode transaction status update", "error", err)
+			continue
+		}
+
+		s.mu.RLock()
+		for ch := range s.subscribers[update.UserID] {
+			select {
+			case ch <- update:
+			default:
+				s.log.Warn(ctx, "transaction status subscriber is too slow, update dropped", "user_id", update.UserID, "transaction_id", update.TransactionID)
+			}
+		}
+		s.mu.RUnlock()
+	}
+}
@@ -0,0 +1,101 @@
+package statusstream
+
+import (
+	"context"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/redis"
+	mock_redis "go-clean/src/lib/tests/mock/redis"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_statusStream_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis := mock_redis.NewMockInterface(ctrl)
+
+	type mockFields struct {
+		redis *mock_redis.MockInterface
+	}
+
+	mocks := mockFields{
+		redis: mockRedis,
+	}
+
+	mockUpdate := entity.TransactionStatusUpdate{
+		TransactionID: 2,
+		UserID:        1,
+		OrderID:       "order-2",
+		Status:        entity.StatusSuccess,
+		UpdatedAt:     time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	mockMessage := `{"transaction_id":2,"user_id":1,"order_id":"order-2","status":"success","updated_at":"2023-01-02T03:04:05Z"}`
+
+	s := Init(Config{}, log.Init(log.Config{Level: "disabled"}), mockRedis)
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockFields)
+		wantErr  bool
+	}{
+		{
+			name: "failed to publish",
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().Publish(context.Background(), "synapsis:transaction-status", mockMessage).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockFields) {
+				mock.redis.EXPECT().Publish(context.Background(), "synapsis:transaction-status", mockMessage).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			err := s.Publish(context.Background(), mockUpdate)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_statusStream_Subscribe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedis := mock_redis.NewMockInterface(ctrl)
+	mockSubscription := mock_redis.NewMockSubscription(ctrl)
+
+	messages := make(chan *redis.Message, 3)
+	mockRedis.EXPECT().Subscribe(gomock.Any(), "synapsis:transaction-status").Return(mockSubscription)
+	mockSubscription.EXPECT().Channel().Return(messages)
+
+	s := Init(Config{}, log.Init(log.Config{Level: "disabled"}), mockRedis)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := s.Subscribe(ctx, 1)
+
+	// updates of other users and broken messages are skipped
+	messages <- &redis.Message{Payload: `{"transaction_id":3,"user_id":2,"status":"success"}`}
+	messages <- &redis.Message{Payload: `not json`}
+	messages <- &redis.Message{Payload: `{"transaction_id":2,"user_id":1,"status":"success"}`}
+
+	select {
+	case update := <-updates:
+		assert.Equal(t, entity.TransactionStatusUpdate{TransactionID: 2, UserID: 1, Status: entity.StatusSuccess}, update)
+	case <-time.After(time.Second):
+		t.Fatal("no update received")
+	}
+
+	cancel()
+	_, ok := <-updates
+	assert.False(t, ok)
+}
@@ -1,14 +1,16 @@
 package transaction
 
 import (
+	"context"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/sql"
 
 	"gorm.io/gorm"
 )
 
 type Interface interface {
-	Create(transaction entity.Transaction) (entity.Transaction, error)
-	Get(param entity.TransactionParam) (entity.Transaction, error)
+	Create(ctx context.Context, transaction entity.Transaction) (entity.Transaction, error)
+	Get(ctx context.Context, param entity.TransactionParam) (entity.Transaction, error)
 }
 
 type transaction struct {
@@ -23,18 +25,18 @@ func Init(db *gorm.DB) Interface {
 	return t
 }
 
-func (t *transaction) Create(transaction entity.Transaction) (entity.Transaction, error) {
-	if err := t.db.Create(&transaction).Error; err != nil {
+func (t *transaction) Create(ctx context.Context, transaction entity.Transaction) (entity.Transaction, error) {
+	if err := sql.Conn(ctx, t.db).Create(&transaction).Error; err != nil {
 		return transaction, err
 	}
 
 	return transaction, nil
 }
 
-func (t *transaction) Get(param entity.TransactionParam) (entity.Transaction, error) {
+func (t *transaction) Get(ctx context.Context, param entity.TransactionParam) (entity.Transaction, error) {
 	transaction := entity.Transaction{}
 
-	if err := t.db.Where(param).First(&transaction).Error; err != nil {
+	if err := sql.Conn(ctx, t.db).Where(param).First(&transaction).Error; err != nil {
 		return transaction, err
 	}
 
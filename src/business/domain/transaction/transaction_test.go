@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"context"
 	"database/sql"
 	"go-clean/src/business/entity"
 	"regexp"
@@ -81,7 +82,7 @@ func Test_transaction_Create(t *testing.T) {
 			}
 
 			u := Init(sqlClient)
-			_, err = u.Create(tt.args.transaction)
+			_, err = u.Create(context.Background(), tt.args.transaction)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("transaction.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -157,7 +158,7 @@ func Test_transaction_Get(t *testing.T) {
 			}
 
 			u := Init(sqlClient)
-			got, err := u.Get(tt.args.param)
+			got, err := u.Get(context.Background(), tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("transaction.Get() error = %v, wantErr %v", err, tt.wantErr)
 				return
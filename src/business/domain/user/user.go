@@ -1,14 +1,23 @@
 package user
 
 import (
+	"context"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/sql"
 
 	"gorm.io/gorm"
 )
 
 type Interface interface {
-	Create(user entity.User) (entity.User, error)
-	Get(param entity.UserParam) (entity.User, error)
+	Create(ctx context.Context, user entity.User) (entity.User, error)
+	Get(ctx context.Context, param entity.UserParam) (entity.User, error)
+	Update(ctx context.Context, selectParam entity.UserParam, updateParam entity.UpdateUserParam) error
+	// UseTOTPStep records step as the last TOTP time step accepted for the
+	// user. It is false when that step or a later one was accepted already.
+	UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	// UseRecoveryCodes replaces the recovery codes of the user with
+	// remaining. It is false when another request changed codes first.
+	UseRecoveryCodes(ctx context.Context, userID uint, codes string, remaining string) (bool, error)
 }
 
 type user struct {
@@ -23,19 +32,49 @@ func Init(db *gorm.DB) Interface {
 	return a
 }
 
-func (u *user) Create(user entity.User) (entity.User, error) {
-	if err := u.db.Create(&user).Error; err != nil {
+func (u *user) Create(ctx context.Context, user entity.User) (entity.User, error) {
+	if err := sql.Conn(ctx, u.db).Create(&user).Error; err != nil {
 		return user, err
 	}
 
 	return user, nil
 }
 
-func (u *user) Get(param entity.UserParam) (entity.User, error) {
+func (u *user) Get(ctx context.Context, param entity.UserParam) (entity.User, error) {
 	user := entity.User{}
-	if err := u.db.Where(param).First(&user).Error; err != nil {
+	if err := sql.Conn(ctx, u.db).Where(param).First(&user).Error; err != nil {
 		return user, err
 	}
 
 	return user, nil
 }
+
+func (u *user) Update(ctx context.Context, selectParam entity.UserParam, updateParam entity.UpdateUserParam) error {
+	if err := sql.Conn(ctx, u.db).Model(entity.User{}).Where(selectParam).Updates(updateParam).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *user) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	res := sql.Conn(ctx, u.db).Model(entity.User{}).
+		Where("id = ? AND mfa_last_step < ?", userID, step).
+		Update("mfa_last_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (u *user) UseRecoveryCodes(ctx context.Context, userID uint, codes string, remaining string) (bool, error) {
+	res := sql.Conn(ctx, u.db).Model(entity.User{}).
+		Where("id = ? AND mfa_recovery_codes = ?", userID, codes).
+		Update("mfa_recovery_codes", remaining)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
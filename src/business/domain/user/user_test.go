@@ -1,7 +1,9 @@
 package user
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"go-clean/src/business/entity"
 	"regexp"
 	"testing"
@@ -82,7 +84,7 @@ func Test_user_Create(t *testing.T) {
 			}
 
 			u := Init(sqlClient)
-			_, err = u.Create(tt.args.user)
+			_, err = u.Create(context.Background(), tt.args.user)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("user.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -162,7 +164,7 @@ func Test_user_Get(t *testing.T) {
 			}
 
 			u := Init(sqlClient)
-			got, err := u.Get(tt.args.param)
+			got, err := u.Get(context.Background(), tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("user.Get() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -171,3 +173,229 @@ func Test_user_Get(t *testing.T) {
 		})
 	}
 }
+
+func Test_user_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	querySql := "UPDATE"
+	query := regexp.QuoteMeta(querySql)
+
+	mockSelectParam := entity.UserParam{
+		ID: 1,
+	}
+	mockUpdateParam := entity.UpdateUserParam{
+		Password: "password",
+	}
+
+	type args struct {
+		selectParam entity.UserParam
+		updateParam entity.UpdateUserParam
+	}
+	tests := []struct {
+		name        string
+		args        args
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			args: args{
+				selectParam: mockSelectParam,
+				updateParam: mockUpdateParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectQuery(query).WillReturnError(assert.AnError)
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			args: args{
+				selectParam: mockSelectParam,
+				updateParam: mockUpdateParam,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				sqlMock.ExpectationsWereMet()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			u := Init(sqlClient)
+			err = u.Update(context.Background(), tt.args.selectParam, tt.args.updateParam)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_user_UseTOTPStep(t *testing.T) {
+	query := regexp.QuoteMeta("UPDATE `users` SET `mfa_last_step`=?,`updated_at`=? WHERE (id = ? AND mfa_last_step < ?) AND `users`.`deleted_at` IS NULL")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "step accepted already",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(int64(56666666), sqlmock.AnyArg(), 1, int64(56666666)).WillReturnResult(driver.RowsAffected(0))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(int64(56666666), sqlmock.AnyArg(), 1, int64(56666666)).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    true,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			u := Init(sqlClient)
+			got, err := u.UseTOTPStep(context.Background(), uint(1), int64(56666666))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.UseTOTPStep() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_user_UseRecoveryCodes(t *testing.T) {
+	query := regexp.QuoteMeta("UPDATE `users` SET `mfa_recovery_codes`=?,`updated_at`=? WHERE (id = ? AND mfa_recovery_codes = ?) AND `users`.`deleted_at` IS NULL")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name: "failed to exec query",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "codes changed by another request",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(`["b"]`, sqlmock.AnyArg(), 1, `["a","b"]`).WillReturnResult(driver.RowsAffected(0))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(`["b"]`, sqlmock.AnyArg(), 1, `["a","b"]`).WillReturnResult(driver.RowsAffected(1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    true,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlServer,
+				SkipInitializeWithVersion: true,
+			}))
+			if err != nil {
+				t.Error(err)
+			}
+
+			u := Init(sqlClient)
+			got, err := u.UseRecoveryCodes(context.Background(), uint(1), `["a","b"]`, `["b"]`)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.UseRecoveryCodes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/sql"
+	webhookSdk "go-clean/src/lib/webhook"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deliveryLogLimit caps the deliveries listed per subscription.
+const deliveryLogLimit = 100
+
+type Interface interface {
+	Create(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error)
+	GetList(ctx context.Context, param entity.WebhookSubscriptionParam) ([]entity.WebhookSubscription, error)
+	Get(ctx context.Context, param entity.WebhookSubscriptionParam) (entity.WebhookSubscription, error)
+	Update(ctx context.Context, selectParam entity.WebhookSubscriptionParam, updateParam entity.UpdateWebhookSubscriptionParam) error
+	Delete(ctx context.Context, param entity.WebhookSubscriptionParam) error
+	// AddDeliveries skips deliveries of an event already enqueued for the
+	// subscription, so an event handled twice is sent once.
+	AddDeliveries(ctx context.Context, deliveries ...entity.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, param entity.WebhookDeliveryParam) ([]entity.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, param entity.WebhookDeliveryParam) (entity.WebhookDelivery, error)
+	// ClaimDeliveries leases up to limit due deliveries for lease, like the
+	// outbox claim.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, id uint, attempt entity.WebhookAttempt) error
+	Send(ctx context.Context, req webhookSdk.Request) (webhookSdk.Response, error)
+}
+
+type webhook struct {
+	db *gorm.DB
+	wh webhookSdk.Interface
+}
+
+func Init(db *gorm.DB, wh webhookSdk.Interface) Interface {
+	w := &webhook{
+		db: db,
+		wh: wh,
+	}
+
+	return w
+}
+
+func (w *webhook) Create(ctx context.Context, subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	if err := sql.Conn(ctx, w.db).Create(&subscription).Error; err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+func (w *webhook) GetList(ctx context.Context, param entity.WebhookSubscriptionParam) ([]entity.WebhookSubscription, error) {
+	subscriptions := []entity.WebhookSubscription{}
+	if err := sql.Conn(ctx, w.db).Where(param).Order("id").Find(&subscriptions).Error; err != nil {
+		return subscriptions, err
+	}
+
+	return subscriptions, nil
+}
+
+func (w *webhook) Get(ctx context.Context, param entity.WebhookSubscriptionParam) (entity.WebhookSubscription, error) {
+	subscription := entity.WebhookSubscription{}
+	if err := sql.Conn(ctx, w.db).Where(param).First(&subscription).Error; err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+func (w *webhook) Update(ctx context.Context, selectParam entity.WebhookSubscriptionParam, updateParam entity.UpdateWebhookSubscriptionParam) error {
+	updates := map[string]interface{}{}
+	if updateParam.URL != "" {
+		updates["url"] = updateParam.URL
+	}
+	if updateParam.Secret != "" {
+		updates["secret"] = updateParam.Secret
+	}
+	if len(updateParam.EventTypes) > 0 {
+		// map updates skip the serializer of the column
+		eventTypes, err := json.Marshal(updateParam.EventTypes)
+		if err != nil {
+			return err
+		}
+		updates["event_types"] = string(eventTypes)
+	}
+	if updateParam.IsActive != nil {
+		updates["is_active"] = *updateParam.IsActive
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return sql.Conn(ctx, w.db).Model(&entity.WebhookSubscription{}).Where(selectParam).Updates(updates).Error
+}
+
+func (w *webhook) Delete(ctx context.Context, param entity.WebhookSubscriptionParam) error {
+	if rowsAffected := sql.Conn(ctx, w.db).Where(param).Delete(&entity.WebhookSubscription{}).RowsAffected; rowsAffected == 0 {
+		return apperror.NotFound("data not found to be deleted").WithKey("error.delete_not_found")
+	}
+
+	return nil
+}
+
+func (w *webhook) AddDeliveries(ctx context.Context, deliveries ...entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return sql.Conn(ctx, w.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+func (w *webhook) GetDeliveries(ctx context.Context, param entity.WebhookDeliveryParam) ([]entity.WebhookDelivery, error) {
+	deliveries := []entity.WebhookDelivery{}
+	if err := sql.Conn(ctx, w.db).Where(param).Order("id desc").Limit(deliveryLogLimit).Find(&deliveries).Error; err != nil {
+		return deliveries, err
+	}
+
+	return deliveries, nil
+}
+
+func (w *webhook) GetDelivery(ctx context.Context, param entity.WebhookDeliveryParam) (entity.WebhookDelivery, error) {
+	delivery := entity.WebhookDelivery{}
+	if err := sql.Conn(ctx, w.db).Where(param).First(&delivery).Error; err != nil {
+		return delivery, err
+	}
+
+	return delivery, nil
+}
+
+func (w *webhook) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	deliveries := []entity.WebhookDelivery{}
+
+	err := sql.WithTx(ctx, w.db, func(ctx context.Context) error {
+		now := time.Now()
+		if err := sql.Conn(ctx, w.db).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.WebhookDeliveryStatusPending, now).
+			Order("id").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, d := range deliveries {
+			ids = append(ids, d.ID)
+		}
+
+		return sql.Conn(ctx, w.db).Model(&entity.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+
+	return deliveries, err
+}
+
+func (w *webhook) RecordAttempt(ctx context.Context, id uint, attempt entity.WebhookAttempt) error {
+	updates := map[string]interface{}{
+		"status":          attempt.Status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": attempt.ResponseStatus,
+		"response_body":   attempt.ResponseBody,
+		"last_error":      attempt.LastError,
+	}
+	switch attempt.Status {
+	case entity.WebhookDeliveryStatusSuccess:
+		updates["delivered_at"] = time.Now()
+	case entity.WebhookDeliveryStatusPending:
+		updates["next_attempt_at"] = attempt.NextAttemptAt
+	}
+
+	return sql.Conn(ctx, w.db).Model(&entity.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (w *webhook) Send(ctx context.Context, req webhookSdk.Request) (webhookSdk.Response, error) {
+	return w.wh.Send(ctx, req)
+}
@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"go-clean/src/business/entity"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func initWebhook(t *testing.T, sqlServer *sql.DB) Interface {
+	sqlClient, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlServer,
+		SkipInitializeWithVersion: true,
+	}))
+	if err != nil {
+		t.Error(err)
+	}
+
+	return Init(sqlClient, nil)
+}
+
+func Test_webhook_Update(t *testing.T) {
+	query := regexp.QuoteMeta("UPDATE `webhook_subscriptions` SET `event_types`=?,`is_active`=?,`updated_at`=? WHERE `webhook_subscriptions`.`id` = ? AND `webhook_subscriptions`.`deleted_at` IS NULL")
+
+	isActive := false
+
+	tests := []struct {
+		name        string
+		updateParam entity.UpdateWebhookSubscriptionParam
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name:        "nothing to update",
+			updateParam: entity.UpdateWebhookSubscriptionParam{},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, _, err := sqlmock.New()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed to exec query",
+			updateParam: entity.UpdateWebhookSubscriptionParam{
+				EventTypes: []string{entity.EventPaymentSettled},
+				IsActive:   &isActive,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			updateParam: entity.UpdateWebhookSubscriptionParam{
+				EventTypes: []string{entity.EventPaymentSettled},
+				IsActive:   &isActive,
+			},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WithArgs(`["payment.settled"]`, false, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			w := initWebhook(t, sqlServer)
+			err = w.Update(context.Background(), entity.WebhookSubscriptionParam{ID: 1}, tt.updateParam)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("webhook.Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_webhook_AddDeliveries(t *testing.T) {
+	query := regexp.QuoteMeta("INSERT INTO `webhook_deliveries`")
+
+	mockDelivery := entity.WebhookDelivery{
+		SubscriptionID: 1,
+		EventID:        1,
+		EventType:      entity.EventPaymentSettled,
+		Payload:        `{"id":1}`,
+		Status:         entity.WebhookDeliveryStatusPending,
+	}
+
+	tests := []struct {
+		name        string
+		deliveries  []entity.WebhookDelivery
+		prepSqlMock func() (*sql.DB, error)
+		wantErr     bool
+	}{
+		{
+			name:       "no delivery",
+			deliveries: nil,
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, _, err := sqlmock.New()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+		{
+			name:       "failed to insert",
+			deliveries: []entity.WebhookDelivery{mockDelivery},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			wantErr: true,
+		},
+		{
+			name:       "all ok",
+			deliveries: []entity.WebhookDelivery{mockDelivery},
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectExec(query + ".*ON DUPLICATE KEY UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			w := initWebhook(t, sqlServer)
+			err = w.AddDeliveries(context.Background(), tt.deliveries...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("webhook.AddDeliveries() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_webhook_ClaimDeliveries(t *testing.T) {
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `webhook_deliveries` WHERE status = ? AND next_attempt_at <= ? ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED")
+	updateQuery := regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `next_attempt_at`=?,`updated_at`=? WHERE id IN (?)")
+
+	tests := []struct {
+		name        string
+		prepSqlMock func() (*sql.DB, error)
+		want        []uint
+		wantErr     bool
+	}{
+		{
+			name: "failed to select",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectQuery(selectQuery).WillReturnError(assert.AnError)
+				sqlMock.ExpectRollback()
+				return sqlServer, err
+			},
+			want:    []uint{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			prepSqlMock: func() (*sql.DB, error) {
+				sqlServer, sqlMock, err := sqlmock.New()
+				sqlMock.ExpectBegin()
+				sqlMock.ExpectQuery(selectQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				sqlMock.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				sqlMock.ExpectCommit()
+				return sqlServer, err
+			},
+			want:    []uint{1},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlServer, err := tt.prepSqlMock()
+			if err != nil {
+				t.Error(err)
+			}
+			defer sqlServer.Close()
+
+			w := initWebhook(t, sqlServer)
+			got, err := w.ClaimDeliveries(context.Background(), 10, time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("webhook.ClaimDeliveries() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			ids := []uint{}
+			for _, d := range got {
+				ids = append(ids, d.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
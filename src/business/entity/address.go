@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type Address struct {
+	gorm.Model
+	UserID     uint
+	Recipient  string
+	Phone      string
+	Street     string
+	City       string
+	Province   string
+	PostalCode string
+	IsDefault  bool
+}
+
+// ShippingAddress is the address snapshot stored on a transaction, so later
+// changes in the address book do not alter placed orders.
+type ShippingAddress struct {
+	Recipient  string
+	Phone      string
+	Street     string
+	City       string
+	Province   string
+	PostalCode string
+}
+
+type AddressParam struct {
+	ID        uint `uri:"address_id"`
+	UserID    uint
+	IsDefault bool
+}
+
+type CreateAddressParam struct {
+	Recipient  string `binding:"required"`
+	Phone      string `binding:"required"`
+	Street     string `binding:"required"`
+	City       string `binding:"required"`
+	Province   string `binding:"required"`
+	PostalCode string `binding:"required"`
+	IsDefault  bool
+}
+
+type UpdateAddressParam struct {
+	Recipient  string
+	Phone      string
+	Street     string
+	City       string
+	Province   string
+	PostalCode string
+	IsDefault  bool `gorm:"-:all"`
+}
+
+func (a *Address) ToShippingAddress() ShippingAddress {
+	return ShippingAddress{
+		Recipient:  a.Recipient,
+		Phone:      a.Phone,
+		Street:     a.Street,
+		City:       a.City,
+		Province:   a.Province,
+		PostalCode: a.PostalCode,
+	}
+}
+
+func (sa ShippingAddress) String() string {
+	parts := []string{}
+	for _, p := range []string{sa.Street, sa.City, sa.Province, sa.PostalCode} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	return fmt.Sprintf("%s (%s), %s", sa.Recipient, sa.Phone, strings.Join(parts, ", "))
+}
@@ -0,0 +1,26 @@
+package entity
+
+const (
+	CacheNamespaceProduct   = "product"
+	CacheNamespaceCategory  = "category"
+	CacheNamespaceRateLimit = "ratelimit"
+	CacheNamespaceLogin     = "login"
+)
+
+// CacheNamespaces lists the namespaces under synapsis:* that can be flushed,
+// the cache usecase checks the requested namespaces against it.
+var CacheNamespaces = []string{
+	CacheNamespaceProduct,
+	CacheNamespaceCategory,
+	CacheNamespaceRateLimit,
+	CacheNamespaceLogin,
+}
+
+type FlushCacheParam struct {
+	Namespaces []string `json:"namespaces" binding:"required,min=1"`
+}
+
+type FlushCacheResult struct {
+	Namespace string `json:"namespace"`
+	Deleted   int64  `json:"deleted"`
+}
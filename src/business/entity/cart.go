@@ -6,6 +6,10 @@ const (
 	StatusInCart = "in_cart"
 	StatusUnpaid = "unpaid"
 	StatusPaid   = "paid"
+
+	// MaxCartQty is the most of a product one cart line holds, the max of
+	// CreateCartParam.Qty.
+	MaxCartQty = 100
 )
 
 type Cart struct {
@@ -30,7 +34,7 @@ type CartParam struct {
 
 type CreateCartParam struct {
 	ProductID uint `binding:"required"`
-	Qty       int  `binding:"required"`
+	Qty       int  `binding:"required,min=1,max=100"`
 }
 
 type UpdateCartParam struct {
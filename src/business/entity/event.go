@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types are stable, subscribers and the redis stream consumers match on
+// them.
+const (
+	EventOrderCreated    = "order.created"
+	EventOrderCancelled  = "order.cancelled"
+	EventOrderShipped    = "order.shipped"
+	EventOrderDelivered  = "order.delivered"
+	EventPaymentSettled  = "payment.settled"
+	EventPaymentFailed   = "payment.failed"
+	EventPaymentRefunded = "payment.refunded"
+	EventUserRegistered  = "user.registered"
+)
+
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusDispatched = "dispatched"
+	OutboxStatusFailed     = "failed"
+)
+
+// OutboxEvent is a domain event written in the db transaction of the state
+// change it records, and delivered by the event dispatcher afterwards.
+type OutboxEvent struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	Type          string
+	AggregateID   uint
+	Payload       string
+	Status        string `gorm:"default:pending"`
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	DispatchedAt  *time.Time
+}
+
+type OrderCreatedPayload struct {
+	TransactionID uint   `json:"transaction_id"`
+	UserID        uint   `json:"user_id"`
+	TotalPrice    int64  `json:"total_price"`
+	PaymentType   int    `json:"payment_type"`
+	OrderID       string `json:"order_id"`
+}
+
+type OrderCancelledPayload struct {
+	TransactionID uint   `json:"transaction_id"`
+	UserID        uint   `json:"user_id"`
+	OrderID       string `json:"order_id"`
+	RestoredCart  bool   `json:"restored_cart"`
+}
+
+type OrderShippedPayload struct {
+	TransactionID  uint   `json:"transaction_id"`
+	ShipmentID     uint   `json:"shipment_id"`
+	Courier        string `json:"courier"`
+	Service        string `json:"service"`
+	TrackingNumber string `json:"tracking_number"`
+	// Status is the fulfilment of the order after the shipment.
+	Status string `json:"status"`
+}
+
+type OrderDeliveredPayload struct {
+	TransactionID  uint   `json:"transaction_id"`
+	ShipmentID     uint   `json:"shipment_id"`
+	TrackingNumber string `json:"tracking_number"`
+	Status         string `json:"status"`
+}
+
+type PaymentSettledPayload struct {
+	TransactionID uint   `json:"transaction_id"`
+	OrderID       string `json:"order_id"`
+	PaymentType   int    `json:"payment_type"`
+}
+
+type PaymentFailedPayload struct {
+	TransactionID uint   `json:"transaction_id"`
+	OrderID       string `json:"order_id"`
+	PaymentType   int    `json:"payment_type"`
+	Status        string `json:"status"`
+}
+
+// PaymentRefundedPayload is added once per refund, Status is the payment
+// status after it.
+type PaymentRefundedPayload struct {
+	TransactionID uint   `json:"transaction_id"`
+	OrderID       string `json:"order_id"`
+	RefundKey     string `json:"refund_key"`
+	Amount        int64  `json:"amount"`
+	Reason        string `json:"reason"`
+	Status        string `json:"status"`
+}
+
+type UserRegisteredPayload struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// NewOutboxEvent is a pending event of eventType about the aggregate, e.g. the
+// transaction of an order, with payload marshalled to json.
+func NewOutboxEvent(eventType string, aggregateID uint, payload interface{}) (OutboxEvent, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	return OutboxEvent{
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Payload:       string(raw),
+		Status:        OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,20 @@
+package entity
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+	// HealthStatusDegraded is a ready server with a non critical dependency
+	// down, e.g. redis while the fallback cache serves.
+	HealthStatusDegraded = "degraded"
+)
+
+type HealthStatus struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+type HealthCheck struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
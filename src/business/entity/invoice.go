@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ContentTypePDF = "application/pdf"
+
+// Invoice numbers a paid order. It is created the first time the invoice is
+// requested, invoices are numbered in the order they are issued.
+type Invoice struct {
+	gorm.Model
+	TransactionID uint   `gorm:"uniqueIndex"`
+	Number        string `gorm:"size:50"`
+}
+
+// InvoiceCounter is the last invoice number issued with a prefix in a year,
+// the numbers restart every year.
+type InvoiceCounter struct {
+	Prefix string `gorm:"primaryKey;size:20"`
+	Year   int    `gorm:"primaryKey;autoIncrement:false"`
+	Last   int
+}
+
+type InvoiceParam struct {
+	TransactionID uint `uri:"transaction_id"`
+}
+
+// InvoiceDocument is everything printed on an invoice. Prices include tax,
+// TaxBase and Tax break Total down.
+type InvoiceDocument struct {
+	Number          string
+	IssuedAt        time.Time
+	OrderID         string
+	Seller          InvoiceSeller
+	CustomerName    string
+	CustomerEmail   string
+	ShippingAddress string
+	PaymentMethod   string
+	Items           []InvoiceItem
+	Subtotal        int64
+	ShippingCourier string
+	ShippingService string
+	ShippingCost    int64
+	TaxName         string
+	TaxRate         float64
+	TaxBase         int64
+	Tax             int64
+	Total           int64
+}
+
+type InvoiceSeller struct {
+	Name    string
+	Address string
+	// TaxID is the seller's NPWP.
+	TaxID string
+}
+
+type InvoiceItem struct {
+	Name      string
+	Qty       int
+	UnitPrice int64
+	Amount    int64
+}
+
+// Attachment is a generated file, it is served as a download or attached to
+// an email as is.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
@@ -8,6 +8,15 @@ const (
 	StatusDeny      = "deny"
 	StatusFailure   = "failure"
 	StatusPending   = "pending"
+	// StatusCancelled is an order cancelled by its customer before payment,
+	// its carts are marked cancelled as well.
+	StatusCancelled = "cancelled"
+	// StatusRefunded is a payment refunded in full, its carts are marked
+	// refunded as well.
+	StatusRefunded = "refunded"
+	// StatusPartialRefund is a payment with part of its amount refunded, only
+	// the carts refunded in full are marked refunded.
+	StatusPartialRefund = "partial_refund"
 )
 
 type MidtransTransaction struct {
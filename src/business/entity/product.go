@@ -8,6 +8,8 @@ type Product struct {
 	Name        string
 	Description string
 	Price       int
+	// Weight in grams, it prices delivery with the weight calculator.
+	Weight int
 }
 
 type ProductParam struct {
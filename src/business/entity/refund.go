@@ -0,0 +1,52 @@
+package entity
+
+import "gorm.io/gorm"
+
+const (
+	RefundStatusPending = "pending"
+	RefundStatusSuccess = "success"
+	RefundStatusFailed  = "failed"
+)
+
+// Refund is money returned to the customer of a settled order. A refund is
+// recorded as pending before midtrans is called, so a refund that reached
+// midtrans is never lost. Items is empty for a refund made in the midtrans
+// dashboard.
+type Refund struct {
+	gorm.Model
+	TransactionID uint `gorm:"index"`
+	OrderID       string
+	RefundKey     string `gorm:"uniqueIndex;size:191"`
+	Amount        int64
+	Reason        string
+	Items         []RefundItem `gorm:"serializer:json"`
+	Status        string       `gorm:"default:pending"`
+	// UserID is the admin who issued the refund, 0 for refunds learned from
+	// a midtrans notification.
+	UserID    uint
+	LastError string
+}
+
+// RefundItem is a quantity of one cart of the order.
+type RefundItem struct {
+	CartID uint `binding:"required"`
+	Qty    int  `binding:"required,min=1"`
+}
+
+type RefundParam struct {
+	ID            uint
+	TransactionID uint `uri:"transaction_id"`
+	RefundKey     string
+}
+
+type CreateRefundParam struct {
+	// Items refunds part of the order, the whole remaining amount is refunded
+	// when it is empty.
+	Items  []RefundItem `binding:"omitempty,max=100,dive"`
+	Reason string       `binding:"required,max=255"`
+}
+
+type UpdateRefundParam struct {
+	Status    string
+	LastError string
+}
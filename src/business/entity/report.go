@@ -0,0 +1,86 @@
+package entity
+
+import "time"
+
+const ContentTypeCSV = "text/csv"
+
+const (
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+
+	ReportSortUnits   = "units"
+	ReportSortRevenue = "revenue"
+
+	ReportFormatJSON = "json"
+	ReportFormatCSV  = "csv"
+)
+
+// Reports that can be exported as CSV.
+const (
+	ReportRevenue        = "revenue"
+	ReportProducts       = "products"
+	ReportCategories     = "categories"
+	ReportPaymentMethods = "payment_methods"
+	ReportSummary        = "summary"
+)
+
+// ReportParam filters a sales report by the day an order was created, both
+// From and To are included.
+type ReportParam struct {
+	From     time.Time `form:"from" time_format:"2006-01-02"`
+	To       time.Time `form:"to" time_format:"2006-01-02"`
+	Interval string    `form:"interval" binding:"omitempty,oneof=day week month"`
+	SortBy   string    `form:"sort_by" binding:"omitempty,oneof=units revenue"`
+	Limit    int       `form:"limit" binding:"omitempty,min=1,max=100"`
+	Format   string    `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// RevenueReport is the paid orders of one day, week or month. Period is
+// 2006-01-02 by day, 2006-W01 by ISO week and 2006-01 by month.
+type RevenueReport struct {
+	Period  string
+	Orders  int64
+	Revenue int64
+}
+
+type ProductReport struct {
+	ProductID uint
+	Name      string
+	Units     int64
+	Revenue   int64
+}
+
+type CategoryReport struct {
+	CategoryID uint
+	Name       string
+	Units      int64
+	Revenue    int64
+}
+
+type PaymentMethodReport struct {
+	PaymentType   int
+	PaymentMethod string
+	Orders        int64
+	Revenue       int64
+}
+
+// ConversionReport follows the carts and the orders created in the range to
+// their payment, a refunded payment still counts as paid.
+type ConversionReport struct {
+	Carts           int64
+	PaidCarts       int64
+	CartConversion  float64
+	Orders          int64
+	PaidOrders      int64
+	OrderConversion float64
+}
+
+type SalesSummary struct {
+	From              time.Time
+	To                time.Time
+	Orders            int64
+	Revenue           int64
+	AverageOrderValue int64
+	Conversion        ConversionReport
+}
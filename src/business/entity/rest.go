@@ -3,10 +3,20 @@ package entity
 type Response struct {
 	Meta Meta        `json:"meta"`
 	Data interface{} `json:"data"`
+	// Errors lists the invalid fields of a rejected request body.
+	Errors []FieldError `json:"errors,omitempty"`
 }
 
 type Meta struct {
 	Message string `json:"message"`
 	Code    int    `json:"code"`
 	IsError bool   `json:"is_error"`
+	// ErrorCode is a stable machine readable code, set on errors only.
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ShipmentStatusShipped   = "shipped"
+	ShipmentStatusDelivered = "delivered"
+)
+
+// Fulfilment statuses of an order, derived from its shipments.
+const (
+	FulfilmentUnfulfilled      = "unfulfilled"
+	FulfilmentPartiallyShipped = "partially_shipped"
+	FulfilmentShipped          = "shipped"
+	FulfilmentDelivered        = "delivered"
+)
+
+// Shipment is a parcel of an order handed to a courier. An order may be sent
+// in several shipments, each carrying a quantity of some of its carts.
+type Shipment struct {
+	gorm.Model
+	TransactionID  uint `gorm:"index"`
+	Courier        string
+	Service        string
+	TrackingNumber string
+	Items          []ShipmentItem `gorm:"serializer:json"`
+	Status         string         `gorm:"default:shipped"`
+	ShippedAt      *time.Time
+	DeliveredAt    *time.Time
+	// UserID is the admin who shipped the parcel.
+	UserID uint
+}
+
+// ShipmentItem is a quantity of one cart of the order.
+type ShipmentItem struct {
+	CartID uint `binding:"required"`
+	Qty    int  `binding:"required,min=1"`
+}
+
+// ShippingQuote is the price of delivering the user's cart with one courier
+// service.
+type ShippingQuote struct {
+	Courier       string
+	Service       string
+	Cost          int64
+	EstimatedDays string
+}
+
+// Tracking is the fulfilment of an order as shown to its customer.
+type Tracking struct {
+	TransactionID   uint
+	Status          string
+	Courier         string
+	Service         string
+	ShippingAddress string
+	Shipments       []Shipment
+}
+
+type ShipmentParam struct {
+	ID            uint `uri:"shipment_id"`
+	TransactionID uint `uri:"transaction_id"`
+}
+
+type CreateShipmentParam struct {
+	Courier        string `binding:"required,max=50"`
+	Service        string `binding:"required,max=50"`
+	TrackingNumber string `binding:"required,max=100"`
+	// Items ships part of the order, everything that is not shipped yet is
+	// shipped when it is empty.
+	Items []ShipmentItem `binding:"omitempty,max=100,dive"`
+}
+
+type UpdateShipmentParam struct {
+	Status      string
+	DeliveredAt *time.Time
+}
+
+type ShippingQuoteParam struct {
+	AddressID uint `form:"address_id"`
+}
+
+// CartWeight is the weight in grams of the carts, products holds the product
+// of every cart by id.
+func CartWeight(carts []Cart, products map[uint]Product) int {
+	weight := 0
+	for _, c := range carts {
+		weight += c.Qty * products[c.ProductID].Weight
+	}
+
+	return weight
+}
@@ -1,19 +1,50 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Transaction struct {
 	gorm.Model
-	UserID      uint
-	AddressShip string
-	TotalPrice  int64
+	UserID          uint
+	AddressShip     string
+	ShippingAddress ShippingAddress `gorm:"embedded;embeddedPrefix:ship_"`
+	// ShippingCourier and ShippingService are the delivery picked at checkout,
+	// ShippingCost is part of TotalPrice.
+	ShippingCourier string
+	ShippingService string
+	ShippingCost    int64
+	TotalPrice      int64
 }
 
 type CreateTransactionParam struct {
-	AddressShip string `binding:"required"`
-	PaymentID   int    `binding:"required"`
+	AddressID   uint
+	AddressShip string `binding:"required_without=AddressID,max=255"`
+	PaymentID   int    `binding:"required,payment_method"`
+	// Courier and Service pick the delivery from the shipping quotes, the
+	// order is shipped at no cost without them.
+	Courier string `binding:"required_with=Service,max=50"`
+	Service string `binding:"required_with=Courier,max=50"`
 }
 
 type TransactionParam struct {
 	ID uint
 }
+
+type CancelTransactionParam struct {
+	TransactionID uint `uri:"transaction_id"`
+	// RestoreCart puts the items of the order back into the cart.
+	RestoreCart bool `form:"restore_cart"`
+}
+
+// TransactionStatusUpdate is pushed to the owner of a transaction when its
+// payment status changes.
+type TransactionStatusUpdate struct {
+	TransactionID uint      `json:"transaction_id"`
+	UserID        uint      `json:"user_id"`
+	OrderID       string    `json:"order_id"`
+	Status        string    `json:"status"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -6,11 +6,25 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
 type User struct {
 	gorm.Model
-	Username string
-	Password string `json:"-"`
-	Name     string
+	Username         string
+	Password         string `json:"-"`
+	Name             string
+	Email            string
+	Phone            string
+	Role             string `gorm:"default:customer"`
+	MFAEnabled       bool
+	MFASecret        string `json:"-"`
+	MFARecoveryCodes string `json:"-"`
+	// MFALastStep is the last accepted TOTP time step, a code of that step
+	// or an earlier one is not accepted again.
+	MFALastStep int64 `json:"-" gorm:"default:0"`
 }
 
 type UserParam struct {
@@ -19,14 +33,66 @@ type UserParam struct {
 }
 
 type CreateUserParam struct {
-	Username string `binding:"required"`
-	Password string `binding:"required"`
-	Name     string `binding:"required"`
+	Username string `binding:"required,username"`
+	Password string `binding:"required,password"`
+	Name     string `binding:"required,max=100"`
+}
+
+type UpdateUserParam struct {
+	Password         string
+	Name             string
+	Email            string
+	Phone            string
+	MFAEnabled       bool
+	MFASecret        string
+	MFARecoveryCodes string
+}
+
+type UpdateProfileParam struct {
+	Name  string
+	Email string `binding:"omitempty,email"`
+	Phone string
+}
+
+type ChangePasswordParam struct {
+	OldPassword string `binding:"required"`
+	NewPassword string `binding:"required,password,nefield=OldPassword"`
 }
 
 type LoginUserParam struct {
-	Username string `binding:"required"`
-	Password string `binding:"required"`
+	Username  string `binding:"required"`
+	Password  string `binding:"required"`
+	IPAddress string `json:"-"`
+}
+
+type LoginResult struct {
+	Token          string `json:"token,omitempty"`
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
+
+type LoginMFAParam struct {
+	ChallengeToken string `binding:"required"`
+	Code           string `binding:"required"`
+	IPAddress      string `json:"-"`
+}
+
+type MFAEnrolment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type MFACodeParam struct {
+	Code string `binding:"required"`
+}
+
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type LoginAttemptParam struct {
+	Username  string
+	IPAddress string
 }
 
 func (u *User) ConvertToAuthUser() auth.User {
@@ -35,5 +101,8 @@ func (u *User) ConvertToAuthUser() auth.User {
 		Username: u.Username,
 		Password: u.Password,
 		Name:     u.Name,
+		Email:    u.Email,
+		Role:     u.Role,
+		IsAdmin:  u.Role == RoleAdmin,
 	}
 }
@@ -0,0 +1,140 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEventTypes are the events partner systems can subscribe to.
+var WebhookEventTypes = []string{
+	EventOrderCreated,
+	EventOrderCancelled,
+	EventOrderShipped,
+	EventOrderDelivered,
+	EventPaymentSettled,
+	EventPaymentFailed,
+	EventPaymentRefunded,
+}
+
+const (
+	WebhookDeliveryStatusPending = "pending"
+	WebhookDeliveryStatusSuccess = "success"
+	WebhookDeliveryStatusFailed  = "failed"
+)
+
+// WebhookSubscription is a partner endpoint that is sent the events it
+// subscribed to, signed with its secret.
+type WebhookSubscription struct {
+	gorm.Model
+	URL        string
+	Secret     string   `json:"-"`
+	EventTypes []string `gorm:"serializer:json"`
+	IsActive   bool
+}
+
+// WebhookDelivery is one event sent to one subscription. The body is built
+// once when the event is enqueued, so retries and redeliveries send the same
+// bytes.
+type WebhookDelivery struct {
+	ID             uint `gorm:"primarykey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	SubscriptionID uint `gorm:"uniqueIndex:idx_webhook_deliveries_event"`
+	EventID        uint `gorm:"uniqueIndex:idx_webhook_deliveries_event"`
+	EventType      string
+	Payload        string
+	Status         string `gorm:"default:pending"`
+	Attempts       int
+	NextAttemptAt  time.Time
+	ResponseStatus int
+	ResponseBody   string
+	LastError      string
+	DeliveredAt    *time.Time
+}
+
+// WebhookAttempt is the outcome of one delivery attempt.
+type WebhookAttempt struct {
+	Status         string
+	NextAttemptAt  time.Time
+	ResponseStatus int
+	ResponseBody   string
+	LastError      string
+}
+
+// WebhookPayload is the body posted to subscribers.
+type WebhookPayload struct {
+	ID        uint            `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type WebhookSubscriptionParam struct {
+	ID       uint `uri:"webhook_id"`
+	IsActive bool
+}
+
+type CreateWebhookSubscriptionParam struct {
+	URL        string   `binding:"required,url"`
+	Secret     string   `binding:"required,min=16"`
+	EventTypes []string `binding:"required,min=1,dive,webhook_event"`
+	IsActive   *bool
+}
+
+type UpdateWebhookSubscriptionParam struct {
+	URL        string   `binding:"omitempty,url"`
+	Secret     string   `binding:"omitempty,min=16"`
+	EventTypes []string `binding:"omitempty,min=1,dive,webhook_event"`
+	IsActive   *bool
+}
+
+type WebhookDeliveryParam struct {
+	ID             uint `uri:"delivery_id"`
+	SubscriptionID uint `uri:"webhook_id"`
+}
+
+// IsWebhookEventType reports whether subscriptions can be made to eventType.
+func IsWebhookEventType(eventType string) bool {
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subscribes reports whether the subscription wants eventType.
+func (w *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewWebhookDelivery is the pending delivery of event to the subscription.
+func NewWebhookDelivery(subscriptionID uint, event OutboxEvent) (WebhookDelivery, error) {
+	raw, err := json.Marshal(WebhookPayload{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	return WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        string(raw),
+		Status:         WebhookDeliveryStatusPending,
+		NextAttemptAt:  time.Now(),
+	}, nil
+}
@@ -0,0 +1,157 @@
+package address
+
+import (
+	"context"
+	addressDom "go-clean/src/business/domain/address"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/auth"
+)
+
+type Interface interface {
+	Create(ctx context.Context, param entity.CreateAddressParam) (entity.Address, error)
+	GetList(ctx context.Context) ([]entity.Address, error)
+	Get(ctx context.Context, param entity.AddressParam) (entity.Address, error)
+	Update(ctx context.Context, selectParam entity.AddressParam, updateParam entity.UpdateAddressParam) error
+	Delete(ctx context.Context, param entity.AddressParam) error
+}
+
+type address struct {
+	address addressDom.Interface
+	auth    auth.Interface
+}
+
+func Init(ad addressDom.Interface, auth auth.Interface) Interface {
+	a := &address{
+		address: ad,
+		auth:    auth,
+	}
+
+	return a
+}
+
+func (a *address) Create(ctx context.Context, param entity.CreateAddressParam) (entity.Address, error) {
+	result := entity.Address{}
+
+	user, err := a.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	addresses, err := a.address.GetList(ctx, entity.AddressParam{
+		UserID: user.User.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result, err = a.address.Create(ctx, entity.Address{
+		UserID:     user.User.ID,
+		Recipient:  param.Recipient,
+		Phone:      param.Phone,
+		Street:     param.Street,
+		City:       param.City,
+		Province:   param.Province,
+		PostalCode: param.PostalCode,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// the first address in the book always becomes the default one
+	if param.IsDefault || len(addresses) == 0 {
+		if err := a.address.SetDefault(ctx, entity.AddressParam{
+			ID:     result.ID,
+			UserID: user.User.ID,
+		}); err != nil {
+			return result, err
+		}
+		result.IsDefault = true
+	}
+
+	return result, nil
+}
+
+func (a *address) GetList(ctx context.Context) ([]entity.Address, error) {
+	result := []entity.Address{}
+
+	user, err := a.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = a.address.GetList(ctx, entity.AddressParam{
+		UserID: user.User.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (a *address) Get(ctx context.Context, param entity.AddressParam) (entity.Address, error) {
+	result := entity.Address{}
+
+	user, err := a.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = a.address.Get(ctx, entity.AddressParam{
+		ID:     param.ID,
+		UserID: user.User.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (a *address) Update(ctx context.Context, selectParam entity.AddressParam, updateParam entity.UpdateAddressParam) error {
+	user, err := a.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	address, err := a.address.Get(ctx, entity.AddressParam{
+		ID:     selectParam.ID,
+		UserID: user.User.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := a.address.Update(ctx, entity.AddressParam{
+		ID: address.ID,
+	}, updateParam); err != nil {
+		return err
+	}
+
+	if updateParam.IsDefault && !address.IsDefault {
+		if err := a.address.SetDefault(ctx, entity.AddressParam{
+			ID:     address.ID,
+			UserID: user.User.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *address) Delete(ctx context.Context, param entity.AddressParam) error {
+	user, err := a.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := a.address.Delete(ctx, entity.AddressParam{
+		ID:     param.ID,
+		UserID: user.User.ID,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,472 @@
+package address_test
+
+import (
+	"context"
+	mock_address "go-clean/src/business/domain/mock/address"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/address"
+	"go-clean/src/lib/auth"
+	"testing"
+
+	mock_auth "go-clean/src/lib/tests/mock/auth"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func Test_address_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+
+	a := address.Init(addressMock, authMock)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	createParamMock := entity.CreateAddressParam{
+		Recipient:  "mail",
+		Phone:      "0812",
+		Street:     "jl. sudirman",
+		City:       "purwakarta",
+		Province:   "jawa barat",
+		PostalCode: "41111",
+	}
+
+	newAddressMock := entity.Address{
+		UserID:     1,
+		Recipient:  "mail",
+		Phone:      "0812",
+		Street:     "jl. sudirman",
+		City:       "purwakarta",
+		Province:   "jawa barat",
+		PostalCode: "41111",
+	}
+
+	addressResultMock := newAddressMock
+	addressResultMock.ID = 2
+
+	defaultAddressResultMock := addressResultMock
+	defaultAddressResultMock.IsDefault = true
+
+	existingAddressesMock := []entity.Address{
+		{
+			Model: gorm.Model{
+				ID: 1,
+			},
+			UserID:    1,
+			IsDefault: true,
+		},
+	}
+
+	type mockfields struct {
+		auth    *mock_auth.MockInterface
+		address *mock_address.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:    authMock,
+		address: addressMock,
+	}
+
+	type args struct {
+		ctx   context.Context
+		param entity.CreateAddressParam
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields, arg args)
+		args     args
+		want     entity.Address
+		wantErr  bool
+	}{
+		{
+			name: "failed to get auth user",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: createParamMock,
+			},
+			want:    entity.Address{},
+			wantErr: true,
+		},
+		{
+			name: "failed to get address list",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().GetList(context.Background(), entity.AddressParam{UserID: 1}).Return([]entity.Address{}, assert.AnError)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: createParamMock,
+			},
+			want:    entity.Address{},
+			wantErr: true,
+		},
+		{
+			name: "failed to create address",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().GetList(context.Background(), entity.AddressParam{UserID: 1}).Return(existingAddressesMock, nil)
+				mock.address.EXPECT().Create(context.Background(), newAddressMock).Return(entity.Address{}, assert.AnError)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: createParamMock,
+			},
+			want:    entity.Address{},
+			wantErr: true,
+		},
+		{
+			name: "first address becomes default",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().GetList(context.Background(), entity.AddressParam{UserID: 1}).Return([]entity.Address{}, nil)
+				mock.address.EXPECT().Create(context.Background(), newAddressMock).Return(addressResultMock, nil)
+				mock.address.EXPECT().SetDefault(context.Background(), entity.AddressParam{ID: 2, UserID: 1}).Return(nil)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: createParamMock,
+			},
+			want:    defaultAddressResultMock,
+			wantErr: false,
+		},
+		{
+			name: "failed to set default",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().GetList(context.Background(), entity.AddressParam{UserID: 1}).Return([]entity.Address{}, nil)
+				mock.address.EXPECT().Create(context.Background(), newAddressMock).Return(addressResultMock, nil)
+				mock.address.EXPECT().SetDefault(context.Background(), entity.AddressParam{ID: 2, UserID: 1}).Return(assert.AnError)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: createParamMock,
+			},
+			want:    addressResultMock,
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().GetList(context.Background(), entity.AddressParam{UserID: 1}).Return(existingAddressesMock, nil)
+				mock.address.EXPECT().Create(context.Background(), newAddressMock).Return(addressResultMock, nil)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: createParamMock,
+			},
+			want:    addressResultMock,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks, tt.args)
+			got, err := a.Create(tt.args.ctx, tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_address_GetList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+
+	a := address.Init(addressMock, authMock)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	addressesResultMock := []entity.Address{
+		{
+			UserID: 1,
+		},
+	}
+
+	type mockfields struct {
+		auth    *mock_auth.MockInterface
+		address *mock_address.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:    authMock,
+		address: addressMock,
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		want     []entity.Address
+		wantErr  bool
+	}{
+		{
+			name: "failed to get auth user",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			want:    []entity.Address{},
+			wantErr: true,
+		},
+		{
+			name: "failed to get address list",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().GetList(context.Background(), entity.AddressParam{UserID: 1}).Return([]entity.Address{}, assert.AnError)
+			},
+			want:    []entity.Address{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().GetList(context.Background(), entity.AddressParam{UserID: 1}).Return(addressesResultMock, nil)
+			},
+			want:    addressesResultMock,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := a.GetList(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.GetList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_address_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+
+	a := address.Init(addressMock, authMock)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	addressParamMock := entity.AddressParam{
+		ID:     2,
+		UserID: 1,
+	}
+
+	addressResultMock := entity.Address{
+		Model: gorm.Model{
+			ID: 2,
+		},
+		UserID: 1,
+	}
+
+	updateParamMock := entity.UpdateAddressParam{
+		City: "bandung",
+	}
+
+	updateDefaultParamMock := entity.UpdateAddressParam{
+		City:      "bandung",
+		IsDefault: true,
+	}
+
+	type mockfields struct {
+		auth    *mock_auth.MockInterface
+		address *mock_address.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:    authMock,
+		address: addressMock,
+	}
+
+	type args struct {
+		ctx         context.Context
+		selectParam entity.AddressParam
+		updateParam entity.UpdateAddressParam
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields, arg args)
+		args     args
+		wantErr  bool
+	}{
+		{
+			name: "failed to get auth user",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			args: args{
+				ctx:         context.Background(),
+				selectParam: entity.AddressParam{ID: 2},
+				updateParam: updateParamMock,
+			},
+			wantErr: true,
+		},
+		{
+			name: "address not owned by user",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().Get(context.Background(), addressParamMock).Return(entity.Address{}, gorm.ErrRecordNotFound)
+			},
+			args: args{
+				ctx:         context.Background(),
+				selectParam: entity.AddressParam{ID: 2},
+				updateParam: updateParamMock,
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to update address",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().Get(context.Background(), addressParamMock).Return(addressResultMock, nil)
+				mock.address.EXPECT().Update(context.Background(), entity.AddressParam{ID: 2}, updateParamMock).Return(assert.AnError)
+			},
+			args: args{
+				ctx:         context.Background(),
+				selectParam: entity.AddressParam{ID: 2},
+				updateParam: updateParamMock,
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok and set as default",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().Get(context.Background(), addressParamMock).Return(addressResultMock, nil)
+				mock.address.EXPECT().Update(context.Background(), entity.AddressParam{ID: 2}, updateDefaultParamMock).Return(nil)
+				mock.address.EXPECT().SetDefault(context.Background(), addressParamMock).Return(nil)
+			},
+			args: args{
+				ctx:         context.Background(),
+				selectParam: entity.AddressParam{ID: 2},
+				updateParam: updateDefaultParamMock,
+			},
+			wantErr: false,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().Get(context.Background(), addressParamMock).Return(addressResultMock, nil)
+				mock.address.EXPECT().Update(context.Background(), entity.AddressParam{ID: 2}, updateParamMock).Return(nil)
+			},
+			args: args{
+				ctx:         context.Background(),
+				selectParam: entity.AddressParam{ID: 2},
+				updateParam: updateParamMock,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks, tt.args)
+			err := a.Update(tt.args.ctx, tt.args.selectParam, tt.args.updateParam)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_address_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+
+	a := address.Init(addressMock, authMock)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	addressParamMock := entity.AddressParam{
+		ID:     2,
+		UserID: 1,
+	}
+
+	type mockfields struct {
+		auth    *mock_auth.MockInterface
+		address *mock_address.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:    authMock,
+		address: addressMock,
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		wantErr  bool
+	}{
+		{
+			name: "failed to get auth user",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to delete address",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().Delete(context.Background(), addressParamMock).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.address.EXPECT().Delete(context.Background(), addressParamMock).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			err := a.Delete(context.Background(), entity.AddressParam{ID: 2})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("address.Delete() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
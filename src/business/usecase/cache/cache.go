@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	cacheDom "go-clean/src/business/domain/cache"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+)
+
+type Interface interface {
+	Flush(ctx context.Context, param entity.FlushCacheParam) ([]entity.FlushCacheResult, error)
+}
+
+type cache struct {
+	log   log.Interface
+	auth  auth.Interface
+	cache cacheDom.Interface
+}
+
+func Init(log log.Interface, auth auth.Interface, cd cacheDom.Interface) Interface {
+	c := &cache{
+		log:   log,
+		auth:  auth,
+		cache: cd,
+	}
+
+	return c
+}
+
+// Flush drops the cached keys of each namespace. Every namespace is checked
+// before anything is deleted, so a typo does not leave a partial flush.
+func (c *cache) Flush(ctx context.Context, param entity.FlushCacheParam) ([]entity.FlushCacheResult, error) {
+	results := []entity.FlushCacheResult{}
+
+	user, err := c.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return results, err
+	}
+
+	namespaces := []string{}
+	seen := map[string]bool{}
+	for _, ns := range param.Namespaces {
+		if !isCacheNamespace(ns) {
+			return results, apperror.Validation(fmt.Sprintf("unknown cache namespace %q", ns)).WithKey("cache.unknown_namespace", ns)
+		}
+		if seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		namespaces = append(namespaces, ns)
+	}
+
+	for _, ns := range namespaces {
+		deleted, err := c.cache.Flush(ctx, ns)
+		if err != nil {
+			return results, err
+		}
+
+		c.log.Info(ctx, "cache flushed", "audit", true, "user_id", user.User.ID, "namespace", ns, "deleted", deleted)
+		results = append(results, entity.FlushCacheResult{
+			Namespace: ns,
+			Deleted:   deleted,
+		})
+	}
+
+	return results, nil
+}
+
+func isCacheNamespace(namespace string) bool {
+	for _, ns := range entity.CacheNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,121 @@
+package cache_test
+
+import (
+	"context"
+	mock_cache "go-clean/src/business/domain/mock/cache"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/cache"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	"testing"
+
+	mock_auth "go-clean/src/lib/tests/mock/auth"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_cache_Flush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	cacheMock := mock_cache.NewMockInterface(ctrl)
+
+	c := cache.Init(log.Init(log.Config{Level: "disabled"}), authMock, cacheMock)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	type mockfields struct {
+		auth  *mock_auth.MockInterface
+		cache *mock_cache.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:  authMock,
+		cache: cacheMock,
+	}
+
+	type args struct {
+		ctx   context.Context
+		param entity.FlushCacheParam
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields, arg args)
+		args     args
+		want     []entity.FlushCacheResult
+		wantErr  bool
+	}{
+		{
+			name: "failed to get auth user",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: entity.FlushCacheParam{Namespaces: []string{"product"}},
+			},
+			want:    []entity.FlushCacheResult{},
+			wantErr: true,
+		},
+		{
+			name: "unknown namespace",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: entity.FlushCacheParam{Namespaces: []string{"product", "*"}},
+			},
+			want:    []entity.FlushCacheResult{},
+			wantErr: true,
+		},
+		{
+			name: "failed to flush",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cache.EXPECT().Flush(context.Background(), "product").Return(int64(0), assert.AnError)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: entity.FlushCacheParam{Namespaces: []string{"product"}},
+			},
+			want:    []entity.FlushCacheResult{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cache.EXPECT().Flush(context.Background(), "product").Return(int64(4), nil)
+				mock.cache.EXPECT().Flush(context.Background(), "category").Return(int64(1), nil)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: entity.FlushCacheParam{Namespaces: []string{"product", "category", "product"}},
+			},
+			want: []entity.FlushCacheResult{
+				{Namespace: "product", Deleted: 4},
+				{Namespace: "category", Deleted: 1},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks, tt.args)
+			got, err := c.Flush(tt.args.ctx, tt.args.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("cache.Flush() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
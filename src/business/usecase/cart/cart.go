@@ -45,14 +45,14 @@ func (c *cart) Create(ctx context.Context, cartInput entity.CreateCartParam) (en
 		return result, err
 	}
 
-	cartExist, _ := c.cart.Get(entity.CartParam{
+	cartExist, _ := c.cart.Get(ctx, entity.CartParam{
 		UserID:    user.User.ID,
 		ProductID: product.ID,
 		Status:    entity.StatusInCart,
 	})
 
 	if cartExist.ID != 0 {
-		if err := c.cart.Update(entity.CartParam{
+		if err := c.cart.Update(ctx, entity.CartParam{
 			UserID:    user.User.ID,
 			ProductID: product.ID,
 			Status:    entity.StatusInCart,
@@ -65,7 +65,7 @@ func (c *cart) Create(ctx context.Context, cartInput entity.CreateCartParam) (en
 		return cartExist, nil
 	}
 
-	result, err = c.cart.Create(entity.Cart{
+	result, err = c.cart.Create(ctx, entity.Cart{
 		UserID:    user.User.ID,
 		ProductID: product.ID,
 		Qty:       cartInput.Qty,
@@ -86,7 +86,7 @@ func (c *cart) GetList(ctx context.Context) ([]entity.Cart, error) {
 		return result, err
 	}
 
-	result, err = c.cart.GetList(entity.CartParam{
+	result, err = c.cart.GetList(ctx, entity.CartParam{
 		UserID: user.User.ID,
 		Status: entity.StatusInCart,
 	})
@@ -128,7 +128,7 @@ func (c *cart) Delete(ctx context.Context, param entity.CartParam) error {
 		return err
 	}
 
-	if err := c.cart.Delete(entity.CartParam{
+	if err := c.cart.Delete(ctx, entity.CartParam{
 		ID:     param.ID,
 		UserID: user.User.ID,
 		Status: entity.StatusInCart,
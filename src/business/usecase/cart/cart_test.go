@@ -136,8 +136,8 @@ func Test_cart_Create(t *testing.T) {
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
 				mock.product.EXPECT().Get(context.Background(), productParamMock).Return(productResultMock, nil)
-				mock.cart.EXPECT().Get(cartParamMock).Return(cartResultMock, nil)
-				mock.cart.EXPECT().Update(cartUpdateParamMock, cartUpdateMock).Return(assert.AnError)
+				mock.cart.EXPECT().Get(context.Background(), cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), cartUpdateParamMock, cartUpdateMock).Return(assert.AnError)
 			},
 			want:    cartResultMock,
 			wantErr: true,
@@ -151,8 +151,8 @@ func Test_cart_Create(t *testing.T) {
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
 				mock.product.EXPECT().Get(context.Background(), productParamMock).Return(productResultMock, nil)
-				mock.cart.EXPECT().Get(cartParamMock).Return(cartResultMock, nil)
-				mock.cart.EXPECT().Update(cartUpdateParamMock, cartUpdateMock).Return(nil)
+				mock.cart.EXPECT().Get(context.Background(), cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), cartUpdateParamMock, cartUpdateMock).Return(nil)
 			},
 			want:    cartResultMock,
 			wantErr: false,
@@ -166,8 +166,8 @@ func Test_cart_Create(t *testing.T) {
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
 				mock.product.EXPECT().Get(context.Background(), productParamMock).Return(productResultMock, nil)
-				mock.cart.EXPECT().Get(cartParamMock).Return(entity.Cart{}, nil)
-				mock.cart.EXPECT().Create(createCartMock).Return(entity.Cart{}, assert.AnError)
+				mock.cart.EXPECT().Get(context.Background(), cartParamMock).Return(entity.Cart{}, nil)
+				mock.cart.EXPECT().Create(context.Background(), createCartMock).Return(entity.Cart{}, assert.AnError)
 			},
 			want:    entity.Cart{},
 			wantErr: true,
@@ -181,8 +181,8 @@ func Test_cart_Create(t *testing.T) {
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
 				mock.product.EXPECT().Get(context.Background(), productParamMock).Return(productResultMock, nil)
-				mock.cart.EXPECT().Get(cartParamMock).Return(entity.Cart{}, nil)
-				mock.cart.EXPECT().Create(createCartMock).Return(createCartMock, nil)
+				mock.cart.EXPECT().Get(context.Background(), cartParamMock).Return(entity.Cart{}, nil)
+				mock.cart.EXPECT().Create(context.Background(), createCartMock).Return(createCartMock, nil)
 			},
 			want:    createCartMock,
 			wantErr: false,
@@ -302,7 +302,7 @@ func Test_cart_GetList(t *testing.T) {
 			},
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return([]entity.Cart{}, assert.AnError)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return([]entity.Cart{}, assert.AnError)
 			},
 			want:    []entity.Cart{},
 			wantErr: true,
@@ -314,7 +314,7 @@ func Test_cart_GetList(t *testing.T) {
 			},
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), productIDsMock).Return([]entity.Product{}, assert.AnError)
 			},
 			want:    cartResultMock,
@@ -327,7 +327,7 @@ func Test_cart_GetList(t *testing.T) {
 			},
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), productIDsMock).Return(productResultMock, nil)
 			},
 			want:    resultMock,
@@ -410,7 +410,7 @@ func Test_cart_Delete(t *testing.T) {
 			},
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().Delete(cartDeleteParamMock).Return(assert.AnError)
+				mock.cart.EXPECT().Delete(context.Background(), cartDeleteParamMock).Return(assert.AnError)
 			},
 			wantErr: true,
 		},
@@ -422,7 +422,7 @@ func Test_cart_Delete(t *testing.T) {
 			},
 			mockFunc: func(mock mockFields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().Delete(cartDeleteParamMock).Return(nil)
+				mock.cart.EXPECT().Delete(context.Background(), cartDeleteParamMock).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -0,0 +1,190 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	eventStreamDom "go-clean/src/business/domain/event_stream"
+	outboxDom "go-clean/src/business/domain/outbox"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/log"
+	"sync"
+	"time"
+)
+
+// AllEvents subscribes a handler to every event type.
+const AllEvents = "*"
+
+// Handler handles one event. Delivery is at least once, an event is handled
+// again when any subscriber or sink of it failed, so handlers must be
+// idempotent.
+type Handler func(ctx context.Context, event entity.OutboxEvent) error
+
+type Interface interface {
+	Subscribe(eventType string, h Handler)
+	// Dispatch delivers one batch of due outbox events and returns the number
+	// of events claimed.
+	Dispatch(ctx context.Context) (int, error)
+	// Run dispatches until ctx is done.
+	Run(ctx context.Context)
+}
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a claimed event is hidden from other dispatchers, it
+	// must be longer than the slowest delivery.
+	Lease time.Duration
+	// MaxAttempts marks an event failed after that many failed deliveries.
+	MaxAttempts int
+	// RetryBackoff is the delay after the first failure, doubled after each
+	// further failure up to MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// RedisStream publishes every event to the redis stream as well.
+	RedisStream bool
+}
+
+type event struct {
+	conf        Config
+	log         log.Interface
+	outbox      outboxDom.Interface
+	eventStream eventStreamDom.Interface
+
+	mu          sync.RWMutex
+	subscribers map[string][]Handler
+}
+
+func Init(cfg Config, log log.Interface, od outboxDom.Interface, esd eventStreamDom.Interface) Interface {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 5 * time.Second
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = 10 * time.Minute
+	}
+
+	e := &event{
+		conf:        cfg,
+		log:         log,
+		outbox:      od,
+		eventStream: esd,
+		subscribers: map[string][]Handler{},
+	}
+
+	return e
+}
+
+func (e *event) Subscribe(eventType string, h Handler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.subscribers[eventType] = append(e.subscribers[eventType], h)
+}
+
+func (e *event) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.conf.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := e.Dispatch(ctx)
+		if err != nil {
+			e.log.Error(ctx, "failed to dispatch events", "error", err)
+		}
+
+		// a full batch means more events are probably due
+		if err == nil && n == e.conf.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *event) Dispatch(ctx context.Context) (int, error) {
+	events, err := e.outbox.Claim(ctx, e.conf.BatchSize, e.conf.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, ev := range events {
+		if err := e.deliver(ctx, ev); err != nil {
+			e.retry(ctx, ev, err)
+			continue
+		}
+
+		if err := e.outbox.MarkDispatched(ctx, ev.ID); err != nil {
+			// the event is delivered again once its lease expires
+			e.log.Error(ctx, "failed to mark event dispatched", "event_id", ev.ID, "error", err)
+			continue
+		}
+
+		e.log.Debug(ctx, "event dispatched", "event_id", ev.ID, "event_type", ev.Type)
+	}
+
+	return len(events), nil
+}
+
+func (e *event) deliver(ctx context.Context, ev entity.OutboxEvent) error {
+	e.mu.RLock()
+	handlers := append(append([]Handler{}, e.subscribers[ev.Type]...), e.subscribers[AllEvents]...)
+	e.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, ev); err != nil {
+			return fmt.Errorf("subscriber : %w", err)
+		}
+	}
+
+	if e.conf.RedisStream {
+		if err := e.eventStream.Publish(ctx, ev); err != nil {
+			return fmt.Errorf("redis stream : %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (e *event) retry(ctx context.Context, ev entity.OutboxEvent, cause error) {
+	attempts := ev.Attempts + 1
+	if attempts >= e.conf.MaxAttempts {
+		e.log.Error(ctx, "event delivery failed, giving up", "event_id", ev.ID, "event_type", ev.Type, "attempts", attempts, "error", cause)
+		if err := e.outbox.MarkFailed(ctx, ev.ID, cause.Error()); err != nil {
+			e.log.Error(ctx, "failed to mark event failed", "event_id", ev.ID, "error", err)
+		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(e.backoff(attempts))
+	e.log.Warn(ctx, "event delivery failed, retrying", "event_id", ev.ID, "event_type", ev.Type, "attempts", attempts, "next_attempt_at", nextAttemptAt, "error", cause)
+	if err := e.outbox.MarkRetry(ctx, ev.ID, nextAttemptAt, cause.Error()); err != nil {
+		e.log.Error(ctx, "failed to mark event for retry", "event_id", ev.ID, "error", err)
+	}
+}
+
+func (e *event) backoff(attempts int) time.Duration {
+	backoff := e.conf.RetryBackoff
+	for i := 1; i < attempts && backoff < e.conf.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > e.conf.MaxRetryBackoff {
+		return e.conf.MaxRetryBackoff
+	}
+
+	return backoff
+}
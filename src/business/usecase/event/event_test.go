@@ -0,0 +1,156 @@
+package event_test
+
+import (
+	"context"
+	mock_eventstream "go-clean/src/business/domain/mock/event_stream"
+	mock_outbox "go-clean/src/business/domain/mock/outbox"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/event"
+	"go-clean/src/lib/log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_event_Dispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+	eventStreamMock := mock_eventstream.NewMockInterface(ctrl)
+
+	cfg := event.Config{
+		BatchSize:    10,
+		Lease:        time.Minute,
+		MaxAttempts:  3,
+		RetryBackoff: time.Second,
+		RedisStream:  true,
+	}
+
+	orderCreated := entity.OutboxEvent{
+		ID:          1,
+		Type:        entity.EventOrderCreated,
+		AggregateID: 1,
+		Attempts:    0,
+	}
+
+	lastAttempt := orderCreated
+	lastAttempt.Attempts = 2
+
+	type mockfields struct {
+		outbox      *mock_outbox.MockInterface
+		eventStream *mock_eventstream.MockInterface
+	}
+
+	mocks := mockfields{
+		outbox:      outboxMock,
+		eventStream: eventStreamMock,
+	}
+
+	tests := []struct {
+		name          string
+		mockFunc      func(mock mockfields)
+		subscriberErr error
+		wantHandled   int
+		want          int
+		wantErr       bool
+	}{
+		{
+			name: "failed to claim events",
+			mockFunc: func(mock mockfields) {
+				mock.outbox.EXPECT().Claim(context.Background(), 10, time.Minute).Return(nil, assert.AnError)
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "no event due",
+			mockFunc: func(mock mockfields) {
+				mock.outbox.EXPECT().Claim(context.Background(), 10, time.Minute).Return([]entity.OutboxEvent{}, nil)
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name:          "subscriber failed",
+			subscriberErr: assert.AnError,
+			mockFunc: func(mock mockfields) {
+				mock.outbox.EXPECT().Claim(context.Background(), 10, time.Minute).Return([]entity.OutboxEvent{orderCreated}, nil)
+				mock.outbox.EXPECT().MarkRetry(context.Background(), uint(1), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantHandled: 1,
+			want:        1,
+			wantErr:     false,
+		},
+		{
+			name:          "subscriber failed on the last attempt",
+			subscriberErr: assert.AnError,
+			mockFunc: func(mock mockfields) {
+				mock.outbox.EXPECT().Claim(context.Background(), 10, time.Minute).Return([]entity.OutboxEvent{lastAttempt}, nil)
+				mock.outbox.EXPECT().MarkFailed(context.Background(), uint(1), gomock.Any()).Return(nil)
+			},
+			wantHandled: 1,
+			want:        1,
+			wantErr:     false,
+		},
+		{
+			name: "failed to publish to the redis stream",
+			mockFunc: func(mock mockfields) {
+				mock.outbox.EXPECT().Claim(context.Background(), 10, time.Minute).Return([]entity.OutboxEvent{orderCreated}, nil)
+				mock.eventStream.EXPECT().Publish(context.Background(), orderCreated).Return(assert.AnError)
+				mock.outbox.EXPECT().MarkRetry(context.Background(), uint(1), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantHandled: 1,
+			want:        1,
+			wantErr:     false,
+		},
+		{
+			name: "failed to mark dispatched",
+			mockFunc: func(mock mockfields) {
+				mock.outbox.EXPECT().Claim(context.Background(), 10, time.Minute).Return([]entity.OutboxEvent{orderCreated}, nil)
+				mock.eventStream.EXPECT().Publish(context.Background(), orderCreated).Return(nil)
+				mock.outbox.EXPECT().MarkDispatched(context.Background(), uint(1)).Return(assert.AnError)
+			},
+			wantHandled: 1,
+			want:        1,
+			wantErr:     false,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.outbox.EXPECT().Claim(context.Background(), 10, time.Minute).Return([]entity.OutboxEvent{orderCreated}, nil)
+				mock.eventStream.EXPECT().Publish(context.Background(), orderCreated).Return(nil)
+				mock.outbox.EXPECT().MarkDispatched(context.Background(), uint(1)).Return(nil)
+			},
+			wantHandled: 1,
+			want:        1,
+			wantErr:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+
+			handled := 0
+			e := event.Init(cfg, log.Init(log.Config{Level: "disabled"}), outboxMock, eventStreamMock)
+			e.Subscribe(entity.EventOrderCreated, func(ctx context.Context, ev entity.OutboxEvent) error {
+				handled++
+				return tt.subscriberErr
+			})
+			e.Subscribe(entity.EventPaymentSettled, func(ctx context.Context, ev entity.OutboxEvent) error {
+				t.Errorf("unexpected %s event", ev.Type)
+				return nil
+			})
+
+			got, err := e.Dispatch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("event.Dispatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantHandled, handled)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	cartDom "go-clean/src/business/domain/cart"
+	invoiceDom "go-clean/src/business/domain/invoice"
+	midtransTransactionDom "go-clean/src/business/domain/midtrans_transaction"
+	productDom "go-clean/src/business/domain/product"
+	transactionDom "go-clean/src/business/domain/transaction"
+	userDom "go-clean/src/business/domain/user"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/midtrans"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+type Interface interface {
+	// GetPDF is the invoice of a paid order as a PDF file, the invoice is
+	// numbered the first time it is requested.
+	GetPDF(ctx context.Context, param entity.InvoiceParam) (entity.Attachment, error)
+}
+
+type Config struct {
+	Seller entity.InvoiceSeller
+	// TaxName and TaxRate, a percentage, break the tax included in the
+	// prices down. There is no breakdown when TaxRate is zero.
+	TaxName string
+	TaxRate float64
+}
+
+type invoice struct {
+	conf                Config
+	log                 log.Interface
+	invoice             invoiceDom.Interface
+	transaction         transactionDom.Interface
+	midtransTransaction midtransTransactionDom.Interface
+	cart                cartDom.Interface
+	product             productDom.Interface
+	user                userDom.Interface
+}
+
+func Init(cfg Config, log log.Interface, id invoiceDom.Interface, td transactionDom.Interface, mttd midtransTransactionDom.Interface, cd cartDom.Interface, pd productDom.Interface, ud userDom.Interface) Interface {
+	if cfg.TaxName == "" {
+		cfg.TaxName = "PPN"
+	}
+
+	i := &invoice{
+		conf:                cfg,
+		log:                 log,
+		invoice:             id,
+		transaction:         td,
+		midtransTransaction: mttd,
+		cart:                cd,
+		product:             pd,
+		user:                ud,
+	}
+
+	return i
+}
+
+func (i *invoice) GetPDF(ctx context.Context, param entity.InvoiceParam) (entity.Attachment, error) {
+	result := entity.Attachment{}
+
+	doc, err := i.document(ctx, param.TransactionID)
+	if err != nil {
+		return result, err
+	}
+
+	content, err := i.invoice.Render(ctx, doc)
+	if err != nil {
+		return result, err
+	}
+
+	result.Filename = doc.Number + ".pdf"
+	result.ContentType = entity.ContentTypePDF
+	result.Content = content
+
+	return result, nil
+}
+
+func (i *invoice) document(ctx context.Context, transactionID uint) (entity.InvoiceDocument, error) {
+	result := entity.InvoiceDocument{}
+
+	midtransTransaction, err := i.midtransTransaction.Get(ctx, entity.MidtransTransactionParam{
+		TransactionID: transactionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	switch midtransTransaction.Status {
+	case entity.StatusSuccess, entity.StatusPartialRefund, entity.StatusRefunded:
+	default:
+		return result, apperror.Conflict("only paid orders have an invoice").WithKey("invoice.not_paid")
+	}
+
+	transaction, err := i.transaction.Get(ctx, entity.TransactionParam{
+		ID: transactionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	user, err := i.user.Get(ctx, entity.UserParam{
+		ID: transaction.UserID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	items, err := i.items(ctx, transactionID)
+	if err != nil {
+		return result, err
+	}
+
+	invoice, err := i.number(ctx, transactionID)
+	if err != nil {
+		return result, err
+	}
+
+	result.Number = invoice.Number
+	result.IssuedAt = invoice.CreatedAt
+	result.OrderID = midtransTransaction.OrderID
+	result.Seller = i.conf.Seller
+	result.CustomerName = user.Name
+	result.CustomerEmail = user.Email
+	result.ShippingAddress = transaction.AddressShip
+	result.PaymentMethod = midtrans.PaymentNames[midtransTransaction.PaymentType]
+	result.Items = items
+	for _, item := range items {
+		result.Subtotal += item.Amount
+	}
+	result.ShippingCourier = transaction.ShippingCourier
+	result.ShippingService = transaction.ShippingService
+	result.ShippingCost = transaction.ShippingCost
+	result.Total = transaction.TotalPrice
+
+	if i.conf.TaxRate > 0 {
+		result.TaxName = i.conf.TaxName
+		result.TaxRate = i.conf.TaxRate
+		result.Tax = int64(math.Round(float64(result.Total) * i.conf.TaxRate / (100 + i.conf.TaxRate)))
+		result.TaxBase = result.Total - result.Tax
+	}
+
+	return result, nil
+}
+
+// items are the carts the order was paid for, refunds do not change an
+// issued invoice.
+func (i *invoice) items(ctx context.Context, transactionID uint) ([]entity.InvoiceItem, error) {
+	carts, err := i.cart.GetList(ctx, entity.CartParam{
+		TransactionID: transactionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paid := []entity.Cart{}
+	productIDs := []uint{}
+	for _, c := range carts {
+		if c.Status == entity.StatusPaid || c.Status == entity.StatusRefunded {
+			paid = append(paid, c)
+			productIDs = append(productIDs, c.ProductID)
+		}
+	}
+
+	products, err := i.product.GetListByID(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productMap := make(map[uint]entity.Product)
+	for _, p := range products {
+		productMap[p.ID] = p
+	}
+
+	items := []entity.InvoiceItem{}
+	for _, c := range paid {
+		items = append(items, entity.InvoiceItem{
+			Name:      productMap[c.ProductID].Name,
+			Qty:       c.Qty,
+			UnitPrice: int64(c.FinalPricePerItem),
+			Amount:    int64(c.FinalPricePerItem) * int64(c.Qty),
+		})
+	}
+
+	return items, nil
+}
+
+// number is the invoice of the transaction, it is numbered on the first
+// request.
+func (i *invoice) number(ctx context.Context, transactionID uint) (entity.Invoice, error) {
+	invoice, err := i.invoice.Get(ctx, entity.InvoiceParam{
+		TransactionID: transactionID,
+	})
+	if err == nil {
+		return invoice, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return invoice, err
+	}
+
+	invoice, err = i.invoice.Create(ctx, transactionID)
+	if err != nil {
+		return invoice, err
+	}
+
+	i.log.Info(ctx, "invoice issued", "audit", true, "transaction_id", transactionID, "invoice_number", invoice.Number)
+
+	return invoice, nil
+}
@@ -0,0 +1,255 @@
+package invoice_test
+
+import (
+	"context"
+	mock_cart "go-clean/src/business/domain/mock/cart"
+	mock_invoice "go-clean/src/business/domain/mock/invoice"
+	mock_midtrans_transaction "go-clean/src/business/domain/mock/midtrans_transaction"
+	mock_product "go-clean/src/business/domain/mock/product"
+	mock_transaction "go-clean/src/business/domain/mock/transaction"
+	mock_user "go-clean/src/business/domain/mock/user"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/invoice"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/midtrans"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func Test_invoice_GetPDF(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invoiceMock := mock_invoice.NewMockInterface(ctrl)
+	transactionMock := mock_transaction.NewMockInterface(ctrl)
+	midtransTransactionMock := mock_midtrans_transaction.NewMockInterface(ctrl)
+	cartMock := mock_cart.NewMockInterface(ctrl)
+	productMock := mock_product.NewMockInterface(ctrl)
+	userMock := mock_user.NewMockInterface(ctrl)
+
+	sellerMock := entity.InvoiceSeller{
+		Name:    "Synapsis",
+		Address: "Jakarta",
+		TaxID:   "01.234.567.8-901.000",
+	}
+
+	i := invoice.Init(invoice.Config{
+		Seller:  sellerMock,
+		TaxRate: 11,
+	}, log.Init(log.Config{Level: "disabled"}), invoiceMock, transactionMock, midtransTransactionMock, cartMock, productMock, userMock)
+
+	type mockfields struct {
+		invoice             *mock_invoice.MockInterface
+		transaction         *mock_transaction.MockInterface
+		midtransTransaction *mock_midtrans_transaction.MockInterface
+		cart                *mock_cart.MockInterface
+		product             *mock_product.MockInterface
+		user                *mock_user.MockInterface
+	}
+
+	mocks := mockfields{
+		invoice:             invoiceMock,
+		transaction:         transactionMock,
+		midtransTransaction: midtransTransactionMock,
+		cart:                cartMock,
+		product:             productMock,
+		user:                userMock,
+	}
+
+	paramMock := entity.InvoiceParam{
+		TransactionID: 1,
+	}
+
+	settledMock := entity.MidtransTransaction{
+		TransactionID: 1,
+		OrderID:       "SYN-1-1700000000",
+		PaymentType:   midtrans.GopayPayment,
+		Status:        entity.StatusSuccess,
+	}
+
+	pendingMock := settledMock
+	pendingMock.Status = entity.StatusPending
+
+	transactionResultMock := entity.Transaction{
+		Model:           gorm.Model{ID: 1},
+		UserID:          9,
+		AddressShip:     "Jl. Merdeka 10, Bandung",
+		ShippingCourier: "jne",
+		ShippingService: "REG",
+		ShippingCost:    9000,
+		TotalPrice:      259000,
+	}
+
+	userResultMock := entity.User{
+		Model: gorm.Model{ID: 9},
+		Name:  "Budi",
+		Email: "budi@example.com",
+	}
+
+	// only the paid and refunded carts are invoiced
+	cartsMock := []entity.Cart{
+		{
+			Model:             gorm.Model{ID: 1},
+			ProductID:         1,
+			Qty:               2,
+			Status:            entity.StatusPaid,
+			FinalPricePerItem: 100000,
+		},
+		{
+			Model:             gorm.Model{ID: 2},
+			ProductID:         2,
+			Qty:               1,
+			Status:            entity.StatusRefunded,
+			FinalPricePerItem: 50000,
+		},
+		{
+			Model:             gorm.Model{ID: 3},
+			ProductID:         3,
+			Qty:               1,
+			Status:            entity.StatusCancelled,
+			FinalPricePerItem: 70000,
+		},
+	}
+
+	productsMock := []entity.Product{
+		{
+			Model: gorm.Model{ID: 1},
+			Name:  "Kemeja",
+		},
+		{
+			Model: gorm.Model{ID: 2},
+			Name:  "Celana",
+		},
+	}
+
+	issuedAt := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	invoiceResultMock := entity.Invoice{
+		Model:         gorm.Model{ID: 42, CreatedAt: issuedAt},
+		TransactionID: 1,
+		Number:        "INV-2026-000042",
+	}
+
+	docMock := entity.InvoiceDocument{
+		Number:          "INV-2026-000042",
+		IssuedAt:        issuedAt,
+		OrderID:         "SYN-1-1700000000",
+		Seller:          sellerMock,
+		CustomerName:    "Budi",
+		CustomerEmail:   "budi@example.com",
+		ShippingAddress: "Jl. Merdeka 10, Bandung",
+		PaymentMethod:   "GoPay",
+		Items: []entity.InvoiceItem{
+			{Name: "Kemeja", Qty: 2, UnitPrice: 100000, Amount: 200000},
+			{Name: "Celana", Qty: 1, UnitPrice: 50000, Amount: 50000},
+		},
+		Subtotal:        250000,
+		ShippingCourier: "jne",
+		ShippingService: "REG",
+		ShippingCost:    9000,
+		TaxName:         "PPN",
+		TaxRate:         11,
+		TaxBase:         233333,
+		Tax:             25667,
+		Total:           259000,
+	}
+
+	loaded := func(mock mockfields) {
+		mock.midtransTransaction.EXPECT().Get(context.Background(), entity.MidtransTransactionParam{TransactionID: 1}).Return(settledMock, nil)
+		mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+		mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 9}).Return(userResultMock, nil)
+		mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{TransactionID: 1}).Return(cartsMock, nil)
+		mock.product.EXPECT().GetListByID(context.Background(), []uint{1, 2}).Return(productsMock, nil)
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		want     entity.Attachment
+		wantErr  bool
+	}{
+		{
+			name: "failed to get midtrans transaction",
+			mockFunc: func(mock mockfields) {
+				mock.midtransTransaction.EXPECT().Get(context.Background(), entity.MidtransTransactionParam{TransactionID: 1}).Return(settledMock, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "order is not paid",
+			mockFunc: func(mock mockfields) {
+				mock.midtransTransaction.EXPECT().Get(context.Background(), entity.MidtransTransactionParam{TransactionID: 1}).Return(pendingMock, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to get transaction",
+			mockFunc: func(mock mockfields) {
+				mock.midtransTransaction.EXPECT().Get(context.Background(), entity.MidtransTransactionParam{TransactionID: 1}).Return(settledMock, nil)
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to number the invoice",
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.invoice.EXPECT().Get(context.Background(), paramMock).Return(entity.Invoice{}, gorm.ErrRecordNotFound)
+				mock.invoice.EXPECT().Create(context.Background(), uint(1)).Return(entity.Invoice{}, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to render the invoice",
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.invoice.EXPECT().Get(context.Background(), paramMock).Return(invoiceResultMock, nil)
+				mock.invoice.EXPECT().Render(context.Background(), docMock).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok first invoice",
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.invoice.EXPECT().Get(context.Background(), paramMock).Return(entity.Invoice{}, gorm.ErrRecordNotFound)
+				mock.invoice.EXPECT().Create(context.Background(), uint(1)).Return(invoiceResultMock, nil)
+				mock.invoice.EXPECT().Render(context.Background(), docMock).Return([]byte("%PDF-1.4"), nil)
+			},
+			want: entity.Attachment{
+				Filename:    "INV-2026-000042.pdf",
+				ContentType: entity.ContentTypePDF,
+				Content:     []byte("%PDF-1.4"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "all ok numbered already",
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.invoice.EXPECT().Get(context.Background(), paramMock).Return(invoiceResultMock, nil)
+				mock.invoice.EXPECT().Render(context.Background(), docMock).Return([]byte("%PDF-1.4"), nil)
+			},
+			want: entity.Attachment{
+				Filename:    "INV-2026-000042.pdf",
+				ContentType: entity.ContentTypePDF,
+				Content:     []byte("%PDF-1.4"),
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := i.GetPDF(context.Background(), paramMock)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("invoice.GetPDF() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,39 +1,63 @@
 package midtranstransaction
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	cartDom "go-clean/src/business/domain/cart"
 	midtransDom "go-clean/src/business/domain/midtrans"
 	midtransTransactionDom "go-clean/src/business/domain/midtrans_transaction"
+	outboxDom "go-clean/src/business/domain/outbox"
+	refundDom "go-clean/src/business/domain/refund"
+	statusStreamDom "go-clean/src/business/domain/status_stream"
+	transactionDom "go-clean/src/business/domain/transaction"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/midtrans/midtrans-go/coreapi"
 )
 
 type Interface interface {
-	GetPaymentDetail(param entity.MidtransTransactionParam) (entity.MidtransTransactionPaymentDetail, error)
-	HandleNotification(payload map[string]interface{}) error
+	GetPaymentDetail(ctx context.Context, param entity.MidtransTransactionParam) (entity.MidtransTransactionPaymentDetail, error)
+	HandleNotification(ctx context.Context, payload map[string]interface{}) error
 }
 
 type midtransTransaction struct {
+	log                 log.Interface
+	metrics             metrics.Interface
 	midtrans            midtransDom.Interface
 	midtransTransaction midtransTransactionDom.Interface
 	cart                cartDom.Interface
+	outbox              outboxDom.Interface
+	transaction         transactionDom.Interface
+	statusStream        statusStreamDom.Interface
+	refund              refundDom.Interface
 }
 
-func Init(mttd midtransTransactionDom.Interface, md midtransDom.Interface, cd cartDom.Interface) Interface {
+func Init(log log.Interface, metrics metrics.Interface, mttd midtransTransactionDom.Interface, md midtransDom.Interface, cd cartDom.Interface, od outboxDom.Interface, td transactionDom.Interface, ssd statusStreamDom.Interface, rd refundDom.Interface) Interface {
 	mtt := &midtransTransaction{
+		log:                 log,
+		metrics:             metrics,
 		midtrans:            md,
 		midtransTransaction: mttd,
 		cart:                cd,
+		outbox:              od,
+		transaction:         td,
+		statusStream:        ssd,
+		refund:              rd,
 	}
 
 	return mtt
 }
 
-func (mtt *midtransTransaction) GetPaymentDetail(param entity.MidtransTransactionParam) (entity.MidtransTransactionPaymentDetail, error) {
+func (mtt *midtransTransaction) GetPaymentDetail(ctx context.Context, param entity.MidtransTransactionParam) (entity.MidtransTransactionPaymentDetail, error) {
 	result := entity.MidtransTransactionPaymentDetail{}
 
-	midtransTransaction, err := mtt.midtransTransaction.Get(param)
+	midtransTransaction, err := mtt.midtransTransaction.Get(ctx, param)
 	if err != nil {
 		return result, err
 	}
@@ -50,18 +74,18 @@ func (mtt *midtransTransaction) GetPaymentDetail(param entity.MidtransTransactio
 	return result, nil
 }
 
-func (mtt *midtransTransaction) HandleNotification(payload map[string]interface{}) error {
+func (mtt *midtransTransaction) HandleNotification(ctx context.Context, payload map[string]interface{}) error {
 	orderId, exist := payload["order_id"].(string)
 	if !exist {
-		return errors.New("order id not exist")
+		return apperror.Validation("order id does not exist").WithKey("payment.order_not_found")
 	}
 
-	transactionResponse, err := mtt.midtrans.HandleNotification(orderId)
+	transactionResponse, err := mtt.midtrans.HandleNotification(ctx, orderId)
 	if err != nil {
-		return err
+		return apperror.PaymentFailed("failed to check the payment status", err).WithKey("payment.check_failed")
 	}
 
-	midtransTransaction, err := mtt.midtransTransaction.Get(entity.MidtransTransactionParam{
+	midtransTransaction, err := mtt.midtransTransaction.Get(ctx, entity.MidtransTransactionParam{
 		OrderID: orderId,
 	})
 	if err != nil {
@@ -94,27 +118,210 @@ func (mtt *midtransTransaction) HandleNotification(payload map[string]interface{
 		} else if transactionResponse.TransactionStatus == "pending" {
 			// TODO set transaction status on your databaase to 'pending' / waiting payment
 			status = entity.StatusPending
+		} else if transactionResponse.TransactionStatus == "refund" {
+			status = entity.StatusRefunded
+		} else if transactionResponse.TransactionStatus == "partial_refund" {
+			status = entity.StatusPartialRefund
 		}
 	}
 
-	if err := mtt.midtransTransaction.Update(entity.MidtransTransactionParam{
-		ID: midtransTransaction.ID,
-	}, entity.UpdateMidtransTransactionParam{
-		Status: status,
-	}); err != nil {
+	// a status this service does not know must not wipe the one it has,
+	// midtrans notifies again on the next change
+	if status == "" {
+		if transactionResponse == nil {
+			mtt.log.Warn(ctx, "empty midtrans transaction status", "order_id", orderId)
+			return nil
+		}
+		mtt.log.Warn(ctx, "unhandled midtrans transaction status", "order_id", orderId, "status", transactionResponse.TransactionStatus, "fraud_status", transactionResponse.FraudStatus)
+		return nil
+	}
+
+	// cancelling an order makes midtrans notify a cancel, the order keeps
+	// the cancelled status it was given
+	if midtransTransaction.Status == entity.StatusCancelled && status == entity.StatusFailure {
+		status = entity.StatusCancelled
+	}
+
+	// midtrans repeats notifications, events are only added on a change
+	changed := status != midtransTransaction.Status
+
+	err = mtt.outbox.WithTx(ctx, func(ctx context.Context) error {
+		if err := mtt.midtransTransaction.Update(ctx, entity.MidtransTransactionParam{
+			ID: midtransTransaction.ID,
+		}, entity.UpdateMidtransTransactionParam{
+			Status: status,
+		}); err != nil {
+			return err
+		}
+
+		if status == entity.StatusSuccess {
+			if err := mtt.cart.Update(ctx, entity.CartParam{
+				Status:        entity.StatusUnpaid,
+				TransactionID: midtransTransaction.TransactionID,
+			}, entity.UpdateCartParam{
+				Status: entity.StatusPaid,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if status == entity.StatusRefunded {
+			if err := mtt.cart.Update(ctx, entity.CartParam{
+				Status:        entity.StatusPaid,
+				TransactionID: midtransTransaction.TransactionID,
+			}, entity.UpdateCartParam{
+				Status: entity.StatusRefunded,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if status == entity.StatusRefunded || status == entity.StatusPartialRefund {
+			if err := mtt.syncRefunds(ctx, midtransTransaction, status, transactionResponse.Refunds); err != nil {
+				return err
+			}
+		}
+
+		if !changed {
+			return nil
+		}
+
+		return mtt.addPaymentEvent(ctx, midtransTransaction, status)
+	})
+	if err != nil {
 		return err
 	}
 
+	if !changed {
+		return nil
+	}
+
 	if status == entity.StatusSuccess {
-		if err := mtt.cart.Update(entity.CartParam{
-			Status:        entity.StatusUnpaid,
+		mtt.metrics.IncPaymentsSettled()
+	}
+
+	midtransTransaction.Status = status
+	mtt.publishStatus(ctx, midtransTransaction)
+
+	return nil
+}
+
+// syncRefunds records the refunds midtrans reports, settling the ones issued
+// through the api and adding the ones made in the midtrans dashboard.
+func (mtt *midtransTransaction) syncRefunds(ctx context.Context, midtransTransaction entity.MidtransTransaction, status string, details []coreapi.RefundDetails) error {
+	refunds, err := mtt.refund.GetList(ctx, entity.RefundParam{
+		TransactionID: midtransTransaction.TransactionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	refundMap := map[string]entity.Refund{}
+	for _, r := range refunds {
+		refundMap[r.RefundKey] = r
+	}
+
+	for _, d := range details {
+		if r, ok := refundMap[d.RefundKey]; ok {
+			if r.Status == entity.RefundStatusSuccess {
+				continue
+			}
+
+			if err := mtt.refund.Update(ctx, entity.RefundParam{
+				ID: r.ID,
+			}, entity.UpdateRefundParam{
+				Status: entity.RefundStatusSuccess,
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(d.RefundAmount, 64)
+		if err != nil {
+			return err
+		}
+
+		r, err := mtt.refund.Create(ctx, entity.Refund{
 			TransactionID: midtransTransaction.TransactionID,
-		}, entity.UpdateCartParam{
-			Status: entity.StatusPaid,
-		}); err != nil {
+			OrderID:       midtransTransaction.OrderID,
+			RefundKey:     d.RefundKey,
+			Amount:        int64(math.Round(amount)),
+			Reason:        d.Reason,
+			Status:        entity.RefundStatusSuccess,
+		})
+		if err != nil {
+			return err
+		}
+
+		event, err := entity.NewOutboxEvent(entity.EventPaymentRefunded, midtransTransaction.TransactionID, entity.PaymentRefundedPayload{
+			TransactionID: midtransTransaction.TransactionID,
+			OrderID:       midtransTransaction.OrderID,
+			RefundKey:     r.RefundKey,
+			Amount:        r.Amount,
+			Reason:        r.Reason,
+			Status:        status,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := mtt.outbox.Add(ctx, event); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// publishStatus pushes the committed status to the owner of the transaction.
+// A failure is only logged, clients can still fetch the payment detail.
+func (mtt *midtransTransaction) publishStatus(ctx context.Context, midtransTransaction entity.MidtransTransaction) {
+	transaction, err := mtt.transaction.Get(ctx, entity.TransactionParam{
+		ID: midtransTransaction.TransactionID,
+	})
+	if err != nil {
+		mtt.log.Error(ctx, "failed to get transaction of status update", "transaction_id", midtransTransaction.TransactionID, "error", err)
+		return
+	}
+
+	if err := mtt.statusStream.Publish(ctx, entity.TransactionStatusUpdate{
+		TransactionID: transaction.ID,
+		UserID:        transaction.UserID,
+		OrderID:       midtransTransaction.OrderID,
+		Status:        midtransTransaction.Status,
+		UpdatedAt:     time.Now(),
+	}); err != nil {
+		mtt.log.Error(ctx, "failed to publish transaction status", "transaction_id", transaction.ID, "error", err)
+	}
+}
+
+func (mtt *midtransTransaction) addPaymentEvent(ctx context.Context, midtransTransaction entity.MidtransTransaction, status string) error {
+	var (
+		event entity.OutboxEvent
+		err   error
+	)
+
+	switch status {
+	case entity.StatusSuccess:
+		event, err = entity.NewOutboxEvent(entity.EventPaymentSettled, midtransTransaction.TransactionID, entity.PaymentSettledPayload{
+			TransactionID: midtransTransaction.TransactionID,
+			OrderID:       midtransTransaction.OrderID,
+			PaymentType:   midtransTransaction.PaymentType,
+		})
+	case entity.StatusFailure:
+		event, err = entity.NewOutboxEvent(entity.EventPaymentFailed, midtransTransaction.TransactionID, entity.PaymentFailedPayload{
+			TransactionID: midtransTransaction.TransactionID,
+			OrderID:       midtransTransaction.OrderID,
+			PaymentType:   midtransTransaction.PaymentType,
+			Status:        status,
+		})
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return mtt.outbox.Add(ctx, event)
+}
@@ -1,11 +1,18 @@
 package midtranstransaction_test
 
 import (
+	"context"
 	"encoding/json"
 	mock_cart "go-clean/src/business/domain/mock/cart"
 	mock_midtrans "go-clean/src/business/domain/mock/midtrans"
 	mock_midtranstransaction "go-clean/src/business/domain/mock/midtrans_transaction"
+	mock_outbox "go-clean/src/business/domain/mock/outbox"
+	mock_refund "go-clean/src/business/domain/mock/refund"
+	mock_statusstream "go-clean/src/business/domain/mock/status_stream"
+	mock_transaction "go-clean/src/business/domain/mock/transaction"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"testing"
 
 	midtranstransaction "go-clean/src/business/usecase/midtrans_transaction"
@@ -45,7 +52,7 @@ func Test_midtransTransaction_GetPaymentDetail(t *testing.T) {
 		MidtransID:  "1",
 	}
 
-	mt := midtranstransaction.Init(midtransTransactionMock, nil, nil)
+	mt := midtranstransaction.Init(log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), midtransTransactionMock, nil, nil, nil, nil, nil, nil)
 
 	type mockFields struct {
 		midtrans_transaction *mock_midtranstransaction.MockInterface
@@ -72,7 +79,7 @@ func Test_midtransTransaction_GetPaymentDetail(t *testing.T) {
 				param: midtransTransactionParamMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(entity.MidtransTransaction{}, assert.AnError)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(entity.MidtransTransaction{}, assert.AnError)
 			},
 			want:    entity.MidtransTransactionPaymentDetail{},
 			wantErr: true,
@@ -83,7 +90,7 @@ func Test_midtransTransaction_GetPaymentDetail(t *testing.T) {
 				param: midtransTransactionParamMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
 			},
 			want:    resultMock,
 			wantErr: false,
@@ -92,7 +99,7 @@ func Test_midtransTransaction_GetPaymentDetail(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFunc(mocks, tt.args)
-			got, err := mt.GetPaymentDetail(tt.args.param)
+			got, err := mt.GetPaymentDetail(context.Background(), tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("midtransTransaction.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -140,6 +147,20 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 		TransactionStatus: "pending",
 	}
 
+	transactionResponsePartialRefundMock := &coreapi.TransactionStatusResponse{
+		TransactionStatus: "partial_refund",
+		Refunds: []coreapi.RefundDetails{
+			{RefundKey: "dashboard-1", RefundAmount: "5000.00", Reason: "damaged"},
+		},
+	}
+
+	transactionResponseRefundMock := &coreapi.TransactionStatusResponse{
+		TransactionStatus: "refund",
+		Refunds: []coreapi.RefundDetails{
+			{RefundKey: "SYN-1-R1", RefundAmount: "10000.00"},
+		},
+	}
+
 	midtransTransactionParamMock := entity.MidtransTransactionParam{
 		OrderID: "1",
 	}
@@ -184,18 +205,57 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 		Status: entity.StatusPaid,
 	}
 
-	mt := midtranstransaction.Init(midtransTransactionMock, midtransMock, cartMock)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+	transactionMock := mock_transaction.NewMockInterface(ctrl)
+	statusStreamMock := mock_statusstream.NewMockInterface(ctrl)
+	refundMock := mock_refund.NewMockInterface(ctrl)
+
+	mt := midtranstransaction.Init(log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), midtransTransactionMock, midtransMock, cartMock, outboxMock, transactionMock, statusStreamMock, refundMock)
 
 	type mockFields struct {
 		midtrans             *mock_midtrans.MockInterface
 		midtrans_transaction *mock_midtranstransaction.MockInterface
 		cart                 *mock_cart.MockInterface
+		outbox               *mock_outbox.MockInterface
+		transaction          *mock_transaction.MockInterface
+		status_stream        *mock_statusstream.MockInterface
+		refund               *mock_refund.MockInterface
 	}
 
 	mocks := mockFields{
 		midtrans:             midtransMock,
 		midtrans_transaction: midtransTransactionMock,
 		cart:                 cartMock,
+		outbox:               outboxMock,
+		transaction:          transactionMock,
+		status_stream:        statusStreamMock,
+		refund:               refundMock,
+	}
+
+	transactionResultMock := entity.Transaction{
+		Model: gorm.Model{
+			ID: 1,
+		},
+		UserID: 1,
+	}
+
+	statusOf := func(status string) func(ctx context.Context, update entity.TransactionStatusUpdate) error {
+		return func(ctx context.Context, update entity.TransactionStatusUpdate) error {
+			assert.Equal(t, uint(1), update.UserID)
+			assert.Equal(t, status, update.Status)
+			return nil
+		}
+	}
+
+	withTx := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}
+
+	eventOfType := func(eventType string) func(ctx context.Context, events ...entity.OutboxEvent) error {
+		return func(ctx context.Context, events ...entity.OutboxEvent) error {
+			assert.Equal(t, eventType, events[0].Type)
+			return nil
+		}
 	}
 
 	type args struct {
@@ -214,6 +274,8 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: map[string]interface{}{},
 			},
 			mockFunc: func(mock mockFields, arg args) {
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.status_stream.EXPECT().Publish(context.Background(), gomock.Any()).DoAndReturn(statusOf(entity.StatusSuccess))
 			},
 			wantErr: true,
 		},
@@ -223,7 +285,7 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(nil, assert.AnError)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(nil, assert.AnError)
 			},
 			wantErr: true,
 		},
@@ -233,8 +295,8 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponseMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(entity.MidtransTransaction{}, assert.AnError)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(entity.MidtransTransaction{}, assert.AnError)
 			},
 			wantErr: true,
 		},
@@ -244,9 +306,10 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponseMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
-				mock.midtrans_transaction.EXPECT().Update(midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(assert.AnError)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(assert.AnError)
 			},
 			wantErr: true,
 		},
@@ -256,10 +319,11 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponseMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
-				mock.midtrans_transaction.EXPECT().Update(midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
-				mock.cart.EXPECT().Update(cartUpdateParamMock, cartUpdateMock).Return(assert.AnError)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), cartUpdateParamMock, cartUpdateMock).Return(assert.AnError)
 			},
 			wantErr: true,
 		},
@@ -269,10 +333,77 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponseMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
-				mock.midtrans_transaction.EXPECT().Update(midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
-				mock.cart.EXPECT().Update(cartUpdateParamMock, cartUpdateMock).Return(nil)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), cartUpdateParamMock, cartUpdateMock).Return(nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(eventOfType(entity.EventPaymentSettled))
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed to add payment event",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), cartUpdateParamMock, cartUpdateMock).Return(nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to publish status",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), cartUpdateParamMock, cartUpdateMock).Return(nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(eventOfType(entity.EventPaymentSettled))
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.status_stream.EXPECT().Publish(context.Background(), gomock.Any()).Return(assert.AnError)
+			},
+			wantErr: false,
+		},
+		{
+			name: "cancel notification of a cancelled order adds no event",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				cancelled := midtransTransactionResultMock
+				cancelled.Status = entity.StatusCancelled
+
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseCancelMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(cancelled, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, entity.UpdateMidtransTransactionParam{Status: entity.StatusCancelled}).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "repeated notification adds no event",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				settled := midtransTransactionResultMock
+				settled.Status = entity.StatusSuccess
+
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(settled, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), cartUpdateParamMock, cartUpdateMock).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -282,10 +413,14 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponseSettlementMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
-				mock.midtrans_transaction.EXPECT().Update(midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
-				mock.cart.EXPECT().Update(cartUpdateParamMock, cartUpdateMock).Return(nil)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseSettlementMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateMock).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), cartUpdateParamMock, cartUpdateMock).Return(nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(eventOfType(entity.EventPaymentSettled))
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.status_stream.EXPECT().Publish(context.Background(), gomock.Any()).DoAndReturn(statusOf(entity.StatusSuccess))
 			},
 			wantErr: false,
 		},
@@ -295,9 +430,12 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponseChallengeMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
-				mock.midtrans_transaction.EXPECT().Update(midtransTransactionUpdateParamMock, midtransTransactionUpdateChallangeMock).Return(nil)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseChallengeMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateChallangeMock).Return(nil)
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.status_stream.EXPECT().Publish(context.Background(), gomock.Any()).DoAndReturn(statusOf(entity.StatusChallange))
 			},
 			wantErr: false,
 		},
@@ -307,9 +445,12 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponseDenyMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
-				mock.midtrans_transaction.EXPECT().Update(midtransTransactionUpdateParamMock, midtransTransactionUpdateDenyMock).Return(nil)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseDenyMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateDenyMock).Return(nil)
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.status_stream.EXPECT().Publish(context.Background(), gomock.Any()).DoAndReturn(statusOf(entity.StatusDeny))
 			},
 			wantErr: false,
 		},
@@ -319,9 +460,13 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponseCancelMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
-				mock.midtrans_transaction.EXPECT().Update(midtransTransactionUpdateParamMock, midtransTransactionUpdateFailureMock).Return(nil)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseCancelMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdateFailureMock).Return(nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(eventOfType(entity.EventPaymentFailed))
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.status_stream.EXPECT().Publish(context.Background(), gomock.Any()).DoAndReturn(statusOf(entity.StatusFailure))
 			},
 			wantErr: false,
 		},
@@ -331,9 +476,101 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 				payload: payloadMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.midtrans.EXPECT().HandleNotification("1").Return(transactionResponsePendingMock, nil)
-				mock.midtrans_transaction.EXPECT().Get(midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
-				mock.midtrans_transaction.EXPECT().Update(midtransTransactionUpdateParamMock, midtransTransactionUpdatePendingMock).Return(nil)
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponsePendingMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, midtransTransactionUpdatePendingMock).Return(nil)
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.status_stream.EXPECT().Publish(context.Background(), gomock.Any()).DoAndReturn(statusOf(entity.StatusPending))
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown status keeps the current one",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(&coreapi.TransactionStatusResponse{TransactionStatus: "authorize"}, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty status response keeps the current one",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(nil, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(midtransTransactionResultMock, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed to get refunds",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				settled := midtransTransactionResultMock
+				settled.Status = entity.StatusSuccess
+
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponsePartialRefundMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(settled, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, entity.UpdateMidtransTransactionParam{Status: entity.StatusPartialRefund}).Return(nil)
+				mock.refund.EXPECT().GetList(context.Background(), entity.RefundParam{TransactionID: 1}).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all success partial refund from the dashboard",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				settled := midtransTransactionResultMock
+				settled.Status = entity.StatusSuccess
+
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponsePartialRefundMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(settled, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, entity.UpdateMidtransTransactionParam{Status: entity.StatusPartialRefund}).Return(nil)
+				mock.refund.EXPECT().GetList(context.Background(), entity.RefundParam{TransactionID: 1}).Return([]entity.Refund{}, nil)
+				mock.refund.EXPECT().Create(context.Background(), entity.Refund{
+					TransactionID: 1,
+					RefundKey:     "dashboard-1",
+					Amount:        5000,
+					Reason:        "damaged",
+					Status:        entity.RefundStatusSuccess,
+				}).DoAndReturn(func(ctx context.Context, refund entity.Refund) (entity.Refund, error) {
+					return refund, nil
+				})
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(eventOfType(entity.EventPaymentRefunded))
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.status_stream.EXPECT().Publish(context.Background(), gomock.Any()).DoAndReturn(statusOf(entity.StatusPartialRefund))
+			},
+			wantErr: false,
+		},
+		{
+			name: "refund issued through the api is settled",
+			args: args{
+				payload: payloadMock,
+			},
+			mockFunc: func(mock mockFields, arg args) {
+				refunded := midtransTransactionResultMock
+				refunded.Status = entity.StatusRefunded
+
+				mock.midtrans.EXPECT().HandleNotification(context.Background(), "1").Return(transactionResponseRefundMock, nil)
+				mock.midtrans_transaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(refunded, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtrans_transaction.EXPECT().Update(context.Background(), midtransTransactionUpdateParamMock, entity.UpdateMidtransTransactionParam{Status: entity.StatusRefunded}).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), entity.CartParam{Status: entity.StatusPaid, TransactionID: 1}, entity.UpdateCartParam{Status: entity.StatusRefunded}).Return(nil)
+				mock.refund.EXPECT().GetList(context.Background(), entity.RefundParam{TransactionID: 1}).Return([]entity.Refund{
+					{Model: gorm.Model{ID: 3}, RefundKey: "SYN-1-R1", Status: entity.RefundStatusPending},
+				}, nil)
+				mock.refund.EXPECT().Update(context.Background(), entity.RefundParam{ID: 3}, entity.UpdateRefundParam{Status: entity.RefundStatusSuccess}).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -341,7 +578,7 @@ func Test_midtransTransaction_HandleNotification(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFunc(mocks, tt.args)
-			err := mt.HandleNotification(tt.args.payload)
+			err := mt.HandleNotification(context.Background(), tt.args.payload)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("midtransTransaction.HandleNotification() error = %v, wantErr %v", err, tt.wantErr)
 				return
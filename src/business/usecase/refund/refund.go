@@ -0,0 +1,292 @@
+package refund
+
+import (
+	"context"
+	"fmt"
+	cartDom "go-clean/src/business/domain/cart"
+	midtransDom "go-clean/src/business/domain/midtrans"
+	midtransTransactionDom "go-clean/src/business/domain/midtrans_transaction"
+	outboxDom "go-clean/src/business/domain/outbox"
+	refundDom "go-clean/src/business/domain/refund"
+	statusStreamDom "go-clean/src/business/domain/status_stream"
+	transactionDom "go-clean/src/business/domain/transaction"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	"time"
+
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+type Interface interface {
+	// Create refunds the items of createParam, or the whole remaining amount
+	// of the order when it has no items, through midtrans.
+	Create(ctx context.Context, param entity.RefundParam, createParam entity.CreateRefundParam) (entity.Refund, error)
+	GetList(ctx context.Context, param entity.RefundParam) ([]entity.Refund, error)
+}
+
+type refund struct {
+	log                 log.Interface
+	auth                auth.Interface
+	refund              refundDom.Interface
+	midtrans            midtransDom.Interface
+	midtransTransaction midtransTransactionDom.Interface
+	transaction         transactionDom.Interface
+	cart                cartDom.Interface
+	outbox              outboxDom.Interface
+	statusStream        statusStreamDom.Interface
+}
+
+func Init(log log.Interface, auth auth.Interface, rd refundDom.Interface, md midtransDom.Interface, mttd midtransTransactionDom.Interface, td transactionDom.Interface, cd cartDom.Interface, od outboxDom.Interface, ssd statusStreamDom.Interface) Interface {
+	r := &refund{
+		log:                 log,
+		auth:                auth,
+		refund:              rd,
+		midtrans:            md,
+		midtransTransaction: mttd,
+		transaction:         td,
+		cart:                cd,
+		outbox:              od,
+		statusStream:        ssd,
+	}
+
+	return r
+}
+
+func (r *refund) Create(ctx context.Context, param entity.RefundParam, createParam entity.CreateRefundParam) (entity.Refund, error) {
+	result := entity.Refund{}
+
+	user, err := r.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	midtransTransaction, err := r.midtransTransaction.Get(ctx, entity.MidtransTransactionParam{
+		TransactionID: param.TransactionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if midtransTransaction.Status != entity.StatusSuccess && midtransTransaction.Status != entity.StatusPartialRefund {
+		return result, apperror.Conflict("only paid orders can be refunded").WithKey("refund.not_refundable")
+	}
+
+	transaction, err := r.transaction.Get(ctx, entity.TransactionParam{
+		ID: param.TransactionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	carts, err := r.cart.GetList(ctx, entity.CartParam{
+		TransactionID: param.TransactionID,
+		Status:        entity.StatusPaid,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	refunds, err := r.refund.GetList(ctx, entity.RefundParam{
+		TransactionID: param.TransactionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// pending refunds may have reached midtrans, they are counted as refunded
+	refundedAmount := int64(0)
+	refundedQty := map[uint]int{}
+	for _, rf := range refunds {
+		if rf.Status == entity.RefundStatusFailed {
+			continue
+		}
+
+		refundedAmount += rf.Amount
+		for _, item := range rf.Items {
+			refundedQty[item.CartID] += item.Qty
+		}
+	}
+
+	remaining := transaction.TotalPrice - refundedAmount
+
+	items, amount, err := refundItems(carts, refundedQty, createParam.Items)
+	if err != nil {
+		return result, err
+	}
+
+	if len(createParam.Items) == 0 || amount > remaining {
+		amount = remaining
+	}
+
+	if amount <= 0 {
+		return result, apperror.Conflict("the order has nothing left to refund").WithKey("refund.nothing_left")
+	}
+
+	result, err = r.refund.Create(ctx, entity.Refund{
+		TransactionID: param.TransactionID,
+		OrderID:       midtransTransaction.OrderID,
+		RefundKey:     fmt.Sprintf("%s-R%d", midtransTransaction.OrderID, len(refunds)+1),
+		Amount:        amount,
+		Reason:        createParam.Reason,
+		Items:         items,
+		Status:        entity.RefundStatusPending,
+		UserID:        user.User.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	_, err = r.midtrans.Refund(ctx, midtransTransaction.OrderID, &coreapi.RefundReq{
+		RefundKey: result.RefundKey,
+		Amount:    result.Amount,
+		Reason:    result.Reason,
+	})
+	if err != nil {
+		if updateErr := r.refund.Update(ctx, entity.RefundParam{
+			ID: result.ID,
+		}, entity.UpdateRefundParam{
+			Status:    entity.RefundStatusFailed,
+			LastError: err.Error(),
+		}); updateErr != nil {
+			r.log.Error(ctx, "failed to mark refund failed", "refund_id", result.ID, "error", updateErr)
+		}
+
+		return result, apperror.PaymentFailed("failed to refund the payment", err).WithKey("payment.refund_failed")
+	}
+
+	status := entity.StatusPartialRefund
+	if refundedAmount+amount >= transaction.TotalPrice {
+		status = entity.StatusRefunded
+	}
+
+	for _, item := range items {
+		refundedQty[item.CartID] += item.Qty
+	}
+
+	// the money is returned at this point, a failure below leaves the refund
+	// pending until the refund notification of midtrans settles it
+	err = r.outbox.WithTx(ctx, func(ctx context.Context) error {
+		if err := r.refund.Update(ctx, entity.RefundParam{
+			ID: result.ID,
+		}, entity.UpdateRefundParam{
+			Status: entity.RefundStatusSuccess,
+		}); err != nil {
+			return err
+		}
+
+		if err := r.midtransTransaction.Update(ctx, entity.MidtransTransactionParam{
+			ID: midtransTransaction.ID,
+		}, entity.UpdateMidtransTransactionParam{
+			Status: status,
+		}); err != nil {
+			return err
+		}
+
+		for _, c := range carts {
+			if status != entity.StatusRefunded && refundedQty[c.ID] < c.Qty {
+				continue
+			}
+
+			if err := r.cart.Update(ctx, entity.CartParam{
+				ID: c.ID,
+			}, entity.UpdateCartParam{
+				Status: entity.StatusRefunded,
+			}); err != nil {
+				return err
+			}
+		}
+
+		event, err := entity.NewOutboxEvent(entity.EventPaymentRefunded, param.TransactionID, entity.PaymentRefundedPayload{
+			TransactionID: param.TransactionID,
+			OrderID:       midtransTransaction.OrderID,
+			RefundKey:     result.RefundKey,
+			Amount:        result.Amount,
+			Reason:        result.Reason,
+			Status:        status,
+		})
+		if err != nil {
+			return err
+		}
+
+		return r.outbox.Add(ctx, event)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Status = entity.RefundStatusSuccess
+
+	r.log.Info(ctx, "order refunded", "audit", true, "user_id", user.User.ID, "transaction_id", param.TransactionID, "refund_id", result.ID, "refund_key", result.RefundKey, "amount", result.Amount, "status", status)
+
+	if status != midtransTransaction.Status {
+		if err := r.statusStream.Publish(ctx, entity.TransactionStatusUpdate{
+			TransactionID: transaction.ID,
+			UserID:        transaction.UserID,
+			OrderID:       midtransTransaction.OrderID,
+			Status:        status,
+			UpdatedAt:     time.Now(),
+		}); err != nil {
+			r.log.Error(ctx, "failed to publish transaction status", "transaction_id", transaction.ID, "error", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *refund) GetList(ctx context.Context, param entity.RefundParam) ([]entity.Refund, error) {
+	return r.refund.GetList(ctx, entity.RefundParam{
+		TransactionID: param.TransactionID,
+	})
+}
+
+// refundItems checks the requested items against the paid carts of the order
+// and prices them at the price the customer paid. Without requested items
+// every quantity that is not refunded yet is refunded.
+func refundItems(carts []entity.Cart, refundedQty map[uint]int, requested []entity.RefundItem) ([]entity.RefundItem, int64, error) {
+	items := []entity.RefundItem{}
+	amount := int64(0)
+
+	if len(requested) == 0 {
+		for _, c := range carts {
+			if qty := c.Qty - refundedQty[c.ID]; qty > 0 {
+				items = append(items, entity.RefundItem{CartID: c.ID, Qty: qty})
+				amount += int64(c.FinalPricePerItem) * int64(qty)
+			}
+		}
+
+		return items, amount, nil
+	}
+
+	cartMap := map[uint]entity.Cart{}
+	for _, c := range carts {
+		cartMap[c.ID] = c
+	}
+
+	// the same cart may be listed more than once
+	qtyMap := map[uint]int{}
+	for _, item := range requested {
+		if _, ok := qtyMap[item.CartID]; !ok {
+			items = append(items, entity.RefundItem{CartID: item.CartID})
+		}
+		qtyMap[item.CartID] += item.Qty
+	}
+
+	for i, item := range items {
+		c, ok := cartMap[item.CartID]
+		if !ok {
+			return nil, 0, apperror.Validation(fmt.Sprintf("cart %d is not a paid item of the order", item.CartID)).WithKey("refund.item_not_found", item.CartID)
+		}
+
+		qty := qtyMap[item.CartID]
+		if qty > c.Qty-refundedQty[c.ID] {
+			return nil, 0, apperror.Validation(fmt.Sprintf("cart %d has %d item(s) left to refund", c.ID, c.Qty-refundedQty[c.ID])).WithKey("refund.qty_exceeded", c.ID, c.Qty-refundedQty[c.ID])
+		}
+
+		items[i].Qty = qty
+		amount += int64(c.FinalPricePerItem) * int64(qty)
+	}
+
+	return items, amount, nil
+}
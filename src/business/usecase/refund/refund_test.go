@@ -0,0 +1,353 @@
+package refund_test
+
+import (
+	"context"
+	mock_cart "go-clean/src/business/domain/mock/cart"
+	mock_midtrans "go-clean/src/business/domain/mock/midtrans"
+	mock_midtrans_transaction "go-clean/src/business/domain/mock/midtrans_transaction"
+	mock_outbox "go-clean/src/business/domain/mock/outbox"
+	mock_refund "go-clean/src/business/domain/mock/refund"
+	mock_statusstream "go-clean/src/business/domain/mock/status_stream"
+	mock_transaction "go-clean/src/business/domain/mock/transaction"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/refund"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	mock_auth "go-clean/src/lib/tests/mock/auth"
+	"testing"
+
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func Test_refund_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	refundMock := mock_refund.NewMockInterface(ctrl)
+	midtransMock := mock_midtrans.NewMockInterface(ctrl)
+	midtransTransactionMock := mock_midtrans_transaction.NewMockInterface(ctrl)
+	transactionMock := mock_transaction.NewMockInterface(ctrl)
+	cartMock := mock_cart.NewMockInterface(ctrl)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+	statusStreamMock := mock_statusstream.NewMockInterface(ctrl)
+
+	r := refund.Init(log.Init(log.Config{Level: "disabled"}), authMock, refundMock, midtransMock, midtransTransactionMock, transactionMock, cartMock, outboxMock, statusStreamMock)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 9,
+		},
+	}
+
+	paramMock := entity.RefundParam{
+		TransactionID: 1,
+	}
+
+	settledMock := entity.MidtransTransaction{
+		Model: gorm.Model{
+			ID: 2,
+		},
+		TransactionID: 1,
+		OrderID:       "SYN-1",
+		Status:        entity.StatusSuccess,
+	}
+
+	pendingMock := settledMock
+	pendingMock.Status = entity.StatusPending
+
+	transactionMockResult := entity.Transaction{
+		Model: gorm.Model{
+			ID: 1,
+		},
+		UserID:     1,
+		TotalPrice: 5000,
+	}
+
+	// 2 x 1000 and 3 x 1000
+	cartsMock := []entity.Cart{
+		{
+			Model: gorm.Model{
+				ID: 1,
+			},
+			Qty:               2,
+			FinalPricePerItem: 1000,
+		},
+		{
+			Model: gorm.Model{
+				ID: 2,
+			},
+			Qty:               3,
+			FinalPricePerItem: 1000,
+		},
+	}
+
+	// one item of cart 2 is refunded already
+	refundsMock := []entity.Refund{
+		{
+			Amount: 1000,
+			Items:  []entity.RefundItem{{CartID: 2, Qty: 1}},
+			Status: entity.RefundStatusSuccess,
+		},
+		{
+			Amount: 4000,
+			Status: entity.RefundStatusFailed,
+		},
+	}
+
+	withTx := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}
+
+	created := func(ctx context.Context, refund entity.Refund) (entity.Refund, error) {
+		refund.ID = 3
+		return refund, nil
+	}
+
+	type mockfields struct {
+		auth                *mock_auth.MockInterface
+		refund              *mock_refund.MockInterface
+		midtrans            *mock_midtrans.MockInterface
+		midtransTransaction *mock_midtrans_transaction.MockInterface
+		transaction         *mock_transaction.MockInterface
+		cart                *mock_cart.MockInterface
+		outbox              *mock_outbox.MockInterface
+		statusStream        *mock_statusstream.MockInterface
+	}
+
+	loaded := func(mock mockfields) {
+		mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+		mock.midtransTransaction.EXPECT().Get(context.Background(), entity.MidtransTransactionParam{TransactionID: 1}).Return(settledMock, nil)
+		mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionMockResult, nil)
+		mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{TransactionID: 1, Status: entity.StatusPaid}).Return(cartsMock, nil)
+		mock.refund.EXPECT().GetList(context.Background(), paramMock).Return(refundsMock, nil)
+	}
+
+	tests := []struct {
+		name        string
+		createParam entity.CreateRefundParam
+		mockFunc    func(mock mockfields)
+		want        entity.Refund
+		wantErr     bool
+	}{
+		{
+			name:        "failed to get auth user",
+			createParam: entity.CreateRefundParam{Reason: "damaged"},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:        "order is not paid",
+			createParam: entity.CreateRefundParam{Reason: "damaged"},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), entity.MidtransTransactionParam{TransactionID: 1}).Return(pendingMock, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "item is not part of the order",
+			createParam: entity.CreateRefundParam{
+				Items:  []entity.RefundItem{{CartID: 7, Qty: 1}},
+				Reason: "damaged",
+			},
+			mockFunc: loaded,
+			wantErr:  true,
+		},
+		{
+			name: "qty exceeds what is left of the cart",
+			createParam: entity.CreateRefundParam{
+				Items:  []entity.RefundItem{{CartID: 2, Qty: 1}, {CartID: 2, Qty: 2}},
+				Reason: "damaged",
+			},
+			mockFunc: loaded,
+			wantErr:  true,
+		},
+		{
+			name:        "failed to refund the payment",
+			createParam: entity.CreateRefundParam{Reason: "damaged"},
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.refund.EXPECT().Create(context.Background(), gomock.Any()).DoAndReturn(created)
+				mock.midtrans.EXPECT().Refund(context.Background(), "SYN-1", gomock.Any()).Return(nil, assert.AnError)
+				mock.refund.EXPECT().Update(context.Background(), entity.RefundParam{ID: 3}, entity.UpdateRefundParam{
+					Status:    entity.RefundStatusFailed,
+					LastError: assert.AnError.Error(),
+				}).Return(nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok partial refund",
+			createParam: entity.CreateRefundParam{
+				Items:  []entity.RefundItem{{CartID: 2, Qty: 2}},
+				Reason: "damaged",
+			},
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.refund.EXPECT().Create(context.Background(), entity.Refund{
+					TransactionID: 1,
+					OrderID:       "SYN-1",
+					RefundKey:     "SYN-1-R3",
+					Amount:        2000,
+					Reason:        "damaged",
+					Items:         []entity.RefundItem{{CartID: 2, Qty: 2}},
+					Status:        entity.RefundStatusPending,
+					UserID:        9,
+				}).DoAndReturn(created)
+				mock.midtrans.EXPECT().Refund(context.Background(), "SYN-1", &coreapi.RefundReq{
+					RefundKey: "SYN-1-R3",
+					Amount:    2000,
+					Reason:    "damaged",
+				}).Return(&coreapi.RefundResponse{}, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.refund.EXPECT().Update(context.Background(), entity.RefundParam{ID: 3}, entity.UpdateRefundParam{Status: entity.RefundStatusSuccess}).Return(nil)
+				mock.midtransTransaction.EXPECT().Update(context.Background(), entity.MidtransTransactionParam{ID: 2}, entity.UpdateMidtransTransactionParam{Status: entity.StatusPartialRefund}).Return(nil)
+				// cart 2 is refunded in full, cart 1 is untouched
+				mock.cart.EXPECT().Update(context.Background(), entity.CartParam{ID: 2}, entity.UpdateCartParam{Status: entity.StatusRefunded}).Return(nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(func(ctx context.Context, events ...entity.OutboxEvent) error {
+					assert.Equal(t, entity.EventPaymentRefunded, events[0].Type)
+					return nil
+				})
+				mock.statusStream.EXPECT().Publish(context.Background(), gomock.Any()).DoAndReturn(func(ctx context.Context, update entity.TransactionStatusUpdate) error {
+					assert.Equal(t, uint(1), update.UserID)
+					assert.Equal(t, entity.StatusPartialRefund, update.Status)
+					return nil
+				})
+			},
+			want: entity.Refund{
+				Model: gorm.Model{
+					ID: 3,
+				},
+				TransactionID: 1,
+				OrderID:       "SYN-1",
+				RefundKey:     "SYN-1-R3",
+				Amount:        2000,
+				Reason:        "damaged",
+				Items:         []entity.RefundItem{{CartID: 2, Qty: 2}},
+				Status:        entity.RefundStatusSuccess,
+				UserID:        9,
+			},
+			wantErr: false,
+		},
+		{
+			name:        "all ok full refund of the remaining amount",
+			createParam: entity.CreateRefundParam{Reason: "damaged"},
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.refund.EXPECT().Create(context.Background(), entity.Refund{
+					TransactionID: 1,
+					OrderID:       "SYN-1",
+					RefundKey:     "SYN-1-R3",
+					Amount:        4000,
+					Reason:        "damaged",
+					Items:         []entity.RefundItem{{CartID: 1, Qty: 2}, {CartID: 2, Qty: 2}},
+					Status:        entity.RefundStatusPending,
+					UserID:        9,
+				}).DoAndReturn(created)
+				mock.midtrans.EXPECT().Refund(context.Background(), "SYN-1", gomock.Any()).Return(&coreapi.RefundResponse{}, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.refund.EXPECT().Update(context.Background(), entity.RefundParam{ID: 3}, entity.UpdateRefundParam{Status: entity.RefundStatusSuccess}).Return(nil)
+				mock.midtransTransaction.EXPECT().Update(context.Background(), entity.MidtransTransactionParam{ID: 2}, entity.UpdateMidtransTransactionParam{Status: entity.StatusRefunded}).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), entity.CartParam{ID: 1}, entity.UpdateCartParam{Status: entity.StatusRefunded}).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), entity.CartParam{ID: 2}, entity.UpdateCartParam{Status: entity.StatusRefunded}).Return(nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(nil)
+				mock.statusStream.EXPECT().Publish(context.Background(), gomock.Any()).Return(assert.AnError)
+			},
+			want: entity.Refund{
+				Model: gorm.Model{
+					ID: 3,
+				},
+				TransactionID: 1,
+				OrderID:       "SYN-1",
+				RefundKey:     "SYN-1-R3",
+				Amount:        4000,
+				Reason:        "damaged",
+				Items:         []entity.RefundItem{{CartID: 1, Qty: 2}, {CartID: 2, Qty: 2}},
+				Status:        entity.RefundStatusSuccess,
+				UserID:        9,
+			},
+			wantErr: false,
+		},
+	}
+
+	mocks := mockfields{
+		auth:                authMock,
+		refund:              refundMock,
+		midtrans:            midtransMock,
+		midtransTransaction: midtransTransactionMock,
+		transaction:         transactionMock,
+		cart:                cartMock,
+		outbox:              outboxMock,
+		statusStream:        statusStreamMock,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := r.Create(context.Background(), paramMock, tt.createParam)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("refund.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_refund_GetList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	refundMock := mock_refund.NewMockInterface(ctrl)
+
+	r := refund.Init(log.Init(log.Config{Level: "disabled"}), nil, refundMock, nil, nil, nil, nil, nil, nil)
+
+	refundsMock := []entity.Refund{
+		{
+			TransactionID: 1,
+			Amount:        1000,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func()
+		want     []entity.Refund
+		wantErr  bool
+	}{
+		{
+			name: "failed to get refunds",
+			mockFunc: func() {
+				refundMock.EXPECT().GetList(context.Background(), entity.RefundParam{TransactionID: 1}).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func() {
+				refundMock.EXPECT().GetList(context.Background(), entity.RefundParam{TransactionID: 1}).Return(refundsMock, nil)
+			},
+			want:    refundsMock,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc()
+			got, err := r.GetList(context.Background(), entity.RefundParam{TransactionID: 1})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("refund.GetList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
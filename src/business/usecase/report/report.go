@@ -0,0 +1,361 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	reportDom "go-clean/src/business/domain/report"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/midtrans"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultDays is the range of a report without dates, up to today.
+	defaultDays  = 30
+	defaultLimit = 10
+	// maxDays bounds the range of a report, a longer range is split by the
+	// caller.
+	maxDays = 3 * 366
+)
+
+type Interface interface {
+	Revenue(ctx context.Context, param entity.ReportParam) ([]entity.RevenueReport, error)
+	TopProducts(ctx context.Context, param entity.ReportParam) ([]entity.ProductReport, error)
+	TopCategories(ctx context.Context, param entity.ReportParam) ([]entity.CategoryReport, error)
+	PaymentMethods(ctx context.Context, param entity.ReportParam) ([]entity.PaymentMethodReport, error)
+	// Summary is the paid orders, their average value and the conversion of
+	// carts and orders to payment.
+	Summary(ctx context.Context, param entity.ReportParam) (entity.SalesSummary, error)
+	// Export is one of the entity.Report* reports as a CSV file.
+	Export(ctx context.Context, report string, param entity.ReportParam) (entity.Attachment, error)
+}
+
+type report struct {
+	report reportDom.Interface
+}
+
+func Init(rd reportDom.Interface) Interface {
+	r := &report{
+		report: rd,
+	}
+
+	return r
+}
+
+func (r *report) Revenue(ctx context.Context, param entity.ReportParam) ([]entity.RevenueReport, error) {
+	param, err := window(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.revenue(ctx, param)
+}
+
+func (r *report) TopProducts(ctx context.Context, param entity.ReportParam) ([]entity.ProductReport, error) {
+	param, err := window(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.report.TopProducts(ctx, param)
+}
+
+func (r *report) TopCategories(ctx context.Context, param entity.ReportParam) ([]entity.CategoryReport, error) {
+	param, err := window(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.report.TopCategories(ctx, param)
+}
+
+func (r *report) PaymentMethods(ctx context.Context, param entity.ReportParam) ([]entity.PaymentMethodReport, error) {
+	param, err := window(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.paymentMethods(ctx, param)
+}
+
+func (r *report) Summary(ctx context.Context, param entity.ReportParam) (entity.SalesSummary, error) {
+	param, err := window(param)
+	if err != nil {
+		return entity.SalesSummary{}, err
+	}
+
+	return r.summary(ctx, param)
+}
+
+func (r *report) Export(ctx context.Context, report string, param entity.ReportParam) (entity.Attachment, error) {
+	result := entity.Attachment{}
+
+	param, err := window(param)
+	if err != nil {
+		return result, err
+	}
+
+	rows, err := r.rows(ctx, report, param)
+	if err != nil {
+		return result, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := csv.NewWriter(buf).WriteAll(rows); err != nil {
+		return result, err
+	}
+
+	result.Filename = fmt.Sprintf("%s_%s_%s.csv", report, param.From.Format("20060102"), param.To.AddDate(0, 0, -1).Format("20060102"))
+	result.ContentType = entity.ContentTypeCSV
+	result.Content = buf.Bytes()
+
+	return result, nil
+}
+
+// rows are report as CSV records, the first record is the header.
+func (r *report) rows(ctx context.Context, report string, param entity.ReportParam) ([][]string, error) {
+	switch report {
+	case entity.ReportRevenue:
+		reports, err := r.revenue(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := [][]string{{"period", "orders", "revenue"}}
+		for _, rp := range reports {
+			rows = append(rows, []string{rp.Period, itoa(rp.Orders), itoa(rp.Revenue)})
+		}
+
+		return rows, nil
+	case entity.ReportProducts:
+		reports, err := r.report.TopProducts(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := [][]string{{"product_id", "name", "units", "revenue"}}
+		for _, rp := range reports {
+			rows = append(rows, []string{itoa(int64(rp.ProductID)), text(rp.Name), itoa(rp.Units), itoa(rp.Revenue)})
+		}
+
+		return rows, nil
+	case entity.ReportCategories:
+		reports, err := r.report.TopCategories(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := [][]string{{"category_id", "name", "units", "revenue"}}
+		for _, rp := range reports {
+			rows = append(rows, []string{itoa(int64(rp.CategoryID)), text(rp.Name), itoa(rp.Units), itoa(rp.Revenue)})
+		}
+
+		return rows, nil
+	case entity.ReportPaymentMethods:
+		reports, err := r.paymentMethods(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := [][]string{{"payment_type", "payment_method", "orders", "revenue"}}
+		for _, rp := range reports {
+			rows = append(rows, []string{itoa(int64(rp.PaymentType)), rp.PaymentMethod, itoa(rp.Orders), itoa(rp.Revenue)})
+		}
+
+		return rows, nil
+	case entity.ReportSummary:
+		summary, err := r.summary(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+
+		return [][]string{
+			{"metric", "value"},
+			{"from", summary.From.Format("2006-01-02")},
+			{"to", summary.To.Format("2006-01-02")},
+			{"orders", itoa(summary.Orders)},
+			{"revenue", itoa(summary.Revenue)},
+			{"average_order_value", itoa(summary.AverageOrderValue)},
+			{"carts", itoa(summary.Conversion.Carts)},
+			{"paid_carts", itoa(summary.Conversion.PaidCarts)},
+			{"cart_conversion", strconv.FormatFloat(summary.Conversion.CartConversion, 'f', -1, 64)},
+			{"created_orders", itoa(summary.Conversion.Orders)},
+			{"paid_orders", itoa(summary.Conversion.PaidOrders)},
+			{"order_conversion", strconv.FormatFloat(summary.Conversion.OrderConversion, 'f', -1, 64)},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown report %q", report)
+}
+
+// revenue lists every period of the range, periods without orders are zero.
+func (r *report) revenue(ctx context.Context, param entity.ReportParam) ([]entity.RevenueReport, error) {
+	reports, err := r.report.Revenue(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	reportMap := map[string]entity.RevenueReport{}
+	for _, rp := range reports {
+		reportMap[rp.Period] = rp
+	}
+
+	result := []entity.RevenueReport{}
+	for day := param.From; day.Before(param.To); day = day.AddDate(0, 0, 1) {
+		key := period(day, param.Interval)
+		if n := len(result); n > 0 && result[n-1].Period == key {
+			continue
+		}
+
+		rp, ok := reportMap[key]
+		if !ok {
+			rp = entity.RevenueReport{Period: key}
+		}
+		delete(reportMap, key)
+		result = append(result, rp)
+	}
+
+	// a period outside the range, e.g. when the database groups in another
+	// time zone, is kept rather than dropped
+	if len(reportMap) > 0 {
+		for _, rp := range reportMap {
+			result = append(result, rp)
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Period < result[j].Period
+		})
+	}
+
+	return result, nil
+}
+
+func (r *report) paymentMethods(ctx context.Context, param entity.ReportParam) ([]entity.PaymentMethodReport, error) {
+	reports, err := r.report.PaymentMethods(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range reports {
+		reports[i].PaymentMethod = midtrans.PaymentNames[reports[i].PaymentType]
+	}
+
+	return reports, nil
+}
+
+func (r *report) summary(ctx context.Context, param entity.ReportParam) (entity.SalesSummary, error) {
+	result := entity.SalesSummary{
+		From: param.From,
+		To:   param.To.AddDate(0, 0, -1),
+	}
+
+	// every paid order has one payment method, so the methods add up to the
+	// paid orders
+	methods, err := r.report.PaymentMethods(ctx, param)
+	if err != nil {
+		return result, err
+	}
+
+	for _, m := range methods {
+		result.Orders += m.Orders
+		result.Revenue += m.Revenue
+	}
+
+	if result.Orders > 0 {
+		result.AverageOrderValue = int64(math.Round(float64(result.Revenue) / float64(result.Orders)))
+	}
+
+	conversion, err := r.report.Conversion(ctx, param)
+	if err != nil {
+		return result, err
+	}
+
+	conversion.CartConversion = ratio(conversion.PaidCarts, conversion.Carts)
+	conversion.OrderConversion = ratio(conversion.PaidOrders, conversion.Orders)
+	result.Conversion = conversion
+
+	return result, nil
+}
+
+// window applies the defaults of param and checks its range. From and To of
+// the result are the start of their days and To is moved to the day after,
+// the domain does not include To.
+func window(param entity.ReportParam) (entity.ReportParam, error) {
+	if param.To.IsZero() {
+		param.To = time.Now()
+	}
+	param.To = startOfDay(param.To)
+
+	if param.From.IsZero() {
+		param.From = param.To.AddDate(0, 0, -(defaultDays - 1))
+	}
+	param.From = startOfDay(param.From)
+
+	if param.From.After(param.To) {
+		return param, apperror.Validation("from must not be after to").WithKey("report.invalid_range")
+	}
+
+	if param.To.Sub(param.From) >= maxDays*24*time.Hour {
+		return param, apperror.Validation(fmt.Sprintf("the date range must not exceed %d days", maxDays)).WithKey("report.range_too_long", maxDays)
+	}
+	param.To = param.To.AddDate(0, 0, 1)
+
+	if param.Interval == "" {
+		param.Interval = entity.ReportIntervalDay
+	}
+
+	if param.SortBy == "" {
+		param.SortBy = entity.ReportSortUnits
+	}
+
+	if param.Limit == 0 {
+		param.Limit = defaultLimit
+	}
+
+	return param, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// period is the RevenueReport.Period of day.
+func period(day time.Time, interval string) string {
+	switch interval {
+	case entity.ReportIntervalWeek:
+		year, week := day.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case entity.ReportIntervalMonth:
+		return day.Format("2006-01")
+	}
+
+	return day.Format("2006-01-02")
+}
+
+// ratio is part of total rounded to four decimals, zero without a total.
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// text keeps a spreadsheet from reading a name as a formula.
+func text(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
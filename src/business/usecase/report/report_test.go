@@ -0,0 +1,368 @@
+package report_test
+
+import (
+	"context"
+	mock_report "go-clean/src/business/domain/mock/report"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/report"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_report_Revenue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportMock := mock_report.NewMockInterface(ctrl)
+
+	r := report.Init(reportMock)
+
+	type mockfields struct {
+		report *mock_report.MockInterface
+	}
+
+	mocks := mockfields{
+		report: reportMock,
+	}
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		param    entity.ReportParam
+		mockFunc func(mock mockfields)
+		want     []entity.RevenueReport
+		wantErr  bool
+	}{
+		{
+			name:     "from is after to",
+			param:    entity.ReportParam{From: day(10, 5), To: day(10, 1)},
+			mockFunc: func(mock mockfields) {},
+			wantErr:  true,
+		},
+		{
+			name:     "range is too long",
+			param:    entity.ReportParam{From: day(1, 1).AddDate(-3, 0, 0), To: day(10, 1)},
+			mockFunc: func(mock mockfields) {},
+			wantErr:  true,
+		},
+		{
+			name:  "failed to get revenue",
+			param: entity.ReportParam{From: day(10, 1), To: day(10, 3)},
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().Revenue(context.Background(), gomock.Any()).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "all ok days without orders are zero",
+			param: entity.ReportParam{From: day(10, 1), To: day(10, 3)},
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().Revenue(context.Background(), entity.ReportParam{
+					From:     day(10, 1),
+					To:       day(10, 4),
+					Interval: entity.ReportIntervalDay,
+					SortBy:   entity.ReportSortUnits,
+					Limit:    10,
+				}).Return([]entity.RevenueReport{
+					{Period: "2026-10-02", Orders: 2, Revenue: 300000},
+				}, nil)
+			},
+			want: []entity.RevenueReport{
+				{Period: "2026-10-01"},
+				{Period: "2026-10-02", Orders: 2, Revenue: 300000},
+				{Period: "2026-10-03"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "all ok by iso week",
+			param: entity.ReportParam{From: day(10, 1), To: day(10, 14), Interval: entity.ReportIntervalWeek},
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().Revenue(context.Background(), gomock.Any()).Return([]entity.RevenueReport{
+					{Period: "2026-W41", Orders: 1, Revenue: 59000},
+				}, nil)
+			},
+			want: []entity.RevenueReport{
+				{Period: "2026-W40"},
+				{Period: "2026-W41", Orders: 1, Revenue: 59000},
+				{Period: "2026-W42"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "all ok by month keeps periods grouped outside the range",
+			param: entity.ReportParam{From: day(10, 1), To: day(11, 30), Interval: entity.ReportIntervalMonth},
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().Revenue(context.Background(), gomock.Any()).Return([]entity.RevenueReport{
+					{Period: "2026-09", Orders: 1, Revenue: 10000},
+					{Period: "2026-11", Orders: 3, Revenue: 90000},
+				}, nil)
+			},
+			want: []entity.RevenueReport{
+				{Period: "2026-09", Orders: 1, Revenue: 10000},
+				{Period: "2026-10"},
+				{Period: "2026-11", Orders: 3, Revenue: 90000},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := r.Revenue(context.Background(), tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.Revenue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_report_TopProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportMock := mock_report.NewMockInterface(ctrl)
+
+	r := report.Init(reportMock)
+
+	type mockfields struct {
+		report *mock_report.MockInterface
+	}
+
+	mocks := mockfields{
+		report: reportMock,
+	}
+
+	productsMock := []entity.ProductReport{
+		{ProductID: 1, Name: "Kemeja", Units: 4, Revenue: 200000},
+	}
+
+	tests := []struct {
+		name     string
+		param    entity.ReportParam
+		mockFunc func(mock mockfields)
+		want     []entity.ProductReport
+		wantErr  bool
+	}{
+		{
+			name:  "failed to get products",
+			param: entity.ReportParam{},
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().TopProducts(context.Background(), gomock.Any()).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "all ok last 30 days by default",
+			param: entity.ReportParam{},
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().TopProducts(context.Background(), gomock.Any()).DoAndReturn(func(ctx context.Context, param entity.ReportParam) ([]entity.ProductReport, error) {
+					now := time.Now()
+					assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()), param.To)
+					assert.Equal(t, param.To.AddDate(0, 0, -30), param.From)
+					assert.Equal(t, entity.ReportSortUnits, param.SortBy)
+					assert.Equal(t, 10, param.Limit)
+					return productsMock, nil
+				})
+			},
+			want:    productsMock,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := r.TopProducts(context.Background(), tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.TopProducts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_report_Summary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportMock := mock_report.NewMockInterface(ctrl)
+
+	r := report.Init(reportMock)
+
+	type mockfields struct {
+		report *mock_report.MockInterface
+	}
+
+	mocks := mockfields{
+		report: reportMock,
+	}
+
+	paramMock := entity.ReportParam{
+		From: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	methodsMock := []entity.PaymentMethodReport{
+		{PaymentType: 1, Orders: 3, Revenue: 500000},
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		want     entity.SalesSummary
+		wantErr  bool
+	}{
+		{
+			name: "failed to get payment methods",
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().PaymentMethods(context.Background(), gomock.Any()).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to get conversion",
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().PaymentMethods(context.Background(), gomock.Any()).Return(methodsMock, nil)
+				mock.report.EXPECT().Conversion(context.Background(), gomock.Any()).Return(entity.ConversionReport{}, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().PaymentMethods(context.Background(), gomock.Any()).Return(methodsMock, nil)
+				mock.report.EXPECT().Conversion(context.Background(), gomock.Any()).Return(entity.ConversionReport{
+					Carts:      9,
+					PaidCarts:  4,
+					Orders:     4,
+					PaidOrders: 3,
+				}, nil)
+			},
+			want: entity.SalesSummary{
+				From:              time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+				To:                time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+				Orders:            3,
+				Revenue:           500000,
+				AverageOrderValue: 166667,
+				Conversion: entity.ConversionReport{
+					Carts:           9,
+					PaidCarts:       4,
+					CartConversion:  0.4444,
+					Orders:          4,
+					PaidOrders:      3,
+					OrderConversion: 0.75,
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := r.Summary(context.Background(), paramMock)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.Summary() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_report_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportMock := mock_report.NewMockInterface(ctrl)
+
+	r := report.Init(reportMock)
+
+	type mockfields struct {
+		report *mock_report.MockInterface
+	}
+
+	mocks := mockfields{
+		report: reportMock,
+	}
+
+	paramMock := entity.ReportParam{
+		From: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name     string
+		report   string
+		mockFunc func(mock mockfields)
+		want     entity.Attachment
+		wantErr  bool
+	}{
+		{
+			name:     "unknown report",
+			report:   "stock",
+			mockFunc: func(mock mockfields) {},
+			wantErr:  true,
+		},
+		{
+			name:   "failed to get categories",
+			report: entity.ReportCategories,
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().TopCategories(context.Background(), gomock.Any()).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "all ok products",
+			report: entity.ReportProducts,
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().TopProducts(context.Background(), gomock.Any()).Return([]entity.ProductReport{
+					{ProductID: 1, Name: "Kemeja, batik", Units: 4, Revenue: 200000},
+					{ProductID: 2, Name: "=HYPERLINK()", Units: 1, Revenue: 5000},
+				}, nil)
+			},
+			want: entity.Attachment{
+				Filename:    "products_20261001_20261031.csv",
+				ContentType: entity.ContentTypeCSV,
+				Content:     []byte("product_id,name,units,revenue\n1,\"Kemeja, batik\",4,200000\n2,'=HYPERLINK(),1,5000\n"),
+			},
+			wantErr: false,
+		},
+		{
+			name:   "all ok payment methods",
+			report: entity.ReportPaymentMethods,
+			mockFunc: func(mock mockfields) {
+				mock.report.EXPECT().PaymentMethods(context.Background(), gomock.Any()).Return([]entity.PaymentMethodReport{
+					{PaymentType: 1, Orders: 3, Revenue: 500000},
+				}, nil)
+			},
+			want: entity.Attachment{
+				Filename:    "payment_methods_20261001_20261031.csv",
+				ContentType: entity.ContentTypeCSV,
+				Content:     []byte("payment_type,payment_method,orders,revenue\n1,GoPay,3,500000\n"),
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := r.Export(context.Background(), tt.report, paramMock)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("report.Export() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
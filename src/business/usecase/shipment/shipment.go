@@ -0,0 +1,372 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+	addressDom "go-clean/src/business/domain/address"
+	cartDom "go-clean/src/business/domain/cart"
+	midtransTransactionDom "go-clean/src/business/domain/midtrans_transaction"
+	outboxDom "go-clean/src/business/domain/outbox"
+	productDom "go-clean/src/business/domain/product"
+	shipmentDom "go-clean/src/business/domain/shipment"
+	transactionDom "go-clean/src/business/domain/transaction"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	"time"
+)
+
+type Interface interface {
+	// Create ships the items of createParam, or everything that is not
+	// shipped yet when it has no items.
+	Create(ctx context.Context, param entity.ShipmentParam, createParam entity.CreateShipmentParam) (entity.Shipment, error)
+	GetList(ctx context.Context, param entity.ShipmentParam) ([]entity.Shipment, error)
+	Deliver(ctx context.Context, param entity.ShipmentParam) (entity.Shipment, error)
+	Track(ctx context.Context, param entity.ShipmentParam) (entity.Tracking, error)
+	// Quote prices the delivery of the user's cart with every courier
+	// service.
+	Quote(ctx context.Context, param entity.ShippingQuoteParam) ([]entity.ShippingQuote, error)
+}
+
+type shipment struct {
+	log                 log.Interface
+	auth                auth.Interface
+	shipment            shipmentDom.Interface
+	transaction         transactionDom.Interface
+	midtransTransaction midtransTransactionDom.Interface
+	cart                cartDom.Interface
+	product             productDom.Interface
+	address             addressDom.Interface
+	outbox              outboxDom.Interface
+}
+
+func Init(log log.Interface, auth auth.Interface, sd shipmentDom.Interface, td transactionDom.Interface, mttd midtransTransactionDom.Interface, cd cartDom.Interface, pd productDom.Interface, ad addressDom.Interface, od outboxDom.Interface) Interface {
+	s := &shipment{
+		log:                 log,
+		auth:                auth,
+		shipment:            sd,
+		transaction:         td,
+		midtransTransaction: mttd,
+		cart:                cd,
+		product:             pd,
+		address:             ad,
+		outbox:              od,
+	}
+
+	return s
+}
+
+func (s *shipment) Create(ctx context.Context, param entity.ShipmentParam, createParam entity.CreateShipmentParam) (entity.Shipment, error) {
+	result := entity.Shipment{}
+
+	user, err := s.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	midtransTransaction, err := s.midtransTransaction.Get(ctx, entity.MidtransTransactionParam{
+		TransactionID: param.TransactionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if midtransTransaction.Status != entity.StatusSuccess && midtransTransaction.Status != entity.StatusPartialRefund {
+		return result, apperror.Conflict("only paid orders can be shipped").WithKey("shipment.not_shippable")
+	}
+
+	carts, shipments, err := s.fulfilment(ctx, param.TransactionID)
+	if err != nil {
+		return result, err
+	}
+
+	items, err := shipmentItems(carts, shippedQty(shipments), createParam.Items)
+	if err != nil {
+		return result, err
+	}
+
+	if len(items) == 0 {
+		return result, apperror.Conflict("the order is shipped in full").WithKey("shipment.nothing_left")
+	}
+
+	now := time.Now()
+	err = s.outbox.WithTx(ctx, func(ctx context.Context) error {
+		result, err = s.shipment.Create(ctx, entity.Shipment{
+			TransactionID:  param.TransactionID,
+			Courier:        createParam.Courier,
+			Service:        createParam.Service,
+			TrackingNumber: createParam.TrackingNumber,
+			Items:          items,
+			Status:         entity.ShipmentStatusShipped,
+			ShippedAt:      &now,
+			UserID:         user.User.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		event, err := entity.NewOutboxEvent(entity.EventOrderShipped, param.TransactionID, entity.OrderShippedPayload{
+			TransactionID:  param.TransactionID,
+			ShipmentID:     result.ID,
+			Courier:        result.Courier,
+			Service:        result.Service,
+			TrackingNumber: result.TrackingNumber,
+			Status:         fulfilmentStatus(carts, append(shipments, result)),
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.outbox.Add(ctx, event)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	s.log.Info(ctx, "order shipped", "audit", true, "user_id", user.User.ID, "transaction_id", param.TransactionID, "shipment_id", result.ID, "courier", result.Courier, "tracking_number", result.TrackingNumber)
+
+	return result, nil
+}
+
+func (s *shipment) GetList(ctx context.Context, param entity.ShipmentParam) ([]entity.Shipment, error) {
+	return s.shipment.GetList(ctx, entity.ShipmentParam{
+		TransactionID: param.TransactionID,
+	})
+}
+
+func (s *shipment) Deliver(ctx context.Context, param entity.ShipmentParam) (entity.Shipment, error) {
+	user, err := s.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return entity.Shipment{}, err
+	}
+
+	result, err := s.shipment.Get(ctx, param)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Status == entity.ShipmentStatusDelivered {
+		return result, apperror.Conflict("the shipment is delivered already").WithKey("shipment.already_delivered")
+	}
+
+	carts, shipments, err := s.fulfilment(ctx, result.TransactionID)
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	result.Status = entity.ShipmentStatusDelivered
+	result.DeliveredAt = &now
+
+	for i := range shipments {
+		if shipments[i].ID == result.ID {
+			shipments[i] = result
+		}
+	}
+
+	err = s.outbox.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.shipment.Update(ctx, entity.ShipmentParam{
+			ID: result.ID,
+		}, entity.UpdateShipmentParam{
+			Status:      entity.ShipmentStatusDelivered,
+			DeliveredAt: &now,
+		}); err != nil {
+			return err
+		}
+
+		event, err := entity.NewOutboxEvent(entity.EventOrderDelivered, result.TransactionID, entity.OrderDeliveredPayload{
+			TransactionID:  result.TransactionID,
+			ShipmentID:     result.ID,
+			TrackingNumber: result.TrackingNumber,
+			Status:         fulfilmentStatus(carts, shipments),
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.outbox.Add(ctx, event)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	s.log.Info(ctx, "shipment delivered", "audit", true, "user_id", user.User.ID, "transaction_id", result.TransactionID, "shipment_id", result.ID)
+
+	return result, nil
+}
+
+func (s *shipment) Track(ctx context.Context, param entity.ShipmentParam) (entity.Tracking, error) {
+	result := entity.Tracking{}
+
+	transaction, err := s.transaction.Get(ctx, entity.TransactionParam{
+		ID: param.TransactionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	carts, shipments, err := s.fulfilment(ctx, param.TransactionID)
+	if err != nil {
+		return result, err
+	}
+
+	result.TransactionID = transaction.ID
+	result.Status = fulfilmentStatus(carts, shipments)
+	result.Courier = transaction.ShippingCourier
+	result.Service = transaction.ShippingService
+	result.ShippingAddress = transaction.AddressShip
+	result.Shipments = shipments
+
+	return result, nil
+}
+
+func (s *shipment) Quote(ctx context.Context, param entity.ShippingQuoteParam) ([]entity.ShippingQuote, error) {
+	user, err := s.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	carts, err := s.cart.GetList(ctx, entity.CartParam{
+		UserID: user.User.ID,
+		Status: entity.StatusInCart,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(carts) == 0 {
+		return nil, apperror.Validation("cart is empty").WithKey("transaction.cart_empty")
+	}
+
+	productIDs := []uint{}
+	for _, c := range carts {
+		productIDs = append(productIDs, c.ProductID)
+	}
+
+	products, err := s.product.GetListByID(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productMap := make(map[uint]entity.Product)
+	for _, p := range products {
+		productMap[p.ID] = p
+	}
+
+	destination := ""
+	if param.AddressID != 0 {
+		address, err := s.address.Get(ctx, entity.AddressParam{
+			ID:     param.AddressID,
+			UserID: user.User.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		destination = address.PostalCode
+	}
+
+	return s.shipment.Quote(ctx, entity.CartWeight(carts, productMap), destination), nil
+}
+
+// fulfilment is the paid carts of the order and its shipments.
+func (s *shipment) fulfilment(ctx context.Context, transactionID uint) ([]entity.Cart, []entity.Shipment, error) {
+	carts, err := s.cart.GetList(ctx, entity.CartParam{
+		TransactionID: transactionID,
+		Status:        entity.StatusPaid,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shipments, err := s.shipment.GetList(ctx, entity.ShipmentParam{
+		TransactionID: transactionID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return carts, shipments, nil
+}
+
+func shippedQty(shipments []entity.Shipment) map[uint]int {
+	shipped := map[uint]int{}
+	for _, sh := range shipments {
+		for _, item := range sh.Items {
+			shipped[item.CartID] += item.Qty
+		}
+	}
+
+	return shipped
+}
+
+// fulfilmentStatus is delivered once every paid item is shipped and every
+// shipment is delivered.
+func fulfilmentStatus(carts []entity.Cart, shipments []entity.Shipment) string {
+	if len(shipments) == 0 {
+		return entity.FulfilmentUnfulfilled
+	}
+
+	shipped := shippedQty(shipments)
+	for _, c := range carts {
+		if shipped[c.ID] < c.Qty {
+			return entity.FulfilmentPartiallyShipped
+		}
+	}
+
+	for _, sh := range shipments {
+		if sh.Status != entity.ShipmentStatusDelivered {
+			return entity.FulfilmentShipped
+		}
+	}
+
+	return entity.FulfilmentDelivered
+}
+
+// shipmentItems checks the requested items against the paid carts of the
+// order. Without requested items everything that is not shipped yet is
+// shipped.
+func shipmentItems(carts []entity.Cart, shipped map[uint]int, requested []entity.ShipmentItem) ([]entity.ShipmentItem, error) {
+	items := []entity.ShipmentItem{}
+
+	if len(requested) == 0 {
+		for _, c := range carts {
+			if qty := c.Qty - shipped[c.ID]; qty > 0 {
+				items = append(items, entity.ShipmentItem{CartID: c.ID, Qty: qty})
+			}
+		}
+
+		return items, nil
+	}
+
+	cartMap := map[uint]entity.Cart{}
+	for _, c := range carts {
+		cartMap[c.ID] = c
+	}
+
+	// the same cart may be listed more than once
+	qtyMap := map[uint]int{}
+	for _, item := range requested {
+		if _, ok := qtyMap[item.CartID]; !ok {
+			items = append(items, entity.ShipmentItem{CartID: item.CartID})
+		}
+		qtyMap[item.CartID] += item.Qty
+	}
+
+	for i, item := range items {
+		c, ok := cartMap[item.CartID]
+		if !ok {
+			return nil, apperror.Validation(fmt.Sprintf("cart %d is not a paid item of the order", item.CartID)).WithKey("shipment.item_not_found", item.CartID)
+		}
+
+		left := c.Qty - shipped[c.ID]
+		if qtyMap[item.CartID] > left {
+			return nil, apperror.Validation(fmt.Sprintf("cart %d has %d item(s) left to ship", c.ID, left)).WithKey("shipment.qty_exceeded", c.ID, left)
+		}
+
+		items[i].Qty = qtyMap[item.CartID]
+	}
+
+	return items, nil
+}
@@ -0,0 +1,578 @@
+package shipment_test
+
+import (
+	"context"
+	"encoding/json"
+	mock_address "go-clean/src/business/domain/mock/address"
+	mock_cart "go-clean/src/business/domain/mock/cart"
+	mock_midtrans_transaction "go-clean/src/business/domain/mock/midtrans_transaction"
+	mock_outbox "go-clean/src/business/domain/mock/outbox"
+	mock_product "go-clean/src/business/domain/mock/product"
+	mock_shipment "go-clean/src/business/domain/mock/shipment"
+	mock_transaction "go-clean/src/business/domain/mock/transaction"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/shipment"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	mock_auth "go-clean/src/lib/tests/mock/auth"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func Test_shipment_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	shipmentMock := mock_shipment.NewMockInterface(ctrl)
+	transactionMock := mock_transaction.NewMockInterface(ctrl)
+	midtransTransactionMock := mock_midtrans_transaction.NewMockInterface(ctrl)
+	cartMock := mock_cart.NewMockInterface(ctrl)
+	productMock := mock_product.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+
+	s := shipment.Init(log.Init(log.Config{Level: "disabled"}), authMock, shipmentMock, transactionMock, midtransTransactionMock, cartMock, productMock, addressMock, outboxMock)
+
+	type mockfields struct {
+		auth                *mock_auth.MockInterface
+		shipment            *mock_shipment.MockInterface
+		transaction         *mock_transaction.MockInterface
+		midtransTransaction *mock_midtrans_transaction.MockInterface
+		cart                *mock_cart.MockInterface
+		product             *mock_product.MockInterface
+		address             *mock_address.MockInterface
+		outbox              *mock_outbox.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:                authMock,
+		shipment:            shipmentMock,
+		transaction:         transactionMock,
+		midtransTransaction: midtransTransactionMock,
+		cart:                cartMock,
+		product:             productMock,
+		address:             addressMock,
+		outbox:              outboxMock,
+	}
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 9,
+		},
+	}
+
+	cartsMock := []entity.Cart{
+		{
+			Model:     gorm.Model{ID: 1},
+			ProductID: 1,
+			Qty:       2,
+		},
+		{
+			Model:     gorm.Model{ID: 2},
+			ProductID: 2,
+			Qty:       1,
+		},
+	}
+
+	withTx := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}
+
+	fulfilmentOf := func(eventType string, status string) func(ctx context.Context, events ...entity.OutboxEvent) error {
+		return func(ctx context.Context, events ...entity.OutboxEvent) error {
+			assert.Equal(t, eventType, events[0].Type)
+
+			payload := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal([]byte(events[0].Payload), &payload))
+			assert.Equal(t, status, payload["status"])
+			return nil
+		}
+	}
+
+	paramMock := entity.ShipmentParam{
+		TransactionID: 1,
+	}
+
+	settledMock := entity.MidtransTransaction{
+		TransactionID: 1,
+		Status:        entity.StatusSuccess,
+	}
+
+	pendingMock := settledMock
+	pendingMock.Status = entity.StatusPending
+
+	// one item of cart 1 is shipped already
+	shipmentsMock := []entity.Shipment{
+		{
+			Model:  gorm.Model{ID: 1},
+			Items:  []entity.ShipmentItem{{CartID: 1, Qty: 1}},
+			Status: entity.ShipmentStatusShipped,
+		},
+	}
+
+	created := func(ctx context.Context, shipment entity.Shipment) (entity.Shipment, error) {
+		shipment.ID = 2
+		return shipment, nil
+	}
+
+	loaded := func(mock mockfields) {
+		mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+		mock.midtransTransaction.EXPECT().Get(context.Background(), entity.MidtransTransactionParam{TransactionID: 1}).Return(settledMock, nil)
+		mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{TransactionID: 1, Status: entity.StatusPaid}).Return(cartsMock, nil)
+		mock.shipment.EXPECT().GetList(context.Background(), paramMock).Return(shipmentsMock, nil)
+	}
+
+	createParamMock := entity.CreateShipmentParam{
+		Courier:        "jne",
+		Service:        "REG",
+		TrackingNumber: "JNE-2",
+	}
+
+	tests := []struct {
+		name        string
+		createParam entity.CreateShipmentParam
+		mockFunc    func(mock mockfields)
+		wantItems   []entity.ShipmentItem
+		wantErr     bool
+	}{
+		{
+			name:        "failed to get auth user",
+			createParam: createParamMock,
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:        "order is not paid",
+			createParam: createParamMock,
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), entity.MidtransTransactionParam{TransactionID: 1}).Return(pendingMock, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "qty exceeds what is left of the cart",
+			createParam: entity.CreateShipmentParam{
+				Courier:        "jne",
+				Service:        "REG",
+				TrackingNumber: "JNE-2",
+				Items:          []entity.ShipmentItem{{CartID: 1, Qty: 2}},
+			},
+			mockFunc: loaded,
+			wantErr:  true,
+		},
+		{
+			name: "item is not part of the order",
+			createParam: entity.CreateShipmentParam{
+				Courier:        "jne",
+				Service:        "REG",
+				TrackingNumber: "JNE-2",
+				Items:          []entity.ShipmentItem{{CartID: 7, Qty: 1}},
+			},
+			mockFunc: loaded,
+			wantErr:  true,
+		},
+		{
+			name: "all ok partial shipment",
+			createParam: entity.CreateShipmentParam{
+				Courier:        "jne",
+				Service:        "REG",
+				TrackingNumber: "JNE-2",
+				Items:          []entity.ShipmentItem{{CartID: 2, Qty: 1}},
+			},
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.shipment.EXPECT().Create(context.Background(), gomock.Any()).DoAndReturn(created)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(fulfilmentOf(entity.EventOrderShipped, entity.FulfilmentPartiallyShipped))
+			},
+			wantItems: []entity.ShipmentItem{{CartID: 2, Qty: 1}},
+			wantErr:   false,
+		},
+		{
+			name:        "all ok shipping the rest",
+			createParam: createParamMock,
+			mockFunc: func(mock mockfields) {
+				loaded(mock)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.shipment.EXPECT().Create(context.Background(), gomock.Any()).DoAndReturn(created)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(fulfilmentOf(entity.EventOrderShipped, entity.FulfilmentShipped))
+			},
+			wantItems: []entity.ShipmentItem{{CartID: 1, Qty: 1}, {CartID: 2, Qty: 1}},
+			wantErr:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := s.Create(context.Background(), paramMock, tt.createParam)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shipment.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantItems, got.Items)
+				assert.Equal(t, entity.ShipmentStatusShipped, got.Status)
+				assert.Equal(t, uint(9), got.UserID)
+				assert.NotNil(t, got.ShippedAt)
+			}
+		})
+	}
+}
+
+func Test_shipment_Deliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	shipmentMock := mock_shipment.NewMockInterface(ctrl)
+	transactionMock := mock_transaction.NewMockInterface(ctrl)
+	midtransTransactionMock := mock_midtrans_transaction.NewMockInterface(ctrl)
+	cartMock := mock_cart.NewMockInterface(ctrl)
+	productMock := mock_product.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+
+	s := shipment.Init(log.Init(log.Config{Level: "disabled"}), authMock, shipmentMock, transactionMock, midtransTransactionMock, cartMock, productMock, addressMock, outboxMock)
+
+	type mockfields struct {
+		auth                *mock_auth.MockInterface
+		shipment            *mock_shipment.MockInterface
+		transaction         *mock_transaction.MockInterface
+		midtransTransaction *mock_midtrans_transaction.MockInterface
+		cart                *mock_cart.MockInterface
+		product             *mock_product.MockInterface
+		address             *mock_address.MockInterface
+		outbox              *mock_outbox.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:                authMock,
+		shipment:            shipmentMock,
+		transaction:         transactionMock,
+		midtransTransaction: midtransTransactionMock,
+		cart:                cartMock,
+		product:             productMock,
+		address:             addressMock,
+		outbox:              outboxMock,
+	}
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 9,
+		},
+	}
+
+	cartsMock := []entity.Cart{
+		{
+			Model:     gorm.Model{ID: 1},
+			ProductID: 1,
+			Qty:       2,
+		},
+		{
+			Model:     gorm.Model{ID: 2},
+			ProductID: 2,
+			Qty:       1,
+		},
+	}
+
+	withTx := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}
+
+	fulfilmentOf := func(eventType string, status string) func(ctx context.Context, events ...entity.OutboxEvent) error {
+		return func(ctx context.Context, events ...entity.OutboxEvent) error {
+			assert.Equal(t, eventType, events[0].Type)
+
+			payload := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal([]byte(events[0].Payload), &payload))
+			assert.Equal(t, status, payload["status"])
+			return nil
+		}
+	}
+
+	paramMock := entity.ShipmentParam{
+		ID:            1,
+		TransactionID: 1,
+	}
+
+	shippedMock := entity.Shipment{
+		Model:         gorm.Model{ID: 1},
+		TransactionID: 1,
+		Items:         []entity.ShipmentItem{{CartID: 1, Qty: 2}, {CartID: 2, Qty: 1}},
+		Status:        entity.ShipmentStatusShipped,
+	}
+
+	deliveredMock := shippedMock
+	deliveredMock.Status = entity.ShipmentStatusDelivered
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		wantErr  bool
+	}{
+		{
+			name: "failed to get shipment",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.shipment.EXPECT().Get(context.Background(), paramMock).Return(entity.Shipment{}, gorm.ErrRecordNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name: "delivered already",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.shipment.EXPECT().Get(context.Background(), paramMock).Return(deliveredMock, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.shipment.EXPECT().Get(context.Background(), paramMock).Return(shippedMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{TransactionID: 1, Status: entity.StatusPaid}).Return(cartsMock, nil)
+				mock.shipment.EXPECT().GetList(context.Background(), entity.ShipmentParam{TransactionID: 1}).Return([]entity.Shipment{shippedMock}, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.shipment.EXPECT().Update(context.Background(), entity.ShipmentParam{ID: 1}, gomock.Any()).Return(nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(fulfilmentOf(entity.EventOrderDelivered, entity.FulfilmentDelivered))
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := s.Deliver(context.Background(), paramMock)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shipment.Deliver() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, entity.ShipmentStatusDelivered, got.Status)
+				assert.NotNil(t, got.DeliveredAt)
+			}
+		})
+	}
+}
+
+func Test_shipment_Track(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	shipmentMock := mock_shipment.NewMockInterface(ctrl)
+	transactionMock := mock_transaction.NewMockInterface(ctrl)
+	midtransTransactionMock := mock_midtrans_transaction.NewMockInterface(ctrl)
+	cartMock := mock_cart.NewMockInterface(ctrl)
+	productMock := mock_product.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+
+	s := shipment.Init(log.Init(log.Config{Level: "disabled"}), authMock, shipmentMock, transactionMock, midtransTransactionMock, cartMock, productMock, addressMock, outboxMock)
+
+	type mockfields struct {
+		auth                *mock_auth.MockInterface
+		shipment            *mock_shipment.MockInterface
+		transaction         *mock_transaction.MockInterface
+		midtransTransaction *mock_midtrans_transaction.MockInterface
+		cart                *mock_cart.MockInterface
+		product             *mock_product.MockInterface
+		address             *mock_address.MockInterface
+		outbox              *mock_outbox.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:                authMock,
+		shipment:            shipmentMock,
+		transaction:         transactionMock,
+		midtransTransaction: midtransTransactionMock,
+		cart:                cartMock,
+		product:             productMock,
+		address:             addressMock,
+		outbox:              outboxMock,
+	}
+
+	cartsMock := []entity.Cart{
+		{
+			Model:     gorm.Model{ID: 1},
+			ProductID: 1,
+			Qty:       2,
+		},
+		{
+			Model:     gorm.Model{ID: 2},
+			ProductID: 2,
+			Qty:       1,
+		},
+	}
+
+	transactionResultMock := entity.Transaction{
+		Model:           gorm.Model{ID: 1},
+		AddressShip:     "purwakarta",
+		ShippingCourier: "jne",
+		ShippingService: "REG",
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		want     entity.Tracking
+		wantErr  bool
+	}{
+		{
+			name: "failed to get transaction",
+			mockFunc: func(mock mockfields) {
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(entity.Transaction{}, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok not shipped yet",
+			mockFunc: func(mock mockfields) {
+				mock.transaction.EXPECT().Get(context.Background(), entity.TransactionParam{ID: 1}).Return(transactionResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{TransactionID: 1, Status: entity.StatusPaid}).Return(cartsMock, nil)
+				mock.shipment.EXPECT().GetList(context.Background(), entity.ShipmentParam{TransactionID: 1}).Return([]entity.Shipment{}, nil)
+			},
+			want: entity.Tracking{
+				TransactionID:   1,
+				Status:          entity.FulfilmentUnfulfilled,
+				Courier:         "jne",
+				Service:         "REG",
+				ShippingAddress: "purwakarta",
+				Shipments:       []entity.Shipment{},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := s.Track(context.Background(), entity.ShipmentParam{TransactionID: 1})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shipment.Track() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_shipment_Quote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	shipmentMock := mock_shipment.NewMockInterface(ctrl)
+	transactionMock := mock_transaction.NewMockInterface(ctrl)
+	midtransTransactionMock := mock_midtrans_transaction.NewMockInterface(ctrl)
+	cartMock := mock_cart.NewMockInterface(ctrl)
+	productMock := mock_product.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+
+	s := shipment.Init(log.Init(log.Config{Level: "disabled"}), authMock, shipmentMock, transactionMock, midtransTransactionMock, cartMock, productMock, addressMock, outboxMock)
+
+	type mockfields struct {
+		auth                *mock_auth.MockInterface
+		shipment            *mock_shipment.MockInterface
+		transaction         *mock_transaction.MockInterface
+		midtransTransaction *mock_midtrans_transaction.MockInterface
+		cart                *mock_cart.MockInterface
+		product             *mock_product.MockInterface
+		address             *mock_address.MockInterface
+		outbox              *mock_outbox.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:                authMock,
+		shipment:            shipmentMock,
+		transaction:         transactionMock,
+		midtransTransaction: midtransTransactionMock,
+		cart:                cartMock,
+		product:             productMock,
+		address:             addressMock,
+		outbox:              outboxMock,
+	}
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 9,
+		},
+	}
+
+	cartsMock := []entity.Cart{
+		{
+			Model:     gorm.Model{ID: 1},
+			ProductID: 1,
+			Qty:       2,
+		},
+		{
+			Model:     gorm.Model{ID: 2},
+			ProductID: 2,
+			Qty:       1,
+		},
+	}
+
+	cartParamMock := entity.CartParam{
+		UserID: 9,
+		Status: entity.StatusInCart,
+	}
+
+	productsMock := []entity.Product{
+		{Model: gorm.Model{ID: 1}, Weight: 500},
+		{Model: gorm.Model{ID: 2}, Weight: 1200},
+	}
+
+	quotesMock := []entity.ShippingQuote{
+		{Courier: "jne", Service: "REG", Cost: 18000},
+	}
+
+	tests := []struct {
+		name     string
+		param    entity.ShippingQuoteParam
+		mockFunc func(mock mockfields)
+		want     []entity.ShippingQuote
+		wantErr  bool
+	}{
+		{
+			name: "cart empty",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return([]entity.Cart{}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "all ok to a saved address",
+			param: entity.ShippingQuoteParam{AddressID: 3},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartsMock, nil)
+				mock.product.EXPECT().GetListByID(context.Background(), []uint{1, 2}).Return(productsMock, nil)
+				mock.address.EXPECT().Get(context.Background(), entity.AddressParam{ID: 3, UserID: 9}).Return(entity.Address{PostalCode: "41111"}, nil)
+				// 2 x 500 grams and 1 x 1200 grams
+				mock.shipment.EXPECT().Quote(context.Background(), 2200, "41111").Return(quotesMock)
+			},
+			want:    quotesMock,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := s.Quote(context.Background(), tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shipment.Quote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
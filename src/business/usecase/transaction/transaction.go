@@ -3,17 +3,24 @@ package transaction
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
+	addressDom "go-clean/src/business/domain/address"
 	cartDom "go-clean/src/business/domain/cart"
 	midtransDom "go-clean/src/business/domain/midtrans"
 	midtransTransactionDom "go-clean/src/business/domain/midtrans_transaction"
+	outboxDom "go-clean/src/business/domain/outbox"
 	productDom "go-clean/src/business/domain/product"
+	shipmentDom "go-clean/src/business/domain/shipment"
+	statusStreamDom "go-clean/src/business/domain/status_stream"
 	transactionDom "go-clean/src/business/domain/transaction"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
 	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"go-clean/src/lib/midtrans"
-	"log"
 	"strconv"
+	"time"
 
 	"github.com/midtrans/midtrans-go/coreapi"
 )
@@ -21,25 +28,43 @@ import (
 type Interface interface {
 	Create(ctx context.Context, createParam entity.CreateTransactionParam) (entity.Transaction, error)
 	ValidateTransaction(ctx context.Context, transactionID uint, user auth.UserAuthInfo) error
+	// Cancel cancels an order that is still waiting for payment, its carts
+	// are released and, with RestoreCart, put back into the user's cart.
+	Cancel(ctx context.Context, param entity.CancelTransactionParam) error
+	// StreamStatus streams the payment status changes of the user's
+	// transactions until ctx is done.
+	StreamStatus(ctx context.Context) (<-chan entity.TransactionStatusUpdate, error)
 }
 
 type transaction struct {
+	log                 log.Interface
+	metrics             metrics.Interface
 	auth                auth.Interface
 	cart                cartDom.Interface
 	product             productDom.Interface
 	transaction         transactionDom.Interface
 	midtrans            midtransDom.Interface
 	midtransTransaction midtransTransactionDom.Interface
+	address             addressDom.Interface
+	outbox              outboxDom.Interface
+	statusStream        statusStreamDom.Interface
+	shipment            shipmentDom.Interface
 }
 
-func Init(auth auth.Interface, td transactionDom.Interface, cd cartDom.Interface, pd productDom.Interface, md midtransDom.Interface, mtd midtransTransactionDom.Interface) Interface {
+func Init(log log.Interface, metrics metrics.Interface, auth auth.Interface, td transactionDom.Interface, cd cartDom.Interface, pd productDom.Interface, md midtransDom.Interface, mtd midtransTransactionDom.Interface, ad addressDom.Interface, od outboxDom.Interface, ssd statusStreamDom.Interface, shd shipmentDom.Interface) Interface {
 	t := &transaction{
+		log:                 log,
+		metrics:             metrics,
 		auth:                auth,
 		cart:                cd,
 		product:             pd,
 		transaction:         td,
 		midtrans:            md,
 		midtransTransaction: mtd,
+		address:             ad,
+		outbox:              od,
+		statusStream:        ssd,
+		shipment:            shd,
 	}
 
 	return t
@@ -51,7 +76,7 @@ func (t *transaction) Create(ctx context.Context, createParam entity.CreateTrans
 		return entity.Transaction{}, err
 	}
 
-	carts, err := t.cart.GetList(entity.CartParam{
+	carts, err := t.cart.GetList(ctx, entity.CartParam{
 		UserID: user.User.ID,
 		Status: entity.StatusInCart,
 	})
@@ -60,7 +85,7 @@ func (t *transaction) Create(ctx context.Context, createParam entity.CreateTrans
 	}
 
 	if len(carts) == 0 {
-		return entity.Transaction{}, errors.New("cart is empty")
+		return entity.Transaction{}, apperror.Validation("cart is empty").WithKey("transaction.cart_empty")
 	}
 
 	productIDs := []uint{}
@@ -83,70 +108,122 @@ func (t *transaction) Create(ctx context.Context, createParam entity.CreateTrans
 		totalPrice += int64(c.Qty * productMap[c.ProductID].Price)
 	}
 
-	transaction, err := t.transaction.Create(entity.Transaction{
+	newTransaction := entity.Transaction{
 		UserID:      user.User.ID,
 		AddressShip: createParam.AddressShip,
 		TotalPrice:  totalPrice,
-	})
-	if err != nil {
-		return transaction, err
 	}
 
-	coreApiRes, err := t.midtrans.Create(midtrans.CreateOrderParam{
-		OrderID:      transaction.ID,
-		PaymentID:    createParam.PaymentID,
-		GrossAmount:  totalPrice,
-		ItemsDetails: t.convertToItemsDetails(carts, productMap),
-		CustomerDetails: midtrans.CustomerDetails{
-			Name: user.User.Name,
-		},
-	})
-	if err != nil {
-		return transaction, err
-	}
+	if createParam.AddressID != 0 {
+		address, err := t.address.Get(ctx, entity.AddressParam{
+			ID:     createParam.AddressID,
+			UserID: user.User.ID,
+		})
+		if err != nil {
+			return entity.Transaction{}, err
+		}
 
-	paymentData, err := t.getPaymentData(createParam.PaymentID, coreApiRes)
-	if err != nil {
-		return transaction, err
+		newTransaction.ShippingAddress = address.ToShippingAddress()
+		newTransaction.AddressShip = newTransaction.ShippingAddress.String()
 	}
 
-	paymenDataMarshal, err := json.Marshal(paymentData)
-	if err != nil {
-		return transaction, err
-	}
+	if createParam.Courier != "" {
+		quote, err := t.shippingQuote(ctx, createParam, entity.CartWeight(carts, productMap), newTransaction.ShippingAddress.PostalCode)
+		if err != nil {
+			return entity.Transaction{}, err
+		}
 
-	if err := t.cart.Update(entity.CartParam{
-		Status: entity.StatusInCart,
-		UserID: user.User.ID,
-	}, entity.UpdateCartParam{
-		Status:        entity.StatusUnpaid,
-		TransactionID: transaction.ID,
-	}); err != nil {
-		return transaction, err
+		totalPrice += quote.Cost
+		newTransaction.ShippingCourier = quote.Courier
+		newTransaction.ShippingService = quote.Service
+		newTransaction.ShippingCost = quote.Cost
+		newTransaction.TotalPrice = totalPrice
 	}
 
-	_, err = t.midtransTransaction.Create(entity.MidtransTransaction{
-		TransactionID: transaction.ID,
-		MidtransID:    coreApiRes.TransactionID,
-		OrderID:       coreApiRes.OrderID,
-		PaymentType:   createParam.PaymentID,
-		Status:        entity.StatusPending,
-		PaymentData:   string(paymenDataMarshal),
+	// the order row is written in the same transaction as its carts and
+	// event, a failed charge leaves no order behind
+	transaction := entity.Transaction{}
+	err = t.outbox.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		transaction, err = t.transaction.Create(ctx, newTransaction)
+		if err != nil {
+			return err
+		}
+
+		coreApiRes, err := t.midtrans.Create(ctx, midtrans.CreateOrderParam{
+			OrderID:      transaction.ID,
+			PaymentID:    createParam.PaymentID,
+			GrossAmount:  totalPrice,
+			ItemsDetails: t.convertToItemsDetails(carts, productMap, transaction),
+			CustomerDetails: midtrans.CustomerDetails{
+				Name:  user.User.Name,
+				Email: user.User.Email,
+			},
+		})
+		if err != nil {
+			return apperror.PaymentFailed("failed to create the payment", err).WithKey("payment.create_failed")
+		}
+
+		paymentData, err := t.getPaymentData(createParam.PaymentID, coreApiRes)
+		if err != nil {
+			return err
+		}
+
+		paymenDataMarshal, err := json.Marshal(paymentData)
+		if err != nil {
+			return err
+		}
+
+		if err := t.cart.Update(ctx, entity.CartParam{
+			Status: entity.StatusInCart,
+			UserID: user.User.ID,
+		}, entity.UpdateCartParam{
+			Status:        entity.StatusUnpaid,
+			TransactionID: transaction.ID,
+		}); err != nil {
+			return err
+		}
+
+		if _, err := t.midtransTransaction.Create(ctx, entity.MidtransTransaction{
+			TransactionID: transaction.ID,
+			MidtransID:    coreApiRes.TransactionID,
+			OrderID:       coreApiRes.OrderID,
+			PaymentType:   createParam.PaymentID,
+			Status:        entity.StatusPending,
+			PaymentData:   string(paymenDataMarshal),
+		}); err != nil {
+			return err
+		}
+
+		event, err := entity.NewOutboxEvent(entity.EventOrderCreated, transaction.ID, entity.OrderCreatedPayload{
+			TransactionID: transaction.ID,
+			UserID:        user.User.ID,
+			TotalPrice:    totalPrice,
+			PaymentType:   createParam.PaymentID,
+			OrderID:       coreApiRes.OrderID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return t.outbox.Add(ctx, event)
 	})
 	if err != nil {
 		return transaction, err
 	}
 
 	for _, c := range carts {
-		if err := t.cart.Update(entity.CartParam{
+		if err := t.cart.Update(ctx, entity.CartParam{
 			ID: c.ID,
 		}, entity.UpdateCartParam{
 			FinalPricePerItem: productMap[c.ProductID].Price,
 		}); err != nil {
-			log.Printf("failed to set final price id %d", c.ID)
+			t.log.Error(ctx, "failed to set final price", "cart_id", c.ID, "error", err)
 		}
 	}
 
+	t.metrics.IncOrdersCreated()
+
 	return transaction, nil
 }
 
@@ -156,13 +233,24 @@ func (t *transaction) getPaymentData(paymentId int, coreApiRes *coreapi.ChargeRe
 		paymentData.Key = coreApiRes.Actions[1].URL
 		paymentData.Qr = coreApiRes.Actions[0].URL
 	} else {
-		return paymentData, errors.New("failed to get payment data")
+		return paymentData, apperror.New(apperror.CodePaymentFailed, "payment type is not supported").WithKey("payment.type_unsupported")
 	}
 
 	return paymentData, nil
 }
 
-func (t *transaction) convertToItemsDetails(carts []entity.Cart, products map[uint]entity.Product) []midtrans.ItemsDetails {
+// shippingQuote is the quote of the courier service picked at checkout.
+func (t *transaction) shippingQuote(ctx context.Context, createParam entity.CreateTransactionParam, weight int, destination string) (entity.ShippingQuote, error) {
+	for _, q := range t.shipment.Quote(ctx, weight, destination) {
+		if q.Courier == createParam.Courier && q.Service == createParam.Service {
+			return q, nil
+		}
+	}
+
+	return entity.ShippingQuote{}, apperror.Validation("courier service is not available").WithKey("shipping.service_unavailable", createParam.Courier, createParam.Service)
+}
+
+func (t *transaction) convertToItemsDetails(carts []entity.Cart, products map[uint]entity.Product, transaction entity.Transaction) []midtrans.ItemsDetails {
 	res := []midtrans.ItemsDetails{}
 	for _, c := range carts {
 		resTemp := midtrans.ItemsDetails{
@@ -174,15 +262,25 @@ func (t *transaction) convertToItemsDetails(carts []entity.Cart, products map[ui
 		res = append(res, resTemp)
 	}
 
+	// midtrans checks the gross amount against the sum of the items
+	if transaction.ShippingCost > 0 {
+		res = append(res, midtrans.ItemsDetails{
+			ID:    "shipping",
+			Price: transaction.ShippingCost,
+			Qty:   1,
+			Name:  fmt.Sprintf("Shipping %s %s", transaction.ShippingCourier, transaction.ShippingService),
+		})
+	}
+
 	return res
 }
 
 func (t *transaction) ValidateTransaction(ctx context.Context, transactionID uint, user auth.UserAuthInfo) error {
 	if transactionID == 0 {
-		return errors.New("please provide transaction id")
+		return apperror.Validation("please provide transaction id").WithKey("transaction.id_required")
 	}
 
-	transaction, err := t.transaction.Get(entity.TransactionParam{
+	transaction, err := t.transaction.Get(ctx, entity.TransactionParam{
 		ID: transactionID,
 	})
 	if err != nil {
@@ -190,8 +288,153 @@ func (t *transaction) ValidateTransaction(ctx context.Context, transactionID uin
 	}
 
 	if transaction.UserID != user.User.ID {
-		return errors.New("unauthorized")
+		return apperror.Forbidden("transaction does not belong to the user").WithKey("transaction.not_owned")
 	}
 
 	return nil
 }
+
+func (t *transaction) Cancel(ctx context.Context, param entity.CancelTransactionParam) error {
+	user, err := t.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	midtransTransaction, err := t.midtransTransaction.Get(ctx, entity.MidtransTransactionParam{
+		TransactionID: param.TransactionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if midtransTransaction.Status != entity.StatusPending {
+		return apperror.Conflict("only orders waiting for payment can be cancelled").WithKey("transaction.not_cancellable")
+	}
+
+	carts, err := t.cart.GetList(ctx, entity.CartParam{
+		TransactionID: param.TransactionID,
+		Status:        entity.StatusUnpaid,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the gateway refuses to cancel a charge that was paid in the meantime
+	if _, err := t.midtrans.Cancel(ctx, midtransTransaction.OrderID); err != nil {
+		return apperror.PaymentFailed("failed to cancel the payment", err).WithKey("payment.cancel_failed")
+	}
+
+	err = t.outbox.WithTx(ctx, func(ctx context.Context) error {
+		// a payment notification or another cancel may have moved the status
+		// since it was read, the carts are only touched by the one that wins
+		cancelled, err := t.midtransTransaction.UpdateStatusFrom(ctx, midtransTransaction.ID, entity.StatusPending, entity.StatusCancelled)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return apperror.Conflict("only orders waiting for payment can be cancelled").WithKey("transaction.not_cancellable")
+		}
+
+		if err := t.cart.Update(ctx, entity.CartParam{
+			TransactionID: param.TransactionID,
+			Status:        entity.StatusUnpaid,
+		}, entity.UpdateCartParam{
+			Status: entity.StatusCancelled,
+		}); err != nil {
+			return err
+		}
+
+		if param.RestoreCart {
+			if err := t.restoreCart(ctx, user.User.ID, carts); err != nil {
+				return err
+			}
+		}
+
+		event, err := entity.NewOutboxEvent(entity.EventOrderCancelled, param.TransactionID, entity.OrderCancelledPayload{
+			TransactionID: param.TransactionID,
+			UserID:        user.User.ID,
+			OrderID:       midtransTransaction.OrderID,
+			RestoredCart:  param.RestoreCart,
+		})
+		if err != nil {
+			return err
+		}
+
+		return t.outbox.Add(ctx, event)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := t.statusStream.Publish(ctx, entity.TransactionStatusUpdate{
+		TransactionID: param.TransactionID,
+		UserID:        user.User.ID,
+		OrderID:       midtransTransaction.OrderID,
+		Status:        entity.StatusCancelled,
+		UpdatedAt:     time.Now(),
+	}); err != nil {
+		t.log.Error(ctx, "failed to publish transaction status", "transaction_id", param.TransactionID, "error", err)
+	}
+
+	return nil
+}
+
+// restoreCart puts the items of a cancelled order back into the cart, adding
+// to the quantity of a product that is already in it. A quantity is capped at
+// entity.MaxCartQty like one added to the cart.
+func (t *transaction) restoreCart(ctx context.Context, userID uint, carts []entity.Cart) error {
+	for _, c := range carts {
+		inCart, err := t.cart.GetList(ctx, entity.CartParam{
+			UserID:    userID,
+			ProductID: c.ProductID,
+			Status:    entity.StatusInCart,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(inCart) > 0 {
+			qty := capCartQty(inCart[0].Qty + c.Qty)
+			if qty <= inCart[0].Qty {
+				continue
+			}
+
+			if err := t.cart.Update(ctx, entity.CartParam{
+				ID: inCart[0].ID,
+			}, entity.UpdateCartParam{
+				Qty: qty,
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := t.cart.Create(ctx, entity.Cart{
+			UserID:    userID,
+			ProductID: c.ProductID,
+			Qty:       capCartQty(c.Qty),
+			Status:    entity.StatusInCart,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func capCartQty(qty int) int {
+	if qty > entity.MaxCartQty {
+		return entity.MaxCartQty
+	}
+
+	return qty
+}
+
+func (t *transaction) StreamStatus(ctx context.Context) (<-chan entity.TransactionStatusUpdate, error) {
+	user, err := t.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.statusStream.Subscribe(ctx, user.User.ID), nil
+}
@@ -3,14 +3,20 @@ package transaction_test
 import (
 	"context"
 	"encoding/json"
+	mock_address "go-clean/src/business/domain/mock/address"
 	mock_cart "go-clean/src/business/domain/mock/cart"
 	mock_midtrans "go-clean/src/business/domain/mock/midtrans"
 	mock_midtrans_transaction "go-clean/src/business/domain/mock/midtrans_transaction"
+	mock_outbox "go-clean/src/business/domain/mock/outbox"
 	mock_product "go-clean/src/business/domain/mock/product"
+	mock_shipment "go-clean/src/business/domain/mock/shipment"
+	mock_statusstream "go-clean/src/business/domain/mock/status_stream"
 	mock_transaction "go-clean/src/business/domain/mock/transaction"
 	"go-clean/src/business/entity"
 	"go-clean/src/business/usecase/transaction"
 	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"go-clean/src/lib/midtrans"
 	mock_auth "go-clean/src/lib/tests/mock/auth"
 	"testing"
@@ -31,8 +37,11 @@ func Test_transaction_Create(t *testing.T) {
 	midtransMock := mock_midtrans.NewMockInterface(ctrl)
 	transactionMock := mock_transaction.NewMockInterface(ctrl)
 	midtransTransactionMock := mock_midtrans_transaction.NewMockInterface(ctrl)
+	addressMock := mock_address.NewMockInterface(ctrl)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+	shipmentMock := mock_shipment.NewMockInterface(ctrl)
 
-	tr := transaction.Init(authMock, transactionMock, cartMock, productMock, midtransMock, midtransTransactionMock)
+	tr := transaction.Init(log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), authMock, transactionMock, cartMock, productMock, midtransMock, midtransTransactionMock, addressMock, outboxMock, nil, shipmentMock)
 
 	userAuthMock := auth.UserAuthInfo{
 		User: auth.User{
@@ -46,6 +55,39 @@ func Test_transaction_Create(t *testing.T) {
 		PaymentID:   1,
 	}
 
+	paramsWithAddressMock := entity.CreateTransactionParam{
+		AddressID: 1,
+		PaymentID: 1,
+	}
+
+	addressParamMock := entity.AddressParam{
+		ID:     1,
+		UserID: 1,
+	}
+
+	addressResultMock := entity.Address{
+		Model: gorm.Model{
+			ID: 1,
+		},
+		UserID:     1,
+		Recipient:  "mail",
+		Phone:      "0812",
+		Street:     "jl. sudirman",
+		City:       "purwakarta",
+		Province:   "jawa barat",
+		PostalCode: "41111",
+	}
+
+	newTransactionWithAddressMock := entity.Transaction{
+		UserID:          1,
+		AddressShip:     "mail (0812), jl. sudirman, purwakarta, jawa barat, 41111",
+		ShippingAddress: addressResultMock.ToShippingAddress(),
+		TotalPrice:      10000,
+	}
+
+	transactionWithAddressResultMock := newTransactionWithAddressMock
+	transactionWithAddressResultMock.ID = 1
+
 	paramsMockUndifinedPaymentMock := entity.CreateTransactionParam{
 		AddressShip: "purwakarta",
 		PaymentID:   999,
@@ -163,6 +205,36 @@ func Test_transaction_Create(t *testing.T) {
 		TransactionID: 1,
 	}
 
+	paramsWithShippingMock := entity.CreateTransactionParam{
+		AddressShip: "purwakarta",
+		PaymentID:   1,
+		Courier:     "jne",
+		Service:     "REG",
+	}
+
+	quotesMock := []entity.ShippingQuote{
+		{Courier: "jne", Service: "REG", Cost: 9000},
+		{Courier: "jne", Service: "YES", Cost: 18000},
+	}
+
+	newTransactionWithShippingMock := newTransactionMock
+	newTransactionWithShippingMock.ShippingCourier = "jne"
+	newTransactionWithShippingMock.ShippingService = "REG"
+	newTransactionWithShippingMock.ShippingCost = 9000
+	newTransactionWithShippingMock.TotalPrice = 19000
+
+	transactionWithShippingResultMock := newTransactionWithShippingMock
+	transactionWithShippingResultMock.ID = 1
+
+	midtransCreateParamWithShippingMock := midtransCreateParamMock
+	midtransCreateParamWithShippingMock.GrossAmount = 19000
+	midtransCreateParamWithShippingMock.ItemsDetails = append(midtransCreateParamMock.ItemsDetails, midtrans.ItemsDetails{
+		ID:    "shipping",
+		Price: 9000,
+		Qty:   1,
+		Name:  "Shipping jne REG",
+	})
+
 	selectParamCartFinalPrice := entity.CartParam{
 		ID: 1,
 	}
@@ -178,6 +250,9 @@ func Test_transaction_Create(t *testing.T) {
 		midtrans             *mock_midtrans.MockInterface
 		transaction          *mock_transaction.MockInterface
 		midtrans_transaction *mock_midtrans_transaction.MockInterface
+		address              *mock_address.MockInterface
+		outbox               *mock_outbox.MockInterface
+		shipment             *mock_shipment.MockInterface
 	}
 
 	mocks := mockfields{
@@ -187,6 +262,13 @@ func Test_transaction_Create(t *testing.T) {
 		midtrans:             midtransMock,
 		transaction:          transactionMock,
 		midtrans_transaction: midtransTransactionMock,
+		address:              addressMock,
+		outbox:               outboxMock,
+		shipment:             shipmentMock,
+	}
+
+	withTx := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
 	}
 
 	type args struct {
@@ -217,7 +299,7 @@ func Test_transaction_Create(t *testing.T) {
 			name: "failed get cart list",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return([]entity.Cart{}, assert.AnError)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return([]entity.Cart{}, assert.AnError)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -230,7 +312,7 @@ func Test_transaction_Create(t *testing.T) {
 			name: "cart empty",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return([]entity.Cart{}, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return([]entity.Cart{}, nil)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -243,7 +325,7 @@ func Test_transaction_Create(t *testing.T) {
 			name: "failed to get products list",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return([]entity.Product{}, assert.AnError)
 			},
 			args: args{
@@ -253,13 +335,29 @@ func Test_transaction_Create(t *testing.T) {
 			want:    entity.Transaction{},
 			wantErr: true,
 		},
+		{
+			name: "failed to get address",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
+				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
+				mock.address.EXPECT().Get(context.Background(), addressParamMock).Return(entity.Address{}, assert.AnError)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: paramsWithAddressMock,
+			},
+			want:    entity.Transaction{},
+			wantErr: true,
+		},
 		{
 			name: "failed to create transaction",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
-				mock.transaction.EXPECT().Create(newTransactionMock).Return(transactionResultMock, assert.AnError)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionMock).Return(transactionResultMock, assert.AnError)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -272,10 +370,11 @@ func Test_transaction_Create(t *testing.T) {
 			name: "failed to create midtrans",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
-				mock.transaction.EXPECT().Create(newTransactionMock).Return(transactionResultMock, nil)
-				mock.midtrans.EXPECT().Create(midtransCreateParamMock).Return(nil, assert.AnError)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionMock).Return(transactionResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamMock).Return(nil, assert.AnError)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -288,11 +387,12 @@ func Test_transaction_Create(t *testing.T) {
 			name: "failed to update cart",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
-				mock.transaction.EXPECT().Create(newTransactionMock).Return(transactionResultMock, nil)
-				mock.midtrans.EXPECT().Create(midtransCreateParamMock).Return(midtransResultMock, nil)
-				mock.cart.EXPECT().Update(selectParamCartMock, updateParamCartMock).Return(assert.AnError)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionMock).Return(transactionResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamMock).Return(midtransResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartMock, updateParamCartMock).Return(assert.AnError)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -305,10 +405,11 @@ func Test_transaction_Create(t *testing.T) {
 			name: "failed to get payment data",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
-				mock.transaction.EXPECT().Create(newTransactionMock).Return(transactionResultMock, nil)
-				mock.midtrans.EXPECT().Create(midtransCreateParamUndifinedMock).Return(midtransResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionMock).Return(transactionResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamUndifinedMock).Return(midtransResultMock, nil)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -321,12 +422,33 @@ func Test_transaction_Create(t *testing.T) {
 			name: "failed to create midtrans transaction",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
-				mock.transaction.EXPECT().Create(newTransactionMock).Return(transactionResultMock, nil)
-				mock.midtrans.EXPECT().Create(midtransCreateParamMock).Return(midtransResultMock, nil)
-				mock.cart.EXPECT().Update(selectParamCartMock, updateParamCartMock).Return(nil)
-				mock.midtrans_transaction.EXPECT().Create(newMidtransTransactionMock).Return(entity.MidtransTransaction{}, assert.AnError)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionMock).Return(transactionResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamMock).Return(midtransResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartMock, updateParamCartMock).Return(nil)
+				mock.midtrans_transaction.EXPECT().Create(context.Background(), newMidtransTransactionMock).Return(entity.MidtransTransaction{}, assert.AnError)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: paramsMock,
+			},
+			want:    transactionResultMock,
+			wantErr: true,
+		},
+		{
+			name: "failed to add order created event",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
+				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionMock).Return(transactionResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamMock).Return(midtransResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartMock, updateParamCartMock).Return(nil)
+				mock.midtrans_transaction.EXPECT().Create(context.Background(), newMidtransTransactionMock).Return(entity.MidtransTransaction{}, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(assert.AnError)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -339,13 +461,15 @@ func Test_transaction_Create(t *testing.T) {
 			name: "failed to update cart to set final price",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
-				mock.transaction.EXPECT().Create(newTransactionMock).Return(transactionResultMock, nil)
-				mock.midtrans.EXPECT().Create(midtransCreateParamMock).Return(midtransResultMock, nil)
-				mock.cart.EXPECT().Update(selectParamCartMock, updateParamCartMock).Return(nil)
-				mock.midtrans_transaction.EXPECT().Create(newMidtransTransactionMock).Return(entity.MidtransTransaction{}, nil)
-				mock.cart.EXPECT().Update(selectParamCartFinalPrice, updateParamCartFinalPrice).Return(assert.AnError)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionMock).Return(transactionResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamMock).Return(midtransResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartMock, updateParamCartMock).Return(nil)
+				mock.midtrans_transaction.EXPECT().Create(context.Background(), newMidtransTransactionMock).Return(entity.MidtransTransaction{}, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartFinalPrice, updateParamCartFinalPrice).Return(assert.AnError)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -358,13 +482,15 @@ func Test_transaction_Create(t *testing.T) {
 			name: "all success",
 			mockFunc: func(mock mockfields, arg args) {
 				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
-				mock.cart.EXPECT().GetList(cartParamMock).Return(cartResultMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
 				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
-				mock.transaction.EXPECT().Create(newTransactionMock).Return(transactionResultMock, nil)
-				mock.midtrans.EXPECT().Create(midtransCreateParamMock).Return(midtransResultMock, nil)
-				mock.cart.EXPECT().Update(selectParamCartMock, updateParamCartMock).Return(nil)
-				mock.midtrans_transaction.EXPECT().Create(newMidtransTransactionMock).Return(entity.MidtransTransaction{}, nil)
-				mock.cart.EXPECT().Update(selectParamCartFinalPrice, updateParamCartFinalPrice).Return(nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionMock).Return(transactionResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamMock).Return(midtransResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartMock, updateParamCartMock).Return(nil)
+				mock.midtrans_transaction.EXPECT().Create(context.Background(), newMidtransTransactionMock).Return(entity.MidtransTransaction{}, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartFinalPrice, updateParamCartFinalPrice).Return(nil)
 			},
 			args: args{
 				ctx:   context.Background(),
@@ -373,6 +499,65 @@ func Test_transaction_Create(t *testing.T) {
 			want:    transactionResultMock,
 			wantErr: false,
 		},
+		{
+			name: "all success with address from address book",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
+				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
+				mock.address.EXPECT().Get(context.Background(), addressParamMock).Return(addressResultMock, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionWithAddressMock).Return(transactionWithAddressResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamMock).Return(midtransResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartMock, updateParamCartMock).Return(nil)
+				mock.midtrans_transaction.EXPECT().Create(context.Background(), newMidtransTransactionMock).Return(entity.MidtransTransaction{}, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartFinalPrice, updateParamCartFinalPrice).Return(nil)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: paramsWithAddressMock,
+			},
+			want:    transactionWithAddressResultMock,
+			wantErr: false,
+		},
+		{
+			name: "courier service not available",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
+				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
+				mock.shipment.EXPECT().Quote(context.Background(), 0, "").Return(quotesMock[1:])
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: paramsWithShippingMock,
+			},
+			want:    entity.Transaction{},
+			wantErr: true,
+		},
+		{
+			name: "all success with shipping",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), cartParamMock).Return(cartResultMock, nil)
+				mock.product.EXPECT().GetListByID(context.Background(), []uint{1}).Return(productResultMock, nil)
+				mock.shipment.EXPECT().Quote(context.Background(), 0, "").Return(quotesMock)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.transaction.EXPECT().Create(context.Background(), newTransactionWithShippingMock).Return(transactionWithShippingResultMock, nil)
+				mock.midtrans.EXPECT().Create(context.Background(), midtransCreateParamWithShippingMock).Return(midtransResultMock, nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartMock, updateParamCartMock).Return(nil)
+				mock.midtrans_transaction.EXPECT().Create(context.Background(), newMidtransTransactionMock).Return(entity.MidtransTransaction{}, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(nil)
+				mock.cart.EXPECT().Update(context.Background(), selectParamCartFinalPrice, updateParamCartFinalPrice).Return(nil)
+			},
+			args: args{
+				ctx:   context.Background(),
+				param: paramsWithShippingMock,
+			},
+			want:    transactionWithShippingResultMock,
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -393,7 +578,7 @@ func Test_transaction_ValidateTransaction(t *testing.T) {
 
 	transactionMock := mock_transaction.NewMockInterface(ctrl)
 
-	tr := transaction.Init(nil, transactionMock, nil, nil, nil, nil)
+	tr := transaction.Init(log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), nil, transactionMock, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	authUserMock := auth.UserAuthInfo{
 		User: auth.User{
@@ -452,7 +637,7 @@ func Test_transaction_ValidateTransaction(t *testing.T) {
 				user:          authUserMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.transaction.EXPECT().Get(transactionParamMock).Return(entity.Transaction{}, assert.AnError)
+				mock.transaction.EXPECT().Get(context.Background(), transactionParamMock).Return(entity.Transaction{}, assert.AnError)
 			},
 			wantErr: true,
 		},
@@ -464,7 +649,7 @@ func Test_transaction_ValidateTransaction(t *testing.T) {
 				user:          authUserFailedMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.transaction.EXPECT().Get(transactionParamMock).Return(transactionResultMock, nil)
+				mock.transaction.EXPECT().Get(context.Background(), transactionParamMock).Return(transactionResultMock, nil)
 			},
 			wantErr: true,
 		},
@@ -476,7 +661,7 @@ func Test_transaction_ValidateTransaction(t *testing.T) {
 				user:          authUserMock,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.transaction.EXPECT().Get(transactionParamMock).Return(transactionResultMock, nil)
+				mock.transaction.EXPECT().Get(context.Background(), transactionParamMock).Return(transactionResultMock, nil)
 			},
 			wantErr: false,
 		},
@@ -492,3 +677,220 @@ func Test_transaction_ValidateTransaction(t *testing.T) {
 		})
 	}
 }
+
+func Test_transaction_Cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	cartMock := mock_cart.NewMockInterface(ctrl)
+	midtransMock := mock_midtrans.NewMockInterface(ctrl)
+	midtransTransactionMock := mock_midtrans_transaction.NewMockInterface(ctrl)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
+	statusStreamMock := mock_statusstream.NewMockInterface(ctrl)
+
+	tr := transaction.Init(log.Init(log.Config{Level: "disabled"}), metrics.Init(metrics.Config{}), authMock, nil, cartMock, nil, midtransMock, midtransTransactionMock, nil, outboxMock, statusStreamMock, nil)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	midtransTransactionParamMock := entity.MidtransTransactionParam{
+		TransactionID: 1,
+	}
+
+	pendingMock := entity.MidtransTransaction{
+		Model: gorm.Model{
+			ID: 2,
+		},
+		TransactionID: 1,
+		OrderID:       "SYN-1",
+		Status:        entity.StatusPending,
+	}
+
+	settledMock := pendingMock
+	settledMock.Status = entity.StatusSuccess
+
+	unpaidCartParamMock := entity.CartParam{
+		TransactionID: 1,
+		Status:        entity.StatusUnpaid,
+	}
+
+	cartsMock := []entity.Cart{
+		{
+			Model: gorm.Model{
+				ID: 1,
+			},
+			UserID:    1,
+			ProductID: 1,
+			Qty:       2,
+		},
+		{
+			Model: gorm.Model{
+				ID: 2,
+			},
+			UserID:    1,
+			ProductID: 2,
+			Qty:       1,
+		},
+	}
+
+	withTx := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}
+
+	type mockfields struct {
+		auth                *mock_auth.MockInterface
+		cart                *mock_cart.MockInterface
+		midtrans            *mock_midtrans.MockInterface
+		midtransTransaction *mock_midtrans_transaction.MockInterface
+		outbox              *mock_outbox.MockInterface
+		statusStream        *mock_statusstream.MockInterface
+	}
+
+	mocks := mockfields{
+		auth:                authMock,
+		cart:                cartMock,
+		midtrans:            midtransMock,
+		midtransTransaction: midtransTransactionMock,
+		outbox:              outboxMock,
+		statusStream:        statusStreamMock,
+	}
+
+	cancelled := func(mock mockfields) {
+		mock.midtransTransaction.EXPECT().UpdateStatusFrom(context.Background(), uint(2), entity.StatusPending, entity.StatusCancelled).Return(true, nil)
+		mock.cart.EXPECT().Update(context.Background(), unpaidCartParamMock, entity.UpdateCartParam{Status: entity.StatusCancelled}).Return(nil)
+	}
+
+	tests := []struct {
+		name     string
+		param    entity.CancelTransactionParam
+		mockFunc func(mock mockfields)
+		wantErr  bool
+	}{
+		{
+			name:  "failed to get auth user",
+			param: entity.CancelTransactionParam{TransactionID: 1},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "order already paid",
+			param: entity.CancelTransactionParam{TransactionID: 1},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(settledMock, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "failed to cancel the payment",
+			param: entity.CancelTransactionParam{TransactionID: 1},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(pendingMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), unpaidCartParamMock).Return(cartsMock, nil)
+				mock.midtrans.EXPECT().Cancel(context.Background(), "SYN-1").Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "order paid while cancelling",
+			param: entity.CancelTransactionParam{TransactionID: 1, RestoreCart: true},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(pendingMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), unpaidCartParamMock).Return(cartsMock, nil)
+				mock.midtrans.EXPECT().Cancel(context.Background(), "SYN-1").Return(&coreapi.CancelResponse{}, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.midtransTransaction.EXPECT().UpdateStatusFrom(context.Background(), uint(2), entity.StatusPending, entity.StatusCancelled).Return(false, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "failed to add event",
+			param: entity.CancelTransactionParam{TransactionID: 1},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(pendingMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), unpaidCartParamMock).Return(cartsMock, nil)
+				mock.midtrans.EXPECT().Cancel(context.Background(), "SYN-1").Return(&coreapi.CancelResponse{}, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				cancelled(mock)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "all ok",
+			param: entity.CancelTransactionParam{TransactionID: 1},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(pendingMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), unpaidCartParamMock).Return(cartsMock, nil)
+				mock.midtrans.EXPECT().Cancel(context.Background(), "SYN-1").Return(&coreapi.CancelResponse{}, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				cancelled(mock)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(func(ctx context.Context, events ...entity.OutboxEvent) error {
+					assert.Equal(t, entity.EventOrderCancelled, events[0].Type)
+					return nil
+				})
+				mock.statusStream.EXPECT().Publish(context.Background(), gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:  "all ok restoring the cart",
+			param: entity.CancelTransactionParam{TransactionID: 1, RestoreCart: true},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(pendingMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), unpaidCartParamMock).Return(cartsMock, nil)
+				mock.midtrans.EXPECT().Cancel(context.Background(), "SYN-1").Return(&coreapi.CancelResponse{}, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				cancelled(mock)
+				// product 1 is in the cart again, product 2 is not
+				mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{UserID: 1, ProductID: 1, Status: entity.StatusInCart}).Return([]entity.Cart{{Model: gorm.Model{ID: 3}, Qty: 1}}, nil)
+				mock.cart.EXPECT().Update(context.Background(), entity.CartParam{ID: 3}, entity.UpdateCartParam{Qty: 3}).Return(nil)
+				mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{UserID: 1, ProductID: 2, Status: entity.StatusInCart}).Return([]entity.Cart{}, nil)
+				mock.cart.EXPECT().Create(context.Background(), entity.Cart{UserID: 1, ProductID: 2, Qty: 1, Status: entity.StatusInCart}).Return(entity.Cart{}, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(nil)
+				mock.statusStream.EXPECT().Publish(context.Background(), gomock.Any()).Return(assert.AnError)
+			},
+			wantErr: false,
+		},
+		{
+			name:  "all ok restoring the cart caps the quantity",
+			param: entity.CancelTransactionParam{TransactionID: 1, RestoreCart: true},
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.midtransTransaction.EXPECT().Get(context.Background(), midtransTransactionParamMock).Return(pendingMock, nil)
+				mock.cart.EXPECT().GetList(context.Background(), unpaidCartParamMock).Return(cartsMock, nil)
+				mock.midtrans.EXPECT().Cancel(context.Background(), "SYN-1").Return(&coreapi.CancelResponse{}, nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				cancelled(mock)
+				// product 1 is capped, product 2 is at the cap already
+				mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{UserID: 1, ProductID: 1, Status: entity.StatusInCart}).Return([]entity.Cart{{Model: gorm.Model{ID: 3}, Qty: 99}}, nil)
+				mock.cart.EXPECT().Update(context.Background(), entity.CartParam{ID: 3}, entity.UpdateCartParam{Qty: entity.MaxCartQty}).Return(nil)
+				mock.cart.EXPECT().GetList(context.Background(), entity.CartParam{UserID: 1, ProductID: 2, Status: entity.StatusInCart}).Return([]entity.Cart{{Model: gorm.Model{ID: 4}, Qty: entity.MaxCartQty}}, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(nil)
+				mock.statusStream.EXPECT().Publish(context.Background(), gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			err := tr.Cancel(context.Background(), tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("transaction.Cancel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
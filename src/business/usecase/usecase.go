@@ -2,13 +2,24 @@ package usecase
 
 import (
 	"go-clean/src/business/domain"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/address"
+	"go-clean/src/business/usecase/cache"
 	"go-clean/src/business/usecase/cart"
 	"go-clean/src/business/usecase/category"
+	"go-clean/src/business/usecase/event"
+	"go-clean/src/business/usecase/invoice"
 	midtranstransaction "go-clean/src/business/usecase/midtrans_transaction"
 	"go-clean/src/business/usecase/product"
+	"go-clean/src/business/usecase/refund"
+	"go-clean/src/business/usecase/report"
+	"go-clean/src/business/usecase/shipment"
 	"go-clean/src/business/usecase/transaction"
 	"go-clean/src/business/usecase/user"
+	"go-clean/src/business/usecase/webhook"
 	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 )
 
 type Usecase struct {
@@ -18,16 +29,42 @@ type Usecase struct {
 	Cart                cart.Interface
 	Transaction         transaction.Interface
 	MidtransTransaction midtranstransaction.Interface
+	Address             address.Interface
+	Cache               cache.Interface
+	Event               event.Interface
+	Webhook             webhook.Interface
+	Refund              refund.Interface
+	Shipment            shipment.Interface
+	Invoice             invoice.Interface
+	Report              report.Interface
 }
 
-func Init(auth auth.Interface, d *domain.Domains) *Usecase {
+type Config struct {
+	Event   event.Config
+	Webhook webhook.Config
+	Invoice invoice.Config
+}
+
+func Init(cfg Config, log log.Interface, metrics metrics.Interface, auth auth.Interface, d *domain.Domains) *Usecase {
 	uc := &Usecase{
-		User:                user.Init(d.User, auth),
+		User:                user.Init(log, d.User, d.LoginAttempt, auth, d.Outbox),
 		Category:            category.Init(d.Category),
 		Product:             product.Init(d.Product),
 		Cart:                cart.Init(d.Cart, auth, d.Product),
-		Transaction:         transaction.Init(auth, d.Transaction, d.Cart, d.Product, d.Midtrans, d.MidtransTransaction),
-		MidtransTransaction: midtranstransaction.Init(d.MidtransTransaction, d.Midtrans, d.Cart),
+		Transaction:         transaction.Init(log, metrics, auth, d.Transaction, d.Cart, d.Product, d.Midtrans, d.MidtransTransaction, d.Address, d.Outbox, d.StatusStream, d.Shipment),
+		MidtransTransaction: midtranstransaction.Init(log, metrics, d.MidtransTransaction, d.Midtrans, d.Cart, d.Outbox, d.Transaction, d.StatusStream, d.Refund),
+		Address:             address.Init(d.Address, auth),
+		Cache:               cache.Init(log, auth, d.Cache),
+		Event:               event.Init(cfg.Event, log, d.Outbox, d.EventStream),
+		Webhook:             webhook.Init(cfg.Webhook, log, auth, d.Webhook),
+		Refund:              refund.Init(log, auth, d.Refund, d.Midtrans, d.MidtransTransaction, d.Transaction, d.Cart, d.Outbox, d.StatusStream),
+		Shipment:            shipment.Init(log, auth, d.Shipment, d.Transaction, d.MidtransTransaction, d.Cart, d.Product, d.Address, d.Outbox),
+		Invoice:             invoice.Init(cfg.Invoice, log, d.Invoice, d.Transaction, d.MidtransTransaction, d.Cart, d.Product, d.User),
+		Report:              report.Init(d.Report),
+	}
+
+	for _, eventType := range entity.WebhookEventTypes {
+		uc.Event.Subscribe(eventType, uc.Webhook.Enqueue)
 	}
 
 	return uc
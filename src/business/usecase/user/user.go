@@ -1,48 +1,112 @@
 package user
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	loginAttemptDom "go-clean/src/business/domain/login_attempt"
+	outboxDom "go-clean/src/business/domain/outbox"
 	userDom "go-clean/src/business/domain/user"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
 	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type Interface interface {
-	Create(params entity.CreateUserParam) (entity.User, error)
-	Login(params entity.LoginUserParam) (string, error)
-	GetById(id uint) (entity.User, error)
+	Create(ctx context.Context, params entity.CreateUserParam) (entity.User, error)
+	Login(ctx context.Context, params entity.LoginUserParam) (entity.LoginResult, error)
+	LoginMFA(ctx context.Context, params entity.LoginMFAParam) (entity.LoginResult, error)
+	EnrollMFA(ctx context.Context) (entity.MFAEnrolment, error)
+	ConfirmMFA(ctx context.Context, param entity.MFACodeParam) (entity.MFARecoveryCodes, error)
+	RegenerateRecoveryCodes(ctx context.Context, param entity.MFACodeParam) (entity.MFARecoveryCodes, error)
+	GetById(ctx context.Context, id uint) (entity.User, error)
+	GetProfile(ctx context.Context) (entity.User, error)
+	UpdateProfile(ctx context.Context, param entity.UpdateProfileParam) (entity.User, error)
+	ChangePassword(ctx context.Context, param entity.ChangePasswordParam) error
+}
+
+const (
+	recoveryCodeCount = 10
+)
+
+// LockedError is returned by Login while the username or the client ip is
+// locked out after too many failed attempts.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
 }
 
 type user struct {
-	user userDom.Interface
-	auth auth.Interface
+	log          log.Interface
+	user         userDom.Interface
+	loginAttempt loginAttemptDom.Interface
+	auth         auth.Interface
+	outbox       outboxDom.Interface
 }
 
-func Init(ad userDom.Interface, auth auth.Interface) Interface {
+func Init(log log.Interface, ad userDom.Interface, lad loginAttemptDom.Interface, auth auth.Interface, od outboxDom.Interface) Interface {
 	a := &user{
-		user: ad,
-		auth: auth,
+		log:          log,
+		user:         ad,
+		loginAttempt: lad,
+		auth:         auth,
+		outbox:       od,
 	}
 
 	return a
 }
 
-func (a *user) Create(params entity.CreateUserParam) (entity.User, error) {
+func (a *user) Create(ctx context.Context, params entity.CreateUserParam) (entity.User, error) {
 	user := entity.User{
 		Username: params.Username,
 		Name:     params.Name,
+		Role:     entity.RoleCustomer,
 	}
 
-	hashPass, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.MinCost)
+	existing, err := a.user.Get(ctx, entity.UserParam{
+		Username: params.Username,
+	})
+	switch {
+	case err == nil && existing.ID != 0:
+		return user, apperror.Conflict("username is already taken").WithKey("user.username_taken")
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return user, err
+	}
+
+	hashPass, err := a.auth.HashPassword(params.Password)
 	if err != nil {
 		return user, err
 	}
 
-	user.Password = string(hashPass)
+	user.Password = hashPass
+
+	newUser := user
+	err = a.outbox.WithTx(ctx, func(ctx context.Context) error {
+		created, err := a.user.Create(ctx, user)
+		if err != nil {
+			return err
+		}
+		newUser = created
 
-	newUser, err := a.user.Create(user)
+		event, err := entity.NewOutboxEvent(entity.EventUserRegistered, created.ID, entity.UserRegisteredPayload{
+			UserID:   created.ID,
+			Username: created.Username,
+			Name:     created.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		return a.outbox.Add(ctx, event)
+	})
 	if err != nil {
 		return newUser, err
 	}
@@ -50,8 +114,8 @@ func (a *user) Create(params entity.CreateUserParam) (entity.User, error) {
 	return newUser, nil
 }
 
-func (a *user) GetById(id uint) (entity.User, error) {
-	user, err := a.user.Get(entity.UserParam{
+func (a *user) GetById(ctx context.Context, id uint) (entity.User, error) {
+	user, err := a.user.Get(ctx, entity.UserParam{
 		ID: id,
 	})
 	if err != nil {
@@ -61,26 +125,414 @@ func (a *user) GetById(id uint) (entity.User, error) {
 	return user, nil
 }
 
-func (a *user) Login(params entity.LoginUserParam) (string, error) {
-	user, err := a.user.Get(entity.UserParam{
-		Username: params.Username,
+func (a *user) GetProfile(ctx context.Context) (entity.User, error) {
+	userAuth, err := a.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	user, err := a.user.Get(ctx, entity.UserParam{
+		ID: userAuth.User.ID,
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (a *user) UpdateProfile(ctx context.Context, param entity.UpdateProfileParam) (entity.User, error) {
+	userAuth, err := a.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	if err := a.user.Update(ctx, entity.UserParam{
+		ID: userAuth.User.ID,
+	}, entity.UpdateUserParam{
+		Name:  param.Name,
+		Email: param.Email,
+		Phone: param.Phone,
+	}); err != nil {
+		return entity.User{}, err
+	}
+
+	user, err := a.user.Get(ctx, entity.UserParam{
+		ID: userAuth.User.ID,
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (a *user) ChangePassword(ctx context.Context, param entity.ChangePasswordParam) error {
+	userAuth, err := a.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.user.Get(ctx, entity.UserParam{
+		ID: userAuth.User.ID,
 	})
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	if err := a.auth.ComparePassword(user.Password, param.OldPassword); err != nil {
+		return apperror.Validation("old password is incorrect").WithKey("user.old_password_incorrect")
+	}
+
+	hashPass, err := a.auth.HashPassword(param.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := a.user.Update(ctx, entity.UserParam{
+		ID: user.ID,
+	}, entity.UpdateUserParam{
+		Password: hashPass,
+	}); err != nil {
+		return err
+	}
+
+	a.log.Info(ctx, "password changed", "audit", true, "user_id", user.ID)
+
+	return nil
+}
+
+func (a *user) Login(ctx context.Context, params entity.LoginUserParam) (entity.LoginResult, error) {
+	result := entity.LoginResult{}
+
+	attempt := entity.LoginAttemptParam{
+		Username:  params.Username,
+		IPAddress: params.IPAddress,
+	}
+
+	if err := a.checkLockout(ctx, attempt); err != nil {
+		return result, err
+	}
+
+	user, err := a.user.Get(ctx, entity.UserParam{
+		Username: params.Username,
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return result, err
 	}
 
 	if user.ID == 0 {
-		return "", errors.New("record not found")
+		// a bcrypt comparison is still made, otherwise the faster response
+		// tells which usernames exist
+		a.auth.CompareDummyPassword(params.Password)
+		return result, a.loginFailed(ctx, attempt)
+	}
+
+	if err := a.auth.ComparePassword(user.Password, params.Password); err != nil {
+		return result, a.loginFailed(ctx, attempt)
+	}
+
+	if a.auth.NeedRehash(user.Password) {
+		a.rehashPassword(ctx, user, params.Password)
+	}
+
+	if user.MFAEnabled {
+		challengeToken, err := a.auth.GenerateMFAChallengeToken(user.ConvertToAuthUser())
+		if err != nil {
+			return result, err
+		}
+
+		result.MFARequired = true
+		result.ChallengeToken = challengeToken
+		return result, nil
+	}
+
+	if err := a.loginAttempt.Reset(ctx, attempt); err != nil {
+		a.log.Error(ctx, "failed to reset login attempt", "error", err)
+	}
+
+	token, err := a.auth.GenerateToken(user.ConvertToAuthUser())
+	if err != nil {
+		return result, err
+	}
+
+	result.Token = token
+	return result, nil
+}
+
+// LoginMFA exchanges the challenge token from Login and a TOTP or recovery
+// code for the real token. Wrong codes count as failed login attempts.
+func (a *user) LoginMFA(ctx context.Context, params entity.LoginMFAParam) (entity.LoginResult, error) {
+	result := entity.LoginResult{}
+
+	userID, err := a.auth.ParseMFAChallengeToken(params.ChallengeToken)
+	if err != nil {
+		return result, err
+	}
+
+	user, err := a.user.Get(ctx, entity.UserParam{
+		ID: userID,
+	})
+	if err != nil {
+		return result, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(params.Password)); err != nil {
-		return "", errors.New("record not found")
+	attempt := entity.LoginAttemptParam{
+		Username:  user.Username,
+		IPAddress: params.IPAddress,
+	}
+
+	if err := a.checkLockout(ctx, attempt); err != nil {
+		return result, err
+	}
+
+	if !user.MFAEnabled {
+		return result, apperror.Conflict("mfa is not enabled").WithKey("user.mfa_not_enabled")
+	}
+
+	if !a.verifyMFACode(ctx, user, params.Code) {
+		return result, a.loginFailed(ctx, attempt)
+	}
+
+	if err := a.loginAttempt.Reset(ctx, attempt); err != nil {
+		a.log.Error(ctx, "failed to reset login attempt", "error", err)
 	}
 
 	token, err := a.auth.GenerateToken(user.ConvertToAuthUser())
 	if err != nil {
-		return "", err
+		return result, err
+	}
+
+	result.Token = token
+	return result, nil
+}
+
+func (a *user) EnrollMFA(ctx context.Context) (entity.MFAEnrolment, error) {
+	result := entity.MFAEnrolment{}
+
+	user, err := a.GetProfile(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	if user.MFAEnabled {
+		return result, apperror.Conflict("mfa is already enabled").WithKey("user.mfa_already_enabled")
+	}
+
+	key, err := a.auth.GenerateTOTPKey(user.Username)
+	if err != nil {
+		return result, err
+	}
+
+	if err := a.user.Update(ctx, entity.UserParam{
+		ID: user.ID,
+	}, entity.UpdateUserParam{
+		MFASecret: key.Secret,
+	}); err != nil {
+		return result, err
+	}
+
+	result.Secret = key.Secret
+	result.URI = key.URI
+
+	return result, nil
+}
+
+func (a *user) ConfirmMFA(ctx context.Context, param entity.MFACodeParam) (entity.MFARecoveryCodes, error) {
+	result := entity.MFARecoveryCodes{}
+
+	user, err := a.GetProfile(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	if user.MFAEnabled {
+		return result, apperror.Conflict("mfa is already enabled").WithKey("user.mfa_already_enabled")
+	}
+
+	if user.MFASecret == "" {
+		return result, apperror.Conflict("mfa enrolment not started").WithKey("user.mfa_enrolment_not_started")
+	}
+
+	if !a.useTOTP(ctx, user, param.Code) {
+		return result, apperror.Validation("invalid mfa code").WithKey("user.mfa_invalid_code")
+	}
+
+	result, err = a.saveRecoveryCodes(ctx, user, true)
+	if err != nil {
+		return result, err
+	}
+
+	a.log.Info(ctx, "mfa enabled", "audit", true, "user_id", user.ID)
+
+	return result, nil
+}
+
+func (a *user) RegenerateRecoveryCodes(ctx context.Context, param entity.MFACodeParam) (entity.MFARecoveryCodes, error) {
+	result := entity.MFARecoveryCodes{}
+
+	user, err := a.GetProfile(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	if !user.MFAEnabled {
+		return result, apperror.Conflict("mfa is not enabled").WithKey("user.mfa_not_enabled")
+	}
+
+	if !a.useTOTP(ctx, user, param.Code) {
+		return result, apperror.Validation("invalid mfa code").WithKey("user.mfa_invalid_code")
+	}
+
+	result, err = a.saveRecoveryCodes(ctx, user, false)
+	if err != nil {
+		return result, err
+	}
+
+	a.log.Info(ctx, "mfa recovery codes regenerated", "audit", true, "user_id", user.ID)
+
+	return result, nil
+}
+
+func (a *user) saveRecoveryCodes(ctx context.Context, user entity.User, enable bool) (entity.MFARecoveryCodes, error) {
+	result := entity.MFARecoveryCodes{}
+
+	codes, err := a.auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return result, err
+	}
+
+	hashedCodes := []string{}
+	for _, c := range codes {
+		hashedCodes = append(hashedCodes, a.auth.HashRecoveryCode(c))
+	}
+
+	marshalledCodes, err := json.Marshal(hashedCodes)
+	if err != nil {
+		return result, err
+	}
+
+	if err := a.user.Update(ctx, entity.UserParam{
+		ID: user.ID,
+	}, entity.UpdateUserParam{
+		MFAEnabled:       enable,
+		MFARecoveryCodes: string(marshalledCodes),
+	}); err != nil {
+		return result, err
+	}
+
+	result.RecoveryCodes = codes
+
+	return result, nil
+}
+
+// verifyMFACode accepts either a valid TOTP code or an unused recovery code,
+// which is consumed on use.
+func (a *user) verifyMFACode(ctx context.Context, user entity.User, code string) bool {
+	if a.useTOTP(ctx, user, code) {
+		return true
+	}
+
+	hashedCodes := []string{}
+	if err := json.Unmarshal([]byte(user.MFARecoveryCodes), &hashedCodes); err != nil {
+		return false
+	}
+
+	hashedCode := a.auth.HashRecoveryCode(code)
+	for i, c := range hashedCodes {
+		if c != hashedCode {
+			continue
+		}
+
+		remaining, err := json.Marshal(append(hashedCodes[:i:i], hashedCodes[i+1:]...))
+		if err != nil {
+			return false
+		}
+
+		// the codes are only replaced while they are the ones read, so two
+		// requests with the same code cannot both use it
+		used, err := a.user.UseRecoveryCodes(ctx, user.ID, user.MFARecoveryCodes, string(remaining))
+		if err != nil {
+			a.log.Error(ctx, "failed to consume recovery code", "user_id", user.ID, "error", err)
+			return false
+		}
+
+		if !used {
+			a.log.Warn(ctx, "mfa recovery code used concurrently", "audit", true, "user_id", user.ID)
+			return false
+		}
+
+		a.log.Info(ctx, "mfa recovery code used", "audit", true, "user_id", user.ID, "codes_left", len(hashedCodes)-1)
+		return true
+	}
+
+	return false
+}
+
+// useTOTP accepts a valid TOTP code once. A code of a time step accepted
+// before is rejected, a code seen on the wire cannot be replayed.
+func (a *user) useTOTP(ctx context.Context, user entity.User, code string) bool {
+	step, ok := a.auth.ValidateTOTP(user.MFASecret, code)
+	if !ok {
+		return false
+	}
+
+	used, err := a.user.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		a.log.Error(ctx, "failed to record mfa code", "user_id", user.ID, "error", err)
+		return false
+	}
+
+	if !used {
+		a.log.Warn(ctx, "mfa code replayed", "audit", true, "user_id", user.ID)
+		return false
+	}
+
+	return true
+}
+
+func (a *user) checkLockout(ctx context.Context, attempt entity.LoginAttemptParam) error {
+	lockout, err := a.loginAttempt.GetLockout(ctx, attempt)
+	if err != nil {
+		a.log.Error(ctx, "failed to get login lockout", "error", err)
+	}
+
+	if lockout > 0 {
+		a.log.Warn(ctx, "login rejected, locked out", "audit", true, "username", attempt.Username, "ip", attempt.IPAddress, "retry_after", lockout.String())
+		return &LockedError{RetryAfter: lockout}
+	}
+
+	return nil
+}
+
+func (a *user) loginFailed(ctx context.Context, attempt entity.LoginAttemptParam) error {
+	a.log.Warn(ctx, "login failed", "audit", true, "username", attempt.Username, "ip", attempt.IPAddress)
+
+	lockout, err := a.loginAttempt.RecordFailure(ctx, attempt)
+	if err != nil {
+		a.log.Error(ctx, "failed to record login attempt", "error", err)
+	}
+
+	if lockout > 0 {
+		a.log.Warn(ctx, "login locked out", "audit", true, "username", attempt.Username, "ip", attempt.IPAddress, "duration", lockout.String())
+		return &LockedError{RetryAfter: lockout}
+	}
+
+	return apperror.Unauthorized("invalid username or password").WithKey("auth.login_failed")
+}
+
+func (a *user) rehashPassword(ctx context.Context, user entity.User, password string) {
+	hashPass, err := a.auth.HashPassword(password)
+	if err != nil {
+		a.log.Error(ctx, "failed to rehash password", "user_id", user.ID, "error", err)
+		return
 	}
 
-	return token, nil
+	if err := a.user.Update(ctx, entity.UserParam{
+		ID: user.ID,
+	}, entity.UpdateUserParam{
+		Password: hashPass,
+	}); err != nil {
+		a.log.Error(ctx, "failed to upgrade password hash", "user_id", user.ID, "error", err)
+	}
 }
@@ -1,12 +1,18 @@
 package user_test
 
 import (
+	"context"
 	"errors"
+	mock_loginattempt "go-clean/src/business/domain/mock/login_attempt"
+	mock_outbox "go-clean/src/business/domain/mock/outbox"
 	mock_user "go-clean/src/business/domain/mock/user"
 	"go-clean/src/business/entity"
 	"go-clean/src/business/usecase/user"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
 	mock_auth "go-clean/src/lib/tests/mock/auth"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -19,6 +25,8 @@ func Test_user_Create(t *testing.T) {
 	defer ctrl.Finish()
 
 	userMock := mock_user.NewMockInterface(ctrl)
+	authMock := mock_auth.NewMockInterface(ctrl)
+	outboxMock := mock_outbox.NewMockInterface(ctrl)
 	hashPass, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
 
 	mockParams := entity.CreateUserParam{
@@ -34,14 +42,22 @@ func Test_user_Create(t *testing.T) {
 		Password: string(hashPass),
 	}
 
-	u := user.Init(userMock, nil)
+	u := user.Init(log.Init(log.Config{Level: "disabled"}), userMock, nil, authMock, outboxMock)
 
 	type mockfields struct {
-		user *mock_user.MockInterface
+		user   *mock_user.MockInterface
+		auth   *mock_auth.MockInterface
+		outbox *mock_outbox.MockInterface
 	}
 
 	mocks := mockfields{
-		user: userMock,
+		user:   userMock,
+		auth:   authMock,
+		outbox: outboxMock,
+	}
+
+	withTx := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
 	}
 
 	type args struct {
@@ -55,10 +71,70 @@ func Test_user_Create(t *testing.T) {
 		want     entity.User
 		wantErr  bool
 	}{
+		{
+			name: "failed to check username",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{Username: "mail"}).Return(entity.User{}, assert.AnError)
+			},
+			args: args{
+				params: mockParams,
+			},
+			want: entity.User{
+				Username: "mail",
+			},
+			wantErr: true,
+		},
+		{
+			name: "username is taken",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{Username: "mail"}).Return(mockUserResult, nil)
+			},
+			args: args{
+				params: mockParams,
+			},
+			want: entity.User{
+				Username: "mail",
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to hash password",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{Username: "mail"}).Return(entity.User{}, gorm.ErrRecordNotFound)
+				mock.auth.EXPECT().HashPassword(arg.params.Password).Return("", assert.AnError)
+			},
+			args: args{
+				params: mockParams,
+			},
+			want: entity.User{
+				Username: "mail",
+			},
+			wantErr: true,
+		},
 		{
 			name: "failed to create user",
 			mockFunc: func(mock mockfields, arg args) {
-				mock.user.EXPECT().Create(gomock.Any()).Return(mockUserResult, assert.AnError)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{Username: "mail"}).Return(entity.User{}, gorm.ErrRecordNotFound)
+				mock.auth.EXPECT().HashPassword(arg.params.Password).Return(string(hashPass), nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.user.EXPECT().Create(context.Background(), gomock.Any()).Return(mockUserResult, assert.AnError)
+			},
+			args: args{
+				params: mockParams,
+			},
+			want: entity.User{
+				Username: "mail",
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to add user registered event",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{Username: "mail"}).Return(entity.User{}, gorm.ErrRecordNotFound)
+				mock.auth.EXPECT().HashPassword(arg.params.Password).Return(string(hashPass), nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.user.EXPECT().Create(context.Background(), gomock.Any()).Return(mockUserResult, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).Return(assert.AnError)
 			},
 			args: args{
 				params: mockParams,
@@ -71,7 +147,15 @@ func Test_user_Create(t *testing.T) {
 		{
 			name: "all ok",
 			mockFunc: func(mock mockfields, arg args) {
-				mock.user.EXPECT().Create(gomock.Any()).Return(mockUserResult, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{Username: "mail"}).Return(entity.User{}, gorm.ErrRecordNotFound)
+				mock.auth.EXPECT().HashPassword(arg.params.Password).Return(string(hashPass), nil)
+				mock.outbox.EXPECT().WithTx(context.Background(), gomock.Any()).DoAndReturn(withTx)
+				mock.user.EXPECT().Create(context.Background(), gomock.Any()).Return(mockUserResult, nil)
+				mock.outbox.EXPECT().Add(context.Background(), gomock.Any()).DoAndReturn(func(ctx context.Context, events ...entity.OutboxEvent) error {
+					assert.Equal(t, entity.EventUserRegistered, events[0].Type)
+					assert.Equal(t, uint(1), events[0].AggregateID)
+					return nil
+				})
 			},
 			args: args{
 				params: mockParams,
@@ -85,7 +169,7 @@ func Test_user_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFunc(mocks, tt.args)
-			got, err := u.Create(tt.args.params)
+			got, err := u.Create(context.Background(), tt.args.params)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("user.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -109,7 +193,7 @@ func Test_user_Get(t *testing.T) {
 		Username: "mail",
 	}
 
-	u := user.Init(userMock, nil)
+	u := user.Init(log.Init(log.Config{Level: "disabled"}), userMock, nil, nil, nil)
 
 	type mockFields struct {
 		product *mock_user.MockInterface
@@ -135,7 +219,7 @@ func Test_user_Get(t *testing.T) {
 				id: 1,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.product.EXPECT().Get(userParamMock).Return(entity.User{}, assert.AnError)
+				mock.product.EXPECT().Get(context.Background(), userParamMock).Return(entity.User{}, assert.AnError)
 			},
 			want:    entity.User{},
 			wantErr: true,
@@ -146,7 +230,7 @@ func Test_user_Get(t *testing.T) {
 				id: 1,
 			},
 			mockFunc: func(mock mockFields, arg args) {
-				mock.product.EXPECT().Get(userParamMock).Return(userOkResult, nil)
+				mock.product.EXPECT().Get(context.Background(), userParamMock).Return(userOkResult, nil)
 			},
 			want:    userOkResult,
 			wantErr: false,
@@ -155,7 +239,7 @@ func Test_user_Get(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFunc(mocks, tt.args)
-			got, err := u.GetById(tt.args.id)
+			got, err := u.GetById(context.Background(), tt.args.id)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("user.GetById() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -170,17 +254,24 @@ func Test_user_Login(t *testing.T) {
 	defer ctrl.Finish()
 
 	userMock := mock_user.NewMockInterface(ctrl)
+	loginAttemptMock := mock_loginattempt.NewMockInterface(ctrl)
 	authMock := mock_auth.NewMockInterface(ctrl)
 
 	mockParams := entity.LoginUserParam{
-		Username: "mail",
-		Password: "password",
+		Username:  "mail",
+		Password:  "password",
+		IPAddress: "127.0.0.1",
 	}
 
 	mockGetUserParam := entity.UserParam{
 		Username: "mail",
 	}
 
+	mockAttemptParam := entity.LoginAttemptParam{
+		Username:  "mail",
+		IPAddress: "127.0.0.1",
+	}
+
 	hashPass, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
 
 	mockUserResult := entity.User{
@@ -193,86 +284,178 @@ func Test_user_Login(t *testing.T) {
 
 	mockToken := "mockToken"
 
-	u := user.Init(userMock, authMock)
+	mockMFAUserResult := mockUserResult
+	mockMFAUserResult.MFAEnabled = true
+
+	u := user.Init(log.Init(log.Config{Level: "disabled"}), userMock, loginAttemptMock, authMock, nil)
 
 	type mockfields struct {
-		user *mock_user.MockInterface
-		auth *mock_auth.MockInterface
+		user         *mock_user.MockInterface
+		loginAttempt *mock_loginattempt.MockInterface
+		auth         *mock_auth.MockInterface
 	}
 
 	mocks := mockfields{
-		user: userMock,
-		auth: authMock,
+		user:         userMock,
+		loginAttempt: loginAttemptMock,
+		auth:         authMock,
 	}
 
 	type args struct {
+		ctx    context.Context
 		params entity.LoginUserParam
 	}
 
 	tests := []struct {
-		name     string
-		mockFunc func(mock mockfields, arg args)
-		args     args
-		want     string
-		wantErr  bool
+		name       string
+		mockFunc   func(mock mockfields, arg args)
+		args       args
+		want       entity.LoginResult
+		wantErr    bool
+		wantLocked bool
 	}{
+		{
+			name: "locked out",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Minute, nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:       entity.LoginResult{},
+			wantErr:    true,
+			wantLocked: true,
+		},
 		{
 			name: "failed to find user",
 			mockFunc: func(mock mockfields, arg args) {
-				mock.user.EXPECT().Get(mockGetUserParam).Return(entity.User{}, errors.New("user not found"))
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.user.EXPECT().Get(context.Background(), mockGetUserParam).Return(entity.User{}, errors.New("user not found"))
 			},
 			args: args{
+				ctx:    context.Background(),
 				params: mockParams,
 			},
-			want:    "",
+			want:    entity.LoginResult{},
 			wantErr: true,
 		},
 		{
 			name: "user not found",
 			mockFunc: func(mock mockfields, arg args) {
-				mock.user.EXPECT().Get(mockGetUserParam).Return(entity.User{}, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.user.EXPECT().Get(context.Background(), mockGetUserParam).Return(entity.User{}, gorm.ErrRecordNotFound)
+				mock.auth.EXPECT().CompareDummyPassword("password")
+				mock.loginAttempt.EXPECT().RecordFailure(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
 			},
 			args: args{
+				ctx:    context.Background(),
 				params: mockParams,
 			},
-			want:    "",
+			want:    entity.LoginResult{},
 			wantErr: true,
 		},
 		{
 			name: "password incorrect",
 			mockFunc: func(mock mockfields, arg args) {
-				mockUserResultWithWrongPassword := mockUserResult
-				mockUserResultWithWrongPassword.Password = "wrongPassword"
-				mock.user.EXPECT().Get(mockGetUserParam).Return(mockUserResultWithWrongPassword, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.user.EXPECT().Get(context.Background(), mockGetUserParam).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ComparePassword(mockUserResult.Password, arg.params.Password).Return(assert.AnError)
+				mock.loginAttempt.EXPECT().RecordFailure(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
 			},
 			args: args{
+				ctx:    context.Background(),
 				params: mockParams,
 			},
-			want:    "",
+			want:    entity.LoginResult{},
 			wantErr: true,
 		},
+		{
+			name: "password incorrect and locked out",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.user.EXPECT().Get(context.Background(), mockGetUserParam).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ComparePassword(mockUserResult.Password, arg.params.Password).Return(assert.AnError)
+				mock.loginAttempt.EXPECT().RecordFailure(arg.ctx, mockAttemptParam).Return(30*time.Second, nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:       entity.LoginResult{},
+			wantErr:    true,
+			wantLocked: true,
+		},
 		{
 			name: "failed to generate token",
 			mockFunc: func(mock mockfields, arg args) {
-				mock.user.EXPECT().Get(mockGetUserParam).Return(mockUserResult, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.user.EXPECT().Get(context.Background(), mockGetUserParam).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ComparePassword(mockUserResult.Password, arg.params.Password).Return(nil)
+				mock.auth.EXPECT().NeedRehash(mockUserResult.Password).Return(false)
+				mock.loginAttempt.EXPECT().Reset(arg.ctx, mockAttemptParam).Return(nil)
 				mock.auth.EXPECT().GenerateToken(gomock.Any()).Return("", errors.New("failed to generate token"))
 			},
 			args: args{
+				ctx:    context.Background(),
 				params: mockParams,
 			},
-			want:    "",
+			want:    entity.LoginResult{},
 			wantErr: true,
 		},
+		{
+			name: "success with password rehash",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), assert.AnError)
+				mock.user.EXPECT().Get(context.Background(), mockGetUserParam).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ComparePassword(mockUserResult.Password, arg.params.Password).Return(nil)
+				mock.auth.EXPECT().NeedRehash(mockUserResult.Password).Return(true)
+				mock.auth.EXPECT().HashPassword(arg.params.Password).Return("newHash", nil)
+				mock.user.EXPECT().Update(context.Background(), entity.UserParam{ID: 1}, entity.UpdateUserParam{Password: "newHash"}).Return(nil)
+				mock.loginAttempt.EXPECT().Reset(arg.ctx, mockAttemptParam).Return(nil)
+				mock.auth.EXPECT().GenerateToken(gomock.Any()).Return(mockToken, nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:    entity.LoginResult{Token: mockToken},
+			wantErr: false,
+		},
+		{
+			name: "mfa challenge",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.user.EXPECT().Get(context.Background(), mockGetUserParam).Return(mockMFAUserResult, nil)
+				mock.auth.EXPECT().ComparePassword(mockUserResult.Password, arg.params.Password).Return(nil)
+				mock.auth.EXPECT().NeedRehash(mockUserResult.Password).Return(false)
+				mock.auth.EXPECT().GenerateMFAChallengeToken(gomock.Any()).Return("challengeToken", nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want: entity.LoginResult{
+				MFARequired:    true,
+				ChallengeToken: "challengeToken",
+			},
+			wantErr: false,
+		},
 		{
 			name: "success",
 			mockFunc: func(mock mockfields, arg args) {
-				mock.user.EXPECT().Get(mockGetUserParam).Return(mockUserResult, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.user.EXPECT().Get(context.Background(), mockGetUserParam).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ComparePassword(mockUserResult.Password, arg.params.Password).Return(nil)
+				mock.auth.EXPECT().NeedRehash(mockUserResult.Password).Return(false)
+				mock.loginAttempt.EXPECT().Reset(arg.ctx, mockAttemptParam).Return(nil)
 				mock.auth.EXPECT().GenerateToken(gomock.Any()).Return(mockToken, nil)
 			},
 			args: args{
+				ctx:    context.Background(),
 				params: mockParams,
 			},
-			want:    mockToken,
+			want:    entity.LoginResult{Token: mockToken},
 			wantErr: false,
 		},
 	}
@@ -280,11 +463,489 @@ func Test_user_Login(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFunc(mocks, tt.args)
-			got, err := u.Login(tt.args.params)
+			got, err := u.Login(tt.args.ctx, tt.args.params)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("user.Login() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			var lockedErr *user.LockedError
+			assert.Equal(t, tt.wantLocked, errors.As(err, &lockedErr))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_user_UpdateProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userMock := mock_user.NewMockInterface(ctrl)
+	authMock := mock_auth.NewMockInterface(ctrl)
+
+	u := user.Init(log.Init(log.Config{Level: "disabled"}), userMock, nil, authMock, nil)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	mockParams := entity.UpdateProfileParam{
+		Name:  "name",
+		Email: "mail@mail.com",
+	}
+
+	mockUserResult := entity.User{
+		Model: gorm.Model{
+			ID: 1,
+		},
+		Username: "mail",
+		Name:     "name",
+		Email:    "mail@mail.com",
+	}
+
+	type mockfields struct {
+		user *mock_user.MockInterface
+		auth *mock_auth.MockInterface
+	}
+
+	mocks := mockfields{
+		user: userMock,
+		auth: authMock,
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		want     entity.User
+		wantErr  bool
+	}{
+		{
+			name: "failed to get auth user",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, assert.AnError)
+			},
+			want:    entity.User{},
+			wantErr: true,
+		},
+		{
+			name: "failed to update user",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Update(context.Background(), entity.UserParam{ID: 1}, entity.UpdateUserParam{Name: "name", Email: "mail@mail.com"}).Return(assert.AnError)
+			},
+			want:    entity.User{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Update(context.Background(), entity.UserParam{ID: 1}, entity.UpdateUserParam{Name: "name", Email: "mail@mail.com"}).Return(nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+			},
+			want:    mockUserResult,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := u.UpdateProfile(context.Background(), mockParams)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.UpdateProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_user_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userMock := mock_user.NewMockInterface(ctrl)
+	authMock := mock_auth.NewMockInterface(ctrl)
+
+	u := user.Init(log.Init(log.Config{Level: "disabled"}), userMock, nil, authMock, nil)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	mockParams := entity.ChangePasswordParam{
+		OldPassword: "password",
+		NewPassword: "newPassword",
+	}
+
+	mockUserResult := entity.User{
+		Model: gorm.Model{
+			ID: 1,
+		},
+		Username: "mail",
+		Password: "hash",
+	}
+
+	type mockfields struct {
+		user *mock_user.MockInterface
+		auth *mock_auth.MockInterface
+	}
+
+	mocks := mockfields{
+		user: userMock,
+		auth: authMock,
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		wantErr  bool
+	}{
+		{
+			name: "failed to get user",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(entity.User{}, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "old password incorrect",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ComparePassword("hash", "password").Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to update password",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ComparePassword("hash", "password").Return(nil)
+				mock.auth.EXPECT().HashPassword("newPassword").Return("newHash", nil)
+				mock.user.EXPECT().Update(context.Background(), entity.UserParam{ID: 1}, entity.UpdateUserParam{Password: "newHash"}).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ComparePassword("hash", "password").Return(nil)
+				mock.auth.EXPECT().HashPassword("newPassword").Return("newHash", nil)
+				mock.user.EXPECT().Update(context.Background(), entity.UserParam{ID: 1}, entity.UpdateUserParam{Password: "newHash"}).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			err := u.ChangePassword(context.Background(), mockParams)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.ChangePassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_user_LoginMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userMock := mock_user.NewMockInterface(ctrl)
+	loginAttemptMock := mock_loginattempt.NewMockInterface(ctrl)
+	authMock := mock_auth.NewMockInterface(ctrl)
+
+	u := user.Init(log.Init(log.Config{Level: "disabled"}), userMock, loginAttemptMock, authMock, nil)
+
+	mockParams := entity.LoginMFAParam{
+		ChallengeToken: "challengeToken",
+		Code:           "123456",
+		IPAddress:      "127.0.0.1",
+	}
+
+	mockAttemptParam := entity.LoginAttemptParam{
+		Username:  "mail",
+		IPAddress: "127.0.0.1",
+	}
+
+	mockUserResult := entity.User{
+		Model: gorm.Model{
+			ID: 1,
+		},
+		Username:         "mail",
+		MFAEnabled:       true,
+		MFASecret:        "secret",
+		MFARecoveryCodes: `["hashedA","hashedB"]`,
+	}
+
+	type mockfields struct {
+		user         *mock_user.MockInterface
+		loginAttempt *mock_loginattempt.MockInterface
+		auth         *mock_auth.MockInterface
+	}
+
+	mocks := mockfields{
+		user:         userMock,
+		loginAttempt: loginAttemptMock,
+		auth:         authMock,
+	}
+
+	type args struct {
+		ctx    context.Context
+		params entity.LoginMFAParam
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields, arg args)
+		args     args
+		want     entity.LoginResult
+		wantErr  bool
+	}{
+		{
+			name: "invalid challenge token",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().ParseMFAChallengeToken("challengeToken").Return(uint(0), assert.AnError)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:    entity.LoginResult{},
+			wantErr: true,
+		},
+		{
+			name: "locked out",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().ParseMFAChallengeToken("challengeToken").Return(uint(1), nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Minute, nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:    entity.LoginResult{},
+			wantErr: true,
+		},
+		{
+			name: "invalid code",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().ParseMFAChallengeToken("challengeToken").Return(uint(1), nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.auth.EXPECT().ValidateTOTP("secret", "123456").Return(int64(0), false)
+				mock.auth.EXPECT().HashRecoveryCode("123456").Return("hashedC")
+				mock.loginAttempt.EXPECT().RecordFailure(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:    entity.LoginResult{},
+			wantErr: true,
+		},
+		{
+			name: "replayed code",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().ParseMFAChallengeToken("challengeToken").Return(uint(1), nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.auth.EXPECT().ValidateTOTP("secret", "123456").Return(int64(56666666), true)
+				mock.user.EXPECT().UseTOTPStep(context.Background(), uint(1), int64(56666666)).Return(false, nil)
+				mock.auth.EXPECT().HashRecoveryCode("123456").Return("hashedC")
+				mock.loginAttempt.EXPECT().RecordFailure(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:    entity.LoginResult{},
+			wantErr: true,
+		},
+		{
+			name: "recovery code used concurrently",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().ParseMFAChallengeToken("challengeToken").Return(uint(1), nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.auth.EXPECT().ValidateTOTP("secret", "123456").Return(int64(0), false)
+				mock.auth.EXPECT().HashRecoveryCode("123456").Return("hashedA")
+				mock.user.EXPECT().UseRecoveryCodes(context.Background(), uint(1), `["hashedA","hashedB"]`, `["hashedB"]`).Return(false, nil)
+				mock.loginAttempt.EXPECT().RecordFailure(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:    entity.LoginResult{},
+			wantErr: true,
+		},
+		{
+			name: "success with recovery code",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().ParseMFAChallengeToken("challengeToken").Return(uint(1), nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.auth.EXPECT().ValidateTOTP("secret", "123456").Return(int64(0), false)
+				mock.auth.EXPECT().HashRecoveryCode("123456").Return("hashedA")
+				mock.user.EXPECT().UseRecoveryCodes(context.Background(), uint(1), `["hashedA","hashedB"]`, `["hashedB"]`).Return(true, nil)
+				mock.loginAttempt.EXPECT().Reset(arg.ctx, mockAttemptParam).Return(nil)
+				mock.auth.EXPECT().GenerateToken(gomock.Any()).Return("mockToken", nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:    entity.LoginResult{Token: "mockToken"},
+			wantErr: false,
+		},
+		{
+			name: "success",
+			mockFunc: func(mock mockfields, arg args) {
+				mock.auth.EXPECT().ParseMFAChallengeToken("challengeToken").Return(uint(1), nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.loginAttempt.EXPECT().GetLockout(arg.ctx, mockAttemptParam).Return(time.Duration(0), nil)
+				mock.auth.EXPECT().ValidateTOTP("secret", "123456").Return(int64(56666666), true)
+				mock.user.EXPECT().UseTOTPStep(context.Background(), uint(1), int64(56666666)).Return(true, nil)
+				mock.loginAttempt.EXPECT().Reset(arg.ctx, mockAttemptParam).Return(nil)
+				mock.auth.EXPECT().GenerateToken(gomock.Any()).Return("mockToken", nil)
+			},
+			args: args{
+				ctx:    context.Background(),
+				params: mockParams,
+			},
+			want:    entity.LoginResult{Token: "mockToken"},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks, tt.args)
+			got, err := u.LoginMFA(tt.args.ctx, tt.args.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.LoginMFA() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_user_ConfirmMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userMock := mock_user.NewMockInterface(ctrl)
+	authMock := mock_auth.NewMockInterface(ctrl)
+
+	u := user.Init(log.Init(log.Config{Level: "disabled"}), userMock, nil, authMock, nil)
+
+	userAuthMock := auth.UserAuthInfo{
+		User: auth.User{
+			ID: 1,
+		},
+	}
+
+	mockParams := entity.MFACodeParam{
+		Code: "123456",
+	}
+
+	mockUserResult := entity.User{
+		Model: gorm.Model{
+			ID: 1,
+		},
+		Username:  "mail",
+		MFASecret: "secret",
+	}
+
+	mockEnabledUserResult := mockUserResult
+	mockEnabledUserResult.MFAEnabled = true
+
+	type mockfields struct {
+		user *mock_user.MockInterface
+		auth *mock_auth.MockInterface
+	}
+
+	mocks := mockfields{
+		user: userMock,
+		auth: authMock,
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		want     entity.MFARecoveryCodes
+		wantErr  bool
+	}{
+		{
+			name: "already enabled",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockEnabledUserResult, nil)
+			},
+			want:    entity.MFARecoveryCodes{},
+			wantErr: true,
+		},
+		{
+			name: "invalid code",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ValidateTOTP("secret", "123456").Return(int64(0), false)
+			},
+			want:    entity.MFARecoveryCodes{},
+			wantErr: true,
+		},
+		{
+			name: "failed to save recovery codes",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ValidateTOTP("secret", "123456").Return(int64(56666666), true)
+				mock.user.EXPECT().UseTOTPStep(context.Background(), uint(1), int64(56666666)).Return(true, nil)
+				mock.auth.EXPECT().GenerateRecoveryCodes(10).Return([]string{"a"}, nil)
+				mock.auth.EXPECT().HashRecoveryCode("a").Return("hashedA")
+				mock.user.EXPECT().Update(context.Background(), entity.UserParam{ID: 1}, entity.UpdateUserParam{MFAEnabled: true, MFARecoveryCodes: `["hashedA"]`}).Return(assert.AnError)
+			},
+			want:    entity.MFARecoveryCodes{},
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.auth.EXPECT().GetUserAuthInfo(context.Background()).Return(userAuthMock, nil)
+				mock.user.EXPECT().Get(context.Background(), entity.UserParam{ID: 1}).Return(mockUserResult, nil)
+				mock.auth.EXPECT().ValidateTOTP("secret", "123456").Return(int64(56666666), true)
+				mock.user.EXPECT().UseTOTPStep(context.Background(), uint(1), int64(56666666)).Return(true, nil)
+				mock.auth.EXPECT().GenerateRecoveryCodes(10).Return([]string{"a"}, nil)
+				mock.auth.EXPECT().HashRecoveryCode("a").Return("hashedA")
+				mock.user.EXPECT().Update(context.Background(), entity.UserParam{ID: 1}, entity.UpdateUserParam{MFAEnabled: true, MFARecoveryCodes: `["hashedA"]`}).Return(nil)
+			},
+			want: entity.MFARecoveryCodes{
+				RecoveryCodes: []string{"a"},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := u.ConfirmMFA(context.Background(), mockParams)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.ConfirmMFA() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
 			assert.Equal(t, tt.want, got)
 		})
 	}
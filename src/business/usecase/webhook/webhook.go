@@ -0,0 +1,372 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	webhookDom "go-clean/src/business/domain/webhook"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/log"
+	webhookSdk "go-clean/src/lib/webhook"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Interface interface {
+	Create(ctx context.Context, param entity.CreateWebhookSubscriptionParam) (entity.WebhookSubscription, error)
+	GetList(ctx context.Context) ([]entity.WebhookSubscription, error)
+	Get(ctx context.Context, param entity.WebhookSubscriptionParam) (entity.WebhookSubscription, error)
+	Update(ctx context.Context, selectParam entity.WebhookSubscriptionParam, updateParam entity.UpdateWebhookSubscriptionParam) (entity.WebhookSubscription, error)
+	Delete(ctx context.Context, param entity.WebhookSubscriptionParam) error
+	GetDeliveries(ctx context.Context, param entity.WebhookDeliveryParam) ([]entity.WebhookDelivery, error)
+	// Redeliver sends the delivery again right away, whatever its status.
+	Redeliver(ctx context.Context, param entity.WebhookDeliveryParam) (entity.WebhookDelivery, error)
+	// Enqueue is the event subscriber, it queues a delivery of the event to
+	// every active subscription of its type.
+	Enqueue(ctx context.Context, event entity.OutboxEvent) error
+	// Deliver sends one batch of due deliveries and returns the number of
+	// deliveries claimed.
+	Deliver(ctx context.Context) (int, error)
+	// Run delivers until ctx is done.
+	Run(ctx context.Context)
+}
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Lease is how long a claimed delivery is hidden from other workers, it
+	// must be longer than the lib timeout times the batch size.
+	Lease time.Duration
+	// MaxAttempts marks a delivery failed after that many failed attempts,
+	// it can still be redelivered manually.
+	MaxAttempts int
+	// RetryBackoff is the delay after the first failure, doubled after each
+	// further failure up to MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+type webhook struct {
+	conf    Config
+	log     log.Interface
+	auth    auth.Interface
+	webhook webhookDom.Interface
+}
+
+func Init(cfg Config, log log.Interface, auth auth.Interface, wd webhookDom.Interface) Interface {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 5 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 10 * time.Second
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = time.Hour
+	}
+
+	w := &webhook{
+		conf:    cfg,
+		log:     log,
+		auth:    auth,
+		webhook: wd,
+	}
+
+	return w
+}
+
+func (w *webhook) Create(ctx context.Context, param entity.CreateWebhookSubscriptionParam) (entity.WebhookSubscription, error) {
+	result := entity.WebhookSubscription{}
+
+	user, err := w.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	isActive := true
+	if param.IsActive != nil {
+		isActive = *param.IsActive
+	}
+
+	result, err = w.webhook.Create(ctx, entity.WebhookSubscription{
+		URL:        param.URL,
+		Secret:     param.Secret,
+		EventTypes: param.EventTypes,
+		IsActive:   isActive,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	w.log.Info(ctx, "webhook subscription created", "audit", true, "user_id", user.User.ID, "webhook_id", result.ID, "url", result.URL, "event_types", result.EventTypes)
+
+	return result, nil
+}
+
+func (w *webhook) GetList(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	return w.webhook.GetList(ctx, entity.WebhookSubscriptionParam{})
+}
+
+func (w *webhook) Get(ctx context.Context, param entity.WebhookSubscriptionParam) (entity.WebhookSubscription, error) {
+	return w.webhook.Get(ctx, entity.WebhookSubscriptionParam{
+		ID: param.ID,
+	})
+}
+
+func (w *webhook) Update(ctx context.Context, selectParam entity.WebhookSubscriptionParam, updateParam entity.UpdateWebhookSubscriptionParam) (entity.WebhookSubscription, error) {
+	result := entity.WebhookSubscription{}
+
+	user, err := w.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	subscription, err := w.webhook.Get(ctx, entity.WebhookSubscriptionParam{
+		ID: selectParam.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if err := w.webhook.Update(ctx, entity.WebhookSubscriptionParam{
+		ID: subscription.ID,
+	}, updateParam); err != nil {
+		return result, err
+	}
+
+	result, err = w.webhook.Get(ctx, entity.WebhookSubscriptionParam{
+		ID: subscription.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	w.log.Info(ctx, "webhook subscription updated", "audit", true, "user_id", user.User.ID, "webhook_id", result.ID, "url", result.URL, "event_types", result.EventTypes, "is_active", result.IsActive, "secret_rotated", updateParam.Secret != "")
+
+	return result, nil
+}
+
+func (w *webhook) Delete(ctx context.Context, param entity.WebhookSubscriptionParam) error {
+	user, err := w.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := w.webhook.Delete(ctx, entity.WebhookSubscriptionParam{
+		ID: param.ID,
+	}); err != nil {
+		return err
+	}
+
+	w.log.Info(ctx, "webhook subscription deleted", "audit", true, "user_id", user.User.ID, "webhook_id", param.ID)
+
+	return nil
+}
+
+func (w *webhook) GetDeliveries(ctx context.Context, param entity.WebhookDeliveryParam) ([]entity.WebhookDelivery, error) {
+	result := []entity.WebhookDelivery{}
+
+	subscription, err := w.webhook.Get(ctx, entity.WebhookSubscriptionParam{
+		ID: param.SubscriptionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return w.webhook.GetDeliveries(ctx, entity.WebhookDeliveryParam{
+		SubscriptionID: subscription.ID,
+	})
+}
+
+func (w *webhook) Redeliver(ctx context.Context, param entity.WebhookDeliveryParam) (entity.WebhookDelivery, error) {
+	result := entity.WebhookDelivery{}
+
+	user, err := w.auth.GetUserAuthInfo(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	subscription, err := w.webhook.Get(ctx, entity.WebhookSubscriptionParam{
+		ID: param.SubscriptionID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	delivery, err := w.webhook.GetDelivery(ctx, entity.WebhookDeliveryParam{
+		ID:             param.ID,
+		SubscriptionID: subscription.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	attempt := w.attempt(ctx, subscription, delivery)
+	if err := w.webhook.RecordAttempt(ctx, delivery.ID, attempt); err != nil {
+		return result, err
+	}
+
+	w.log.Info(ctx, "webhook redelivered", "audit", true, "user_id", user.User.ID, "webhook_id", subscription.ID, "delivery_id", delivery.ID, "status", attempt.Status, "response_status", attempt.ResponseStatus)
+
+	return w.webhook.GetDelivery(ctx, entity.WebhookDeliveryParam{
+		ID: delivery.ID,
+	})
+}
+
+func (w *webhook) Enqueue(ctx context.Context, event entity.OutboxEvent) error {
+	if !entity.IsWebhookEventType(event.Type) {
+		return nil
+	}
+
+	subscriptions, err := w.webhook.GetList(ctx, entity.WebhookSubscriptionParam{
+		IsActive: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	deliveries := []entity.WebhookDelivery{}
+	for _, s := range subscriptions {
+		if !s.Subscribes(event.Type) {
+			continue
+		}
+
+		delivery, err := entity.NewWebhookDelivery(s.ID, event)
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return w.webhook.AddDeliveries(ctx, deliveries...)
+}
+
+func (w *webhook) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.conf.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := w.Deliver(ctx)
+		if err != nil {
+			w.log.Error(ctx, "failed to deliver webhooks", "error", err)
+		}
+
+		// a full batch means more deliveries are probably due
+		if err == nil && n == w.conf.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *webhook) Deliver(ctx context.Context) (int, error) {
+	deliveries, err := w.webhook.ClaimDeliveries(ctx, w.conf.BatchSize, w.conf.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := map[uint]entity.WebhookSubscription{}
+	for _, d := range deliveries {
+		subscription, ok := subscriptions[d.SubscriptionID]
+		if !ok {
+			subscription, err = w.webhook.Get(ctx, entity.WebhookSubscriptionParam{
+				ID: d.SubscriptionID,
+			})
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				// the delivery is claimed again once its lease expires
+				w.log.Error(ctx, "failed to get webhook subscription", "webhook_id", d.SubscriptionID, "delivery_id", d.ID, "error", err)
+				continue
+			}
+			subscriptions[d.SubscriptionID] = subscription
+		}
+
+		attempt := entity.WebhookAttempt{
+			Status: entity.WebhookDeliveryStatusFailed,
+		}
+		switch {
+		case subscription.ID == 0:
+			attempt.LastError = "subscription deleted"
+		case !subscription.IsActive:
+			// deliveries queued before the subscription was disabled are
+			// dropped, they can still be redelivered manually
+			attempt.LastError = "subscription inactive"
+		default:
+			attempt = w.attempt(ctx, subscription, d)
+		}
+
+		if err := w.webhook.RecordAttempt(ctx, d.ID, attempt); err != nil {
+			w.log.Error(ctx, "failed to record webhook attempt", "delivery_id", d.ID, "error", err)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery to the subscription and returns the outcome to
+// record, a failure is retried with backoff until MaxAttempts.
+func (w *webhook) attempt(ctx context.Context, subscription entity.WebhookSubscription, delivery entity.WebhookDelivery) entity.WebhookAttempt {
+	resp, err := w.webhook.Send(ctx, webhookSdk.Request{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		EventType:  delivery.EventType,
+		DeliveryID: delivery.ID,
+		Body:       []byte(delivery.Payload),
+	})
+
+	attempt := entity.WebhookAttempt{
+		Status:         entity.WebhookDeliveryStatusSuccess,
+		ResponseStatus: resp.StatusCode,
+		ResponseBody:   resp.Body,
+	}
+	if err == nil && resp.OK() {
+		w.log.Debug(ctx, "webhook delivered", "webhook_id", subscription.ID, "delivery_id", delivery.ID, "event_type", delivery.EventType)
+		return attempt
+	}
+
+	if err != nil {
+		attempt.LastError = err.Error()
+	} else {
+		attempt.LastError = fmt.Sprintf("receiver responded with status %d", resp.StatusCode)
+	}
+
+	attempts := delivery.Attempts + 1
+	if attempts >= w.conf.MaxAttempts {
+		w.log.Error(ctx, "webhook delivery failed, giving up", "webhook_id", subscription.ID, "delivery_id", delivery.ID, "event_type", delivery.EventType, "attempts", attempts, "response_status", resp.StatusCode, "error", attempt.LastError)
+		attempt.Status = entity.WebhookDeliveryStatusFailed
+		return attempt
+	}
+
+	attempt.Status = entity.WebhookDeliveryStatusPending
+	attempt.NextAttemptAt = time.Now().Add(w.backoff(attempts))
+	w.log.Warn(ctx, "webhook delivery failed, retrying", "webhook_id", subscription.ID, "delivery_id", delivery.ID, "event_type", delivery.EventType, "attempts", attempts, "next_attempt_at", attempt.NextAttemptAt, "response_status", resp.StatusCode, "error", attempt.LastError)
+
+	return attempt
+}
+
+func (w *webhook) backoff(attempts int) time.Duration {
+	backoff := w.conf.RetryBackoff
+	for i := 1; i < attempts && backoff < w.conf.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > w.conf.MaxRetryBackoff {
+		return w.conf.MaxRetryBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,249 @@
+package webhook_test
+
+import (
+	"context"
+	mock_webhook "go-clean/src/business/domain/mock/webhook"
+	"go-clean/src/business/entity"
+	"go-clean/src/business/usecase/webhook"
+	"go-clean/src/lib/log"
+	webhookSdk "go-clean/src/lib/webhook"
+	"testing"
+	"time"
+
+	mock_auth "go-clean/src/lib/tests/mock/auth"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func Test_webhook_Enqueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	webhookMock := mock_webhook.NewMockInterface(ctrl)
+
+	w := webhook.Init(webhook.Config{}, log.Init(log.Config{Level: "disabled"}), authMock, webhookMock)
+
+	paymentSettled := entity.OutboxEvent{
+		ID:      1,
+		Type:    entity.EventPaymentSettled,
+		Payload: `{"transaction_id":1}`,
+	}
+
+	subscriptions := []entity.WebhookSubscription{
+		{
+			Model:      gorm.Model{ID: 1},
+			EventTypes: []string{entity.EventPaymentSettled, entity.EventPaymentFailed},
+			IsActive:   true,
+		},
+		{
+			Model:      gorm.Model{ID: 2},
+			EventTypes: []string{entity.EventOrderCreated},
+			IsActive:   true,
+		},
+	}
+
+	type mockfields struct {
+		webhook *mock_webhook.MockInterface
+	}
+
+	mocks := mockfields{
+		webhook: webhookMock,
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		event    entity.OutboxEvent
+		wantErr  bool
+	}{
+		{
+			name:     "not a webhook event",
+			mockFunc: func(mock mockfields) {},
+			event: entity.OutboxEvent{
+				ID:   2,
+				Type: entity.EventUserRegistered,
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed to get subscriptions",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().GetList(context.Background(), entity.WebhookSubscriptionParam{IsActive: true}).Return(nil, assert.AnError)
+			},
+			event:   paymentSettled,
+			wantErr: true,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().GetList(context.Background(), entity.WebhookSubscriptionParam{IsActive: true}).Return(subscriptions, nil)
+				mock.webhook.EXPECT().AddDeliveries(context.Background(), gomock.Any()).DoAndReturn(func(ctx context.Context, deliveries ...entity.WebhookDelivery) error {
+					assert.Len(t, deliveries, 1)
+					assert.Equal(t, uint(1), deliveries[0].SubscriptionID)
+					assert.Equal(t, uint(1), deliveries[0].EventID)
+					assert.Equal(t, entity.WebhookDeliveryStatusPending, deliveries[0].Status)
+					assert.JSONEq(t, `{"id":1,"type":"payment.settled","created_at":"0001-01-01T00:00:00Z","data":{"transaction_id":1}}`, deliveries[0].Payload)
+					return nil
+				})
+			},
+			event:   paymentSettled,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			err := w.Enqueue(context.Background(), tt.event)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func Test_webhook_Deliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authMock := mock_auth.NewMockInterface(ctrl)
+	webhookMock := mock_webhook.NewMockInterface(ctrl)
+
+	cfg := webhook.Config{
+		BatchSize:    10,
+		Lease:        time.Minute,
+		MaxAttempts:  3,
+		RetryBackoff: time.Second,
+	}
+	w := webhook.Init(cfg, log.Init(log.Config{Level: "disabled"}), authMock, webhookMock)
+
+	subscription := entity.WebhookSubscription{
+		Model:      gorm.Model{ID: 1},
+		URL:        "https://erp.example.com/hooks",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{entity.EventPaymentSettled},
+		IsActive:   true,
+	}
+
+	inactive := subscription
+	inactive.IsActive = false
+
+	delivery := entity.WebhookDelivery{
+		ID:             1,
+		SubscriptionID: 1,
+		EventID:        1,
+		EventType:      entity.EventPaymentSettled,
+		Payload:        `{"id":1}`,
+	}
+
+	lastAttempt := delivery
+	lastAttempt.Attempts = 2
+
+	req := webhookSdk.Request{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		EventType:  entity.EventPaymentSettled,
+		DeliveryID: 1,
+		Body:       []byte(`{"id":1}`),
+	}
+
+	type mockfields struct {
+		webhook *mock_webhook.MockInterface
+	}
+
+	mocks := mockfields{
+		webhook: webhookMock,
+	}
+
+	status := func(want string) gomock.Matcher {
+		return gomock.Cond(func(x any) bool {
+			return x.(entity.WebhookAttempt).Status == want
+		})
+	}
+
+	tests := []struct {
+		name     string
+		mockFunc func(mock mockfields)
+		want     int
+		wantErr  bool
+	}{
+		{
+			name: "failed to claim deliveries",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().ClaimDeliveries(context.Background(), 10, time.Minute).Return(nil, assert.AnError)
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "failed to get subscription",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().ClaimDeliveries(context.Background(), 10, time.Minute).Return([]entity.WebhookDelivery{delivery}, nil)
+				mock.webhook.EXPECT().Get(context.Background(), entity.WebhookSubscriptionParam{ID: 1}).Return(entity.WebhookSubscription{}, assert.AnError)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "subscription deleted",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().ClaimDeliveries(context.Background(), 10, time.Minute).Return([]entity.WebhookDelivery{delivery}, nil)
+				mock.webhook.EXPECT().Get(context.Background(), entity.WebhookSubscriptionParam{ID: 1}).Return(entity.WebhookSubscription{}, gorm.ErrRecordNotFound)
+				mock.webhook.EXPECT().RecordAttempt(context.Background(), uint(1), status(entity.WebhookDeliveryStatusFailed)).Return(nil)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "subscription inactive",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().ClaimDeliveries(context.Background(), 10, time.Minute).Return([]entity.WebhookDelivery{delivery}, nil)
+				mock.webhook.EXPECT().Get(context.Background(), entity.WebhookSubscriptionParam{ID: 1}).Return(inactive, nil)
+				mock.webhook.EXPECT().RecordAttempt(context.Background(), uint(1), status(entity.WebhookDeliveryStatusFailed)).Return(nil)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "receiver failed",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().ClaimDeliveries(context.Background(), 10, time.Minute).Return([]entity.WebhookDelivery{delivery}, nil)
+				mock.webhook.EXPECT().Get(context.Background(), entity.WebhookSubscriptionParam{ID: 1}).Return(subscription, nil)
+				mock.webhook.EXPECT().Send(context.Background(), req).Return(webhookSdk.Response{StatusCode: 503}, nil)
+				mock.webhook.EXPECT().RecordAttempt(context.Background(), uint(1), status(entity.WebhookDeliveryStatusPending)).Return(nil)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "receiver failed on the last attempt",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().ClaimDeliveries(context.Background(), 10, time.Minute).Return([]entity.WebhookDelivery{lastAttempt}, nil)
+				mock.webhook.EXPECT().Get(context.Background(), entity.WebhookSubscriptionParam{ID: 1}).Return(subscription, nil)
+				mock.webhook.EXPECT().Send(context.Background(), req).Return(webhookSdk.Response{}, assert.AnError)
+				mock.webhook.EXPECT().RecordAttempt(context.Background(), uint(1), status(entity.WebhookDeliveryStatusFailed)).Return(nil)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "all ok",
+			mockFunc: func(mock mockfields) {
+				mock.webhook.EXPECT().ClaimDeliveries(context.Background(), 10, time.Minute).Return([]entity.WebhookDelivery{delivery, delivery}, nil)
+				mock.webhook.EXPECT().Get(context.Background(), entity.WebhookSubscriptionParam{ID: 1}).Return(subscription, nil)
+				mock.webhook.EXPECT().Send(context.Background(), req).Return(webhookSdk.Response{StatusCode: 200}, nil).Times(2)
+				mock.webhook.EXPECT().RecordAttempt(context.Background(), uint(1), status(entity.WebhookDeliveryStatusSuccess)).Return(nil).Times(2)
+			},
+			want:    2,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFunc(mocks)
+			got, err := w.Deliver(context.Background())
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"go-clean/src/business/domain"
 	"go-clean/src/business/usecase"
 	"go-clean/src/handler/rest"
 	"go-clean/src/lib/auth"
 	"go-clean/src/lib/configreader"
+	"go-clean/src/lib/i18n"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"go-clean/src/lib/midtrans"
+	"go-clean/src/lib/migration"
+	"go-clean/src/lib/pdf"
 	"go-clean/src/lib/redis"
+	"go-clean/src/lib/shipping"
 	"go-clean/src/lib/sql"
+	"go-clean/src/lib/tracer"
+	"go-clean/src/lib/webhook"
 	"go-clean/src/utils/config"
+	"os"
 
 	_ "go-clean/docs/swagger"
 )
@@ -23,29 +35,75 @@ import (
 // @name Authorization
 
 const (
-	configFile string = "./etc/cfg/config.json"
+	defaultConfigFile string = "./etc/cfg/config.json"
 )
 
 func main() {
+	configFile := flag.String("config", defaultConfigFile, "path to the json config file, empty to read the config from env only")
+	flag.Parse()
+
 	cfg := config.Init()
 	configReader := configreader.Init(configreader.Options{
-		ConfigFile: configFile,
+		ConfigFile: *configFile,
 	})
-	configReader.ReadConfig(&cfg)
+	if err := configReader.ReadConfig(&cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	log := log.Init(cfg.Log)
+
+	metrics := metrics.Init(cfg.Metrics)
+
+	tracer := tracer.Init(cfg.Tracer)
+
+	auth := auth.Init(cfg.Auth)
+
+	midtrans := midtrans.Init(cfg.Midtrans, log, metrics, tracer)
+
+	db := sql.Init(cfg.SQL, metrics, tracer)
+
+	migration := migration.Init(cfg.Migration, db, log)
+
+	// the schema AutoMigrate synced already has every migration, record them
+	// so the migrate subcommand does not apply them again
+	if cfg.SQL.AutoMigrate {
+		if err := migration.Adopt(context.Background()); err != nil {
+			panic(err)
+		}
+	}
+
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		os.Exit(runMigrate(migration, args[1:]))
+	}
+
+	redis := redis.Init(cfg.Redis, log, tracer)
+
+	webhook := webhook.Init(cfg.Webhook, metrics, tracer)
+
+	shipping := shipping.Init(cfg.Shipping)
 
-	auth := auth.Init()
+	pdf := pdf.Init(cfg.PDF)
 
-	midtrans := midtrans.Init(cfg.Midtrans)
+	d := domain.Init(cfg.Domain, log, metrics, db, midtrans, redis, webhook, shipping, pdf)
 
-	db := sql.Init(cfg.SQL)
+	uc := usecase.Init(cfg.Usecase, log, metrics, auth, d)
 
-	redis := redis.Init(cfg.Redis)
+	// the dispatcher stops with the process, undelivered events are claimed
+	// again once their lease expires
+	go uc.Event.Run(context.Background())
+	go uc.Webhook.Run(context.Background())
 
-	d := domain.Init(db, midtrans, redis)
+	i18n := i18n.Init(cfg.I18n, log)
 
-	uc := usecase.Init(auth, d)
+	r := rest.Init(cfg.Gin, configReader, log, metrics, tracer, uc, auth, redis, db, midtrans, i18n)
 
-	r := rest.Init(cfg.Gin, configReader, uc, auth)
+	watchConfig(cfg, configReader, log, d)
 
 	r.Run()
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go-clean/src/lib/migration"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = `usage: main migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [n]    revert the last n applied migrations, default 1
+  status      list migrations and whether they are applied`
+
+// runMigrate handles the migrate subcommand and returns the process exit code.
+func runMigrate(m migration.Interface, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		if err := m.Up(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up failed: %s\n", err)
+			return 1
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "invalid number of steps %q\n", args[1])
+				return 2
+			}
+			steps = n
+		}
+
+		if err := m.Down(ctx, steps); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down failed: %s\n", err)
+			return 1
+		}
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status failed: %s\n", err)
+			return 1
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Dirty {
+				appliedAt = "partially applied"
+			} else if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"go-clean/src/business/domain"
+	"go-clean/src/lib/configreader"
+	"go-clean/src/lib/log"
+	"go-clean/src/utils/config"
+)
+
+// watchConfig applies changes of the config file to the running server. The
+// rest handler subscribes on its own, the log level and the domain settings
+// are applied here.
+func watchConfig(cfg config.Application, reader configreader.Interface, log log.Interface, d *domain.Domains) {
+	reader.AddValidator(func(next interface{}) error {
+		return cfg.ValidateReload(*next.(*config.Application))
+	})
+
+	reader.Subscribe(func(next interface{}) {
+		c := next.(*config.Application)
+		if err := log.SetLevel(c.Log.Level); err != nil {
+			log.Error(context.Background(), "failed to set log level", "error", err)
+		}
+		d.Reload(c.Domain)
+		log.Info(context.Background(), "config reloaded")
+	})
+
+	reader.WatchConfig(&cfg, func(err error) {
+		log.Error(context.Background(), "failed to reload config", "error", err)
+	})
+}
@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"go-clean/src/business/entity"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create Address
+// @Description Add New Address to the Address Book
+// @Security BearerAuth
+// @Tags Address
+// @Param address body entity.CreateAddressParam true "address info"
+// @Produce json
+// @Success 201 {object} entity.Response{data=entity.Address{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/address [POST]
+func (r *rest) CreateAddress(ctx *gin.Context) {
+	var param entity.CreateAddressParam
+	if err := ctx.ShouldBindJSON(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	address, err := r.uc.Address.Create(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusCreated, "address.create.success", address)
+}
+
+// @Summary Get List Address
+// @Description Get All Address in the Address Book
+// @Security BearerAuth
+// @Tags Address
+// @Produce json
+// @Success 200 {object} entity.Response{data=[]entity.Address{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/address [GET]
+func (r *rest) GetListAddress(ctx *gin.Context) {
+	addresses, err := r.uc.Address.GetList(ctx.Request.Context())
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "address.list.success", addresses)
+}
+
+// @Summary Get Address
+// @Description Get an Address from the Address Book
+// @Security BearerAuth
+// @Tags Address
+// @Produce json
+// @Param address_id path int true "address id"
+// @Success 200 {object} entity.Response{data=entity.Address{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/address/{address_id} [GET]
+func (r *rest) GetAddress(ctx *gin.Context) {
+	var param entity.AddressParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	address, err := r.uc.Address.Get(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "address.get.success", address)
+}
+
+// @Summary Update Address
+// @Description Update an Address in the Address Book
+// @Security BearerAuth
+// @Tags Address
+// @Produce json
+// @Param address_id path int true "address id"
+// @Param address body entity.UpdateAddressParam true "address info"
+// @Success 200 {object} entity.Response{}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/address/{address_id} [PATCH]
+func (r *rest) UpdateAddress(ctx *gin.Context) {
+	var selectParam entity.AddressParam
+	if err := ctx.ShouldBindUri(&selectParam); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var updateParam entity.UpdateAddressParam
+	if err := ctx.ShouldBindJSON(&updateParam); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := r.uc.Address.Update(ctx.Request.Context(), selectParam, updateParam); err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "address.update.success", nil)
+}
+
+// @Summary Delete Address
+// @Description Delete an Address from the Address Book
+// @Security BearerAuth
+// @Tags Address
+// @Produce json
+// @Param address_id path int true "address id"
+// @Success 200 {object} entity.Response{}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/address/{address_id} [DELETE]
+func (r *rest) DeleteAddress(ctx *gin.Context) {
+	var param entity.AddressParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := r.uc.Address.Delete(ctx.Request.Context(), param); err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "address.delete.success", nil)
+}
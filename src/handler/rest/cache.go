@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"go-clean/src/business/entity"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Flush Cache
+// @Description Delete the cached keys of the given namespaces, one of product, category, ratelimit or login
+// @Security BearerAuth
+// @Tags Admin
+// @Param cache body entity.FlushCacheParam true "namespaces to flush"
+// @Produce json
+// @Success 200 {object} entity.Response{data=[]entity.FlushCacheResult{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/cache/flush [POST]
+func (r *rest) FlushCache(ctx *gin.Context) {
+	var param entity.FlushCacheParam
+	if err := ctx.ShouldBindJSON(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := r.uc.Cache.Flush(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "cache.flush.success", result)
+}
@@ -32,7 +32,7 @@ func (r *rest) CreateCart(ctx *gin.Context) {
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfullt add item to cart", cart)
+	r.httpRespSuccess(ctx, http.StatusOK, "cart.add.success", cart)
 }
 
 // @Summary Get List Cart
@@ -53,7 +53,7 @@ func (r *rest) GetListCart(ctx *gin.Context) {
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfullt get all product from cart", carts)
+	r.httpRespSuccess(ctx, http.StatusOK, "cart.list.success", carts)
 }
 
 // @Summary Delete a Product
@@ -80,5 +80,5 @@ func (r *rest) DeleteCart(ctx *gin.Context) {
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfullt delete the product from cart", nil)
+	r.httpRespSuccess(ctx, http.StatusOK, "cart.delete.success", nil)
 }
@@ -24,5 +24,5 @@ func (r *rest) GetListCategory(ctx *gin.Context) {
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfully get list all category", categories)
+	r.httpRespSuccess(ctx, http.StatusOK, "category.list.success", categories)
 }
@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"context"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultHealthTimeout = 2 * time.Second
+
+	// healthCheckError is all /readyz tells about a failed check, it is not
+	// authenticated so the error itself is only logged.
+	healthCheckError = "unreachable"
+)
+
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+	// critical checks fail readiness, the others only degrade it.
+	critical bool
+}
+
+// @Summary Liveness
+// @Description Reports that the process is up, without checking any dependency
+// @Tags Health
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.HealthStatus{}}
+// @Router /healthz [GET]
+func (r *rest) Liveness(ctx *gin.Context) {
+	r.httpRespSuccess(ctx, http.StatusOK, "health.alive", entity.HealthStatus{
+		Status: entity.HealthStatusUp,
+	})
+}
+
+// @Summary Readiness
+// @Description Checks mysql, redis and optionally the payment gateway, fails while the server is shutting down. Redis being down only degrades it, the cache falls back to memory
+// @Tags Health
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.HealthStatus{}}
+// @Failure 503 {object} entity.Response{data=entity.HealthStatus{}}
+// @Router /readyz [GET]
+func (r *rest) Readiness(ctx *gin.Context) {
+	if atomic.LoadInt32(&r.shuttingDown) == 1 {
+		r.httpRespHealth(ctx, http.StatusServiceUnavailable, "health.shutting_down", entity.HealthStatus{
+			Status: entity.HealthStatusDown,
+		})
+		return
+	}
+
+	conf := r.config().Health
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	c, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+	defer cancel()
+
+	checks := []healthCheck{
+		{name: "mysql", check: r.pingSQL, critical: true},
+		{name: "redis", check: r.redis.Ping},
+	}
+	if conf.CheckGateway {
+		checks = append(checks, healthCheck{name: "midtrans", check: r.midtrans.Ping, critical: true})
+	}
+
+	status := entity.HealthStatus{
+		Status: entity.HealthStatusUp,
+		Checks: make(map[string]entity.HealthCheck, len(checks)),
+	}
+
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, hc := range checks {
+		wg.Add(1)
+		go func(hc healthCheck) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := hc.check(c)
+			result := entity.HealthCheck{
+				Status:  entity.HealthStatusUp,
+				Latency: time.Since(start).String(),
+			}
+			if err != nil {
+				r.log.Error(c, "health check failed", "check", hc.name, "error", err)
+				result.Status = entity.HealthStatusDown
+				result.Error = healthCheckError
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			status.Checks[hc.name] = result
+			switch {
+			case err != nil && hc.critical:
+				status.Status = entity.HealthStatusDown
+			case err != nil && status.Status == entity.HealthStatusUp:
+				status.Status = entity.HealthStatusDegraded
+			}
+		}(hc)
+	}
+	wg.Wait()
+
+	if status.Status == entity.HealthStatusDown {
+		r.httpRespHealth(ctx, http.StatusServiceUnavailable, "health.not_ready", status)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "health.ready", status)
+}
+
+func (r *rest) pingSQL(ctx context.Context) error {
+	db, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return db.PingContext(ctx)
+}
+
+// httpRespHealth is httpRespError that keeps the per dependency status in the
+// response data.
+func (r *rest) httpRespHealth(ctx *gin.Context, code int, key string, status entity.HealthStatus) {
+	resp := entity.Response{
+		Meta: entity.Meta{
+			Message:   r.i18n.Translate(ctx.Request.Context(), key),
+			Code:      code,
+			IsError:   true,
+			ErrorCode: string(apperror.CodeUnavailable),
+		},
+		Data: status,
+	}
+	ctx.AbortWithStatusJSON(code, resp)
+}
@@ -1,21 +1,27 @@
 package rest
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/apperror"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
+	"gorm.io/gorm"
 )
 
-func (r *rest) httpRespSuccess(ctx *gin.Context, code int, message string, data interface{}) {
+// httpRespSuccess answers with the message of key in the language of the
+// request.
+func (r *rest) httpRespSuccess(ctx *gin.Context, code int, key string, data interface{}) {
 	resp := entity.Response{
 		Meta: entity.Meta{
-			Message: message,
+			Message: r.i18n.Translate(ctx.Request.Context(), key),
 			Code:    code,
 			IsError: false,
 		},
@@ -24,48 +30,117 @@ func (r *rest) httpRespSuccess(ctx *gin.Context, code int, message string, data
 	ctx.JSON(code, resp)
 }
 
+// httpRespFile sends file as a download.
+func (r *rest) httpRespFile(ctx *gin.Context, file entity.Attachment) {
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Filename))
+	ctx.Data(http.StatusOK, file.ContentType, file.Content)
+}
+
+// httpRespError maps err to its response. A typed error sets the status and
+// the error code, code is only the status of untyped errors. Untyped errors
+// are answered with the generic message of their code, so gorm and midtrans
+// errors do not leak to clients. Binding errors of single fields are listed in
+// the errors of the response.
 func (r *rest) httpRespError(ctx *gin.Context, code int, err error) {
+	c := ctx.Request.Context()
+	appErr, ok := apperror.As(err)
+	fields, isFieldErr := r.fieldErrors(c, err)
+	switch {
+	case ok:
+		code = appErr.HTTPStatus()
+	case isFieldErr:
+		appErr = apperror.Validation("invalid request body").WithKey("error.invalid_request_body")
+		code = appErr.HTTPStatus()
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		appErr = apperror.NotFound("record not found").WithKey("error.not_found")
+		code = appErr.HTTPStatus()
+	default:
+		appErr = apperror.New(apperror.CodeFromStatus(code), err.Error())
+	}
+
+	if code >= http.StatusInternalServerError {
+		r.log.Error(c, "request failed", "status", code, "error", err)
+	} else if !ok {
+		r.log.Debug(c, "request rejected", "status", code, "error", err)
+	}
+
 	resp := entity.Response{
 		Meta: entity.Meta{
-			Message: err.Error(),
-			Code:    code,
-			IsError: true,
+			Message:   r.errorMessage(c, appErr, ok),
+			Code:      code,
+			IsError:   true,
+			ErrorCode: string(appErr.Code),
 		},
-		Data: nil,
+		Data:   nil,
+		Errors: fields,
 	}
 	ctx.AbortWithStatusJSON(code, resp)
 }
 
+// errorMessage translates the key of appErr. Typed errors without a key keep
+// their english message, untyped ones get the message of their code.
+func (r *rest) errorMessage(ctx context.Context, appErr *apperror.Error, typed bool) string {
+	switch {
+	case appErr.Key != "":
+		return r.i18n.Translate(ctx, appErr.Key, appErr.Args...)
+	case typed:
+		return appErr.Message
+	default:
+		return r.i18n.Translate(ctx, "error."+strings.ToLower(string(appErr.Code)))
+	}
+}
+
 func (r *rest) VerifyUser(ctx *gin.Context) {
+	r.verifyUser(ctx, false)
+}
+
+// VerifyUserPendingMFA is VerifyUser for the mfa enrolment routes, it lets
+// users whose role requires mfa through before they have enrolled.
+func (r *rest) VerifyUserPendingMFA(ctx *gin.Context) {
+	r.verifyUser(ctx, true)
+}
+
+func (r *rest) verifyUser(ctx *gin.Context, allowPendingMFA bool) {
 	authHeader := ctx.GetHeader("Authorization")
 	if authHeader == "" {
-		r.httpRespError(ctx, http.StatusUnauthorized, errors.New("empty token"))
+		r.httpRespError(ctx, http.StatusUnauthorized, apperror.Unauthorized("empty token").WithKey("auth.empty_token"))
 		return
 	}
 
 	var tokenString string
 	_, err := fmt.Sscanf(authHeader, "Bearer %v", &tokenString)
 	if err != nil {
-		r.httpRespError(ctx, http.StatusUnauthorized, errors.New("invalid token"))
+		r.httpRespError(ctx, http.StatusUnauthorized, apperror.Wrap(apperror.CodeUnauthorized, "invalid token", err).WithKey("auth.invalid_token"))
 		return
 	}
 
 	token, err := r.ValidateToken(tokenString)
 	if err != nil {
-		r.httpRespError(ctx, http.StatusUnauthorized, err)
+		r.httpRespError(ctx, http.StatusUnauthorized, apperror.Wrap(apperror.CodeUnauthorized, "invalid token", err).WithKey("auth.invalid_token"))
 		return
 	}
 
 	claim, ok := token.Claims.(jwt.MapClaims)
 	if !ok || !token.Valid {
-		r.httpRespError(ctx, http.StatusUnauthorized, errors.New("failed to claim token"))
+		r.httpRespError(ctx, http.StatusUnauthorized, apperror.Unauthorized("failed to claim token").WithKey("auth.invalid_token"))
+		return
+	}
+
+	userID, ok := claim["id"].(float64)
+	if !ok {
+		r.httpRespError(ctx, http.StatusUnauthorized, apperror.Unauthorized("invalid token").WithKey("auth.invalid_token"))
 		return
 	}
 
 	user := entity.User{}
-	user, err = r.uc.User.GetById(uint(claim["id"].(float64)))
+	user, err = r.uc.User.GetById(ctx.Request.Context(), uint(userID))
 	if err != nil {
-		r.httpRespError(ctx, http.StatusUnauthorized, errors.New("error while getting user"))
+		r.httpRespError(ctx, http.StatusUnauthorized, apperror.Wrap(apperror.CodeUnauthorized, "error while getting user", err).WithKey("auth.user_not_found"))
+		return
+	}
+
+	if !allowPendingMFA && !user.MFAEnabled && r.auth.IsMFARequired(user.Role) {
+		r.httpRespError(ctx, http.StatusForbidden, apperror.Forbidden("mfa enrolment is required for this account").WithKey("auth.mfa_required"))
 		return
 	}
 
@@ -76,6 +151,22 @@ func (r *rest) VerifyUser(ctx *gin.Context) {
 	ctx.Next()
 }
 
+// VerifyAdmin must run after VerifyUser, it only lets admins through.
+func (r *rest) VerifyAdmin(ctx *gin.Context) {
+	user, err := r.auth.GetUserAuthInfo(ctx.Request.Context())
+	if err != nil {
+		r.httpRespError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if !user.User.IsAdmin {
+		r.httpRespError(ctx, http.StatusForbidden, apperror.Forbidden("admin access is required").WithKey("auth.admin_required"))
+		return
+	}
+
+	ctx.Next()
+}
+
 func (r *rest) ValidateToken(encodedToken string) (*jwt.Token, error) {
 	token, err := jwt.Parse(encodedToken, func(t *jwt.Token) (interface{}, error) {
 		_, ok := t.Method.(*jwt.SigningMethodHMAC)
@@ -101,7 +192,7 @@ func (r *rest) VerifyTransaction(ctx *gin.Context) {
 	transactionIDp := ctx.Param("transaction_id")
 	umkmID, _ := strconv.Atoi(transactionIDp)
 
-	if err := r.uc.Transaction.ValidateTransaction(ctx, uint(umkmID), user); err != nil {
+	if err := r.uc.Transaction.ValidateTransaction(ctx.Request.Context(), uint(umkmID), user); err != nil {
 		r.httpRespError(ctx, http.StatusUnauthorized, err)
 		return
 	}
@@ -0,0 +1,37 @@
+package rest
+
+import (
+	"go-clean/src/business/entity"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get Invoice
+// @Description Download the PDF invoice of a paid Order, the invoice is numbered on the first download
+// @Security BearerAuth
+// @Tags Transaction
+// @Produce application/pdf
+// @Param transaction_id path integer true "transaction id"
+// @Success 200 {file} file
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 409 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/transaction/{transaction_id}/invoice [GET]
+func (r *rest) GetInvoice(ctx *gin.Context) {
+	var param entity.InvoiceParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	invoice, err := r.uc.Invoice.GetPDF(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespFile(ctx, invoice)
+}
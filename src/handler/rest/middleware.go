@@ -0,0 +1,324 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-clean/src/lib/apperror"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/redis"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	rateLimitKey = `synapsis:ratelimit:%s:%s`
+
+	rateLimitDefaultGroup = "default"
+
+	corsModeDefault  = "default"
+	corsModeAllowAll = "allowall"
+
+	headerRequestID    = "X-Request-ID"
+	maxRequestIDLength = 128
+
+	// maxLoggedBodySize caps the request and response bodies written to the
+	// log, anything longer is truncated.
+	maxLoggedBodySize = 4096
+	redactedValue     = "[REDACTED]"
+)
+
+// RequestID reuses the X-Request-ID header sent by the client or generates a
+// new one, and stores it in the request context so every log line carries it.
+func (r *rest) RequestID(ctx *gin.Context) {
+	requestID := ctx.GetHeader(headerRequestID)
+	if !isValidRequestID(requestID) {
+		id, err := gonanoid.New()
+		if err != nil {
+			r.log.Error(ctx.Request.Context(), "failed to generate request id", "error", err)
+		}
+		requestID = id
+	}
+
+	ctx.Header(headerRequestID, requestID)
+	ctx.Request = ctx.Request.WithContext(log.SetRequestID(ctx.Request.Context(), requestID))
+	ctx.Next()
+}
+
+// Language picks the response language from the Accept-Language header and
+// stores it in the request context.
+func (r *rest) Language(ctx *gin.Context) {
+	lang := r.i18n.Match(ctx.GetHeader("Accept-Language"))
+
+	ctx.Header("Content-Language", lang)
+	ctx.Request = ctx.Request.WithContext(r.i18n.SetLanguage(ctx.Request.Context(), lang))
+	ctx.Next()
+}
+
+// Trace starts the server span of the request, continuing the trace of the
+// caller when it sent a traceparent header.
+func (r *rest) Trace(ctx *gin.Context) {
+	c := r.tracer.Extract(ctx.Request.Context(), ctx.Request.Header)
+
+	spanName := ctx.FullPath()
+	if spanName == "" {
+		spanName = "unmatched"
+	}
+
+	c, span := r.tracer.Start(c, ctx.Request.Method+" "+spanName,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPMethod(ctx.Request.Method),
+			semconv.HTTPRoute(spanName),
+			attribute.String(log.KeyRequestID, log.GetRequestID(c)),
+		),
+	)
+	defer span.End()
+
+	ctx.Request = ctx.Request.WithContext(c)
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	span.SetAttributes(semconv.HTTPStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
+
+// LogRequest writes one log line per request, with the request and response
+// bodies when LogRequest and LogResponse are enabled. Secrets are redacted.
+func (r *rest) LogRequest(ctx *gin.Context) {
+	start := time.Now()
+	conf := r.config()
+
+	var reqBody []byte
+	if conf.LogRequest && ctx.Request.Body != nil {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			r.log.Error(ctx.Request.Context(), "failed to read request body", "error", err)
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		reqBody = body
+	}
+
+	var respWriter *bodyLogWriter
+	if conf.LogResponse {
+		respWriter = &bodyLogWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = respWriter
+	}
+
+	ctx.Next()
+
+	keyvals := []interface{}{
+		"method", ctx.Request.Method,
+		"path", ctx.Request.URL.Path,
+		"status", ctx.Writer.Status(),
+		"latency", time.Since(start).String(),
+		"ip", ctx.ClientIP(),
+	}
+	if conf.LogRequest && len(reqBody) > 0 {
+		keyvals = append(keyvals, "request_body", redactBody(reqBody))
+	}
+	if respWriter != nil && respWriter.body.Len() > 0 {
+		keyvals = append(keyvals, "response_body", redactBody(respWriter.body.Bytes()))
+	}
+
+	switch status := ctx.Writer.Status(); {
+	case status >= http.StatusInternalServerError:
+		r.log.Error(ctx.Request.Context(), "http request", keyvals...)
+	case status >= http.StatusBadRequest:
+		r.log.Warn(ctx.Request.Context(), "http request", keyvals...)
+	default:
+		r.log.Info(ctx.Request.Context(), "http request", keyvals...)
+	}
+}
+
+// CORS applies the cors policy of the current CORS.Mode, so a mode change in a
+// reloaded config takes effect on the next request.
+func (r *rest) CORS(ctx *gin.Context) {
+	handler, ok := r.cors[r.config().CORS.Mode]
+	if !ok {
+		handler = r.cors[corsModeDefault]
+	}
+
+	handler(ctx)
+}
+
+// RateLimit limits requests of a route group with a sliding window kept in
+// redis. Requests with a valid token are keyed by user id, the rest by ip.
+func (r *rest) RateLimit(group string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		conf := r.config().RateLimit
+		if !conf.Enabled {
+			ctx.Next()
+			return
+		}
+
+		rule, ok := conf.Groups[group]
+		if !ok {
+			rule, ok = conf.Groups[rateLimitDefaultGroup]
+		}
+		if !ok || rule.Limit <= 0 || rule.Window <= 0 {
+			ctx.Next()
+			return
+		}
+
+		key := fmt.Sprintf(rateLimitKey, group, r.rateLimitSubject(ctx))
+		result, err := r.redis.SlidingWindow(ctx.Request.Context(), key, rule.Limit, rule.Window)
+		if errors.Is(err, redis.ErrUnavailable) {
+			// the outage is logged once by the redis circuit breaker
+			ctx.Next()
+			return
+		}
+		if err != nil {
+			r.log.Error(ctx.Request.Context(), "failed to check rate limit", "error", err)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		ctx.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+
+		if !result.Allowed {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			r.httpRespError(ctx, http.StatusTooManyRequests, apperror.New(apperror.CodeTooManyRequests, "too many requests").WithKey("error.too_many_requests"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func (r *rest) rateLimitSubject(ctx *gin.Context) string {
+	tokenString := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if tokenString == "" {
+		return "ip:" + ctx.ClientIP()
+	}
+
+	token, err := r.ValidateToken(tokenString)
+	if err != nil || !token.Valid {
+		return "ip:" + ctx.ClientIP()
+	}
+
+	claim, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "ip:" + ctx.ClientIP()
+	}
+
+	userID, ok := claim["id"].(float64)
+	if !ok {
+		return "ip:" + ctx.ClientIP()
+	}
+
+	return fmt.Sprintf("user:%d", uint(userID))
+}
+
+// Metrics observes the request duration by route template, requests that did
+// not match any route share the "unmatched" label.
+func (r *rest) Metrics(ctx *gin.Context) {
+	start := time.Now()
+
+	ctx.Next()
+
+	route := ctx.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	r.metrics.ObserveHTTPRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+}
+
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	if room := maxLoggedBodySize - w.body.Len(); room > 0 {
+		if len(b) < room {
+			room = len(b)
+		}
+		w.body.Write(b[:room])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range requestID {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// redactBody returns the body as a string with the values of secret looking
+// keys replaced. Bodies that are not json are only logged by their size.
+func redactBody(body []byte) string {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+
+	redacted, err := json.Marshal(redactValue(data))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+
+	if len(redacted) > maxLoggedBodySize {
+		return string(redacted[:maxLoggedBodySize]) + "..."
+	}
+
+	return string(redacted)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if isSecretKey(k) {
+				val[k] = redactedValue
+				continue
+			}
+			val[k] = redactValue(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactValue(item)
+		}
+	}
+
+	return v
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "_", ""))
+	switch key {
+	case "code", "recoverycodes", "otpauthuri", "signaturekey", "authorization":
+		return true
+	}
+
+	return strings.Contains(key, "password") || strings.Contains(key, "token") || strings.Contains(key, "secret")
+}
@@ -27,13 +27,13 @@ func (r *rest) GetPaymentDetail(ctx *gin.Context) {
 		return
 	}
 
-	result, err := r.uc.MidtransTransaction.GetPaymentDetail(param)
+	result, err := r.uc.MidtransTransaction.GetPaymentDetail(ctx.Request.Context(), param)
 	if err != nil {
 		r.httpRespError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfully get payment detail", result)
+	r.httpRespSuccess(ctx, http.StatusOK, "payment.get.success", result)
 }
 
 func (r *rest) HandleNotification(ctx *gin.Context) {
@@ -44,10 +44,10 @@ func (r *rest) HandleNotification(ctx *gin.Context) {
 		return
 	}
 
-	if err := r.uc.MidtransTransaction.HandleNotification(notifPayload); err != nil {
+	if err := r.uc.MidtransTransaction.HandleNotification(ctx.Request.Context(), notifPayload); err != nil {
 		r.httpRespError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfully handle transaction", nil)
+	r.httpRespSuccess(ctx, http.StatusOK, "payment.notification.success", nil)
 }
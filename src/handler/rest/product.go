@@ -33,7 +33,7 @@ func (r *rest) GetListProduct(ctx *gin.Context) {
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfully get list all product", products)
+	r.httpRespSuccess(ctx, http.StatusOK, "product.list.success", products)
 }
 
 // @Summary Get Product
@@ -61,5 +61,5 @@ func (r *rest) GetProduct(ctx *gin.Context) {
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfully get a product", product)
+	r.httpRespSuccess(ctx, http.StatusOK, "product.get.success", product)
 }
@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"go-clean/src/business/entity"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create Refund
+// @Description Refund items of a paid order through midtrans, the whole remaining amount is refunded when no items are given
+// @Security BearerAuth
+// @Tags Admin
+// @Param transaction_id path int true "transaction id"
+// @Param refund body entity.CreateRefundParam true "refund info"
+// @Produce json
+// @Success 201 {object} entity.Response{data=entity.Refund{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 409 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/transactions/{transaction_id}/refunds [POST]
+func (r *rest) CreateRefund(ctx *gin.Context) {
+	var param entity.RefundParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var createParam entity.CreateRefundParam
+	if err := ctx.ShouldBindJSON(&createParam); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	refund, err := r.uc.Refund.Create(ctx.Request.Context(), param, createParam)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusCreated, "refund.create.success", refund)
+}
+
+// @Summary Get List Refund
+// @Description Get All Refunds of an Order
+// @Security BearerAuth
+// @Tags Admin
+// @Param transaction_id path int true "transaction id"
+// @Produce json
+// @Success 200 {object} entity.Response{data=[]entity.Refund{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/transactions/{transaction_id}/refunds [GET]
+func (r *rest) GetListRefund(ctx *gin.Context) {
+	var param entity.RefundParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	refunds, err := r.uc.Refund.GetList(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "refund.list.success", refunds)
+}
@@ -0,0 +1,198 @@
+package rest
+
+import (
+	"go-clean/src/business/entity"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get Revenue Report
+// @Description Get the paid orders and revenue by day, ISO week or month, from and to default to the last 30 days
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json,text/csv
+// @Param from query string false "first day, 2006-01-02"
+// @Param to query string false "last day, 2006-01-02"
+// @Param interval query string false "day, week or month"
+// @Param format query string false "json or csv"
+// @Success 200 {object} entity.Response{data=[]entity.RevenueReport{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/reports/revenue [GET]
+func (r *rest) GetRevenueReport(ctx *gin.Context) {
+	var param entity.ReportParam
+	if err := ctx.ShouldBindQuery(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if param.Format == entity.ReportFormatCSV {
+		r.exportReport(ctx, entity.ReportRevenue, param)
+		return
+	}
+
+	reports, err := r.uc.Report.Revenue(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "report.revenue.success", reports)
+}
+
+// @Summary Get Top Products Report
+// @Description Get the best selling products by units or revenue
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json,text/csv
+// @Param from query string false "first day, 2006-01-02"
+// @Param to query string false "last day, 2006-01-02"
+// @Param sort_by query string false "units or revenue"
+// @Param limit query integer false "number of products, 10 by default"
+// @Param format query string false "json or csv"
+// @Success 200 {object} entity.Response{data=[]entity.ProductReport{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/reports/products [GET]
+func (r *rest) GetProductReport(ctx *gin.Context) {
+	var param entity.ReportParam
+	if err := ctx.ShouldBindQuery(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if param.Format == entity.ReportFormatCSV {
+		r.exportReport(ctx, entity.ReportProducts, param)
+		return
+	}
+
+	reports, err := r.uc.Report.TopProducts(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "report.products.success", reports)
+}
+
+// @Summary Get Top Categories Report
+// @Description Get the best selling categories by units or revenue
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json,text/csv
+// @Param from query string false "first day, 2006-01-02"
+// @Param to query string false "last day, 2006-01-02"
+// @Param sort_by query string false "units or revenue"
+// @Param limit query integer false "number of categories, 10 by default"
+// @Param format query string false "json or csv"
+// @Success 200 {object} entity.Response{data=[]entity.CategoryReport{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/reports/categories [GET]
+func (r *rest) GetCategoryReport(ctx *gin.Context) {
+	var param entity.ReportParam
+	if err := ctx.ShouldBindQuery(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if param.Format == entity.ReportFormatCSV {
+		r.exportReport(ctx, entity.ReportCategories, param)
+		return
+	}
+
+	reports, err := r.uc.Report.TopCategories(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "report.categories.success", reports)
+}
+
+// @Summary Get Payment Method Report
+// @Description Get the paid orders and revenue by payment method
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json,text/csv
+// @Param from query string false "first day, 2006-01-02"
+// @Param to query string false "last day, 2006-01-02"
+// @Param format query string false "json or csv"
+// @Success 200 {object} entity.Response{data=[]entity.PaymentMethodReport{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/reports/payment-methods [GET]
+func (r *rest) GetPaymentMethodReport(ctx *gin.Context) {
+	var param entity.ReportParam
+	if err := ctx.ShouldBindQuery(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if param.Format == entity.ReportFormatCSV {
+		r.exportReport(ctx, entity.ReportPaymentMethods, param)
+		return
+	}
+
+	reports, err := r.uc.Report.PaymentMethods(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "report.payment_methods.success", reports)
+}
+
+// @Summary Get Sales Summary
+// @Description Get the paid orders, revenue, average order value and the conversion from cart to payment
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json,text/csv
+// @Param from query string false "first day, 2006-01-02"
+// @Param to query string false "last day, 2006-01-02"
+// @Param format query string false "json or csv"
+// @Success 200 {object} entity.Response{data=entity.SalesSummary{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/reports/summary [GET]
+func (r *rest) GetSalesSummary(ctx *gin.Context) {
+	var param entity.ReportParam
+	if err := ctx.ShouldBindQuery(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if param.Format == entity.ReportFormatCSV {
+		r.exportReport(ctx, entity.ReportSummary, param)
+		return
+	}
+
+	summary, err := r.uc.Report.Summary(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "report.summary.success", summary)
+}
+
+func (r *rest) exportReport(ctx *gin.Context, report string, param entity.ReportParam) {
+	file, err := r.uc.Report.Export(ctx.Request.Context(), report, param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespFile(ctx, file)
+}
@@ -7,18 +7,26 @@ import (
 	"go-clean/src/business/usecase"
 	"go-clean/src/lib/auth"
 	"go-clean/src/lib/configreader"
+	"go-clean/src/lib/i18n"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
+	"go-clean/src/lib/midtrans"
+	"go-clean/src/lib/redis"
+	"go-clean/src/lib/tracer"
 	"go-clean/src/utils/config"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"gorm.io/gorm"
 )
 
 var once = &sync.Once{}
@@ -31,11 +39,27 @@ type rest struct {
 	http         *gin.Engine
 	conf         config.GinConfig
 	configreader configreader.Interface
+	log          log.Interface
+	metrics      metrics.Interface
+	tracer       tracer.Interface
 	uc           *usecase.Usecase
 	auth         auth.Interface
+	redis        redis.Interface
+	db           *gorm.DB
+	midtrans     midtrans.Interface
+	i18n         i18n.Interface
+	// shuttingDown fails readiness once a shutdown signal is received.
+	shuttingDown int32
+	// shutdown is closed when the server starts shutting down, long lived
+	// responses such as event streams end on it since Shutdown does not
+	// cancel the request contexts.
+	shutdown chan struct{}
+	// confMu guards conf, which is replaced when the config file is reloaded.
+	confMu sync.RWMutex
+	cors   map[string]gin.HandlerFunc
 }
 
-func Init(conf config.GinConfig, confReader configreader.Interface, uc *usecase.Usecase, auth auth.Interface) REST {
+func Init(conf config.GinConfig, confReader configreader.Interface, log log.Interface, metrics metrics.Interface, tracer tracer.Interface, uc *usecase.Usecase, auth auth.Interface, redis redis.Interface, db *gorm.DB, midtrans midtrans.Interface, i18n i18n.Interface) REST {
 	r := &rest{}
 	once.Do(func() {
 		switch conf.Mode {
@@ -47,19 +71,32 @@ func Init(conf config.GinConfig, confReader configreader.Interface, uc *usecase.
 			gin.SetMode("")
 		}
 
-		httpServ := gin.Default()
+		httpServ := gin.New()
+
+		if err := registerValidation(); err != nil {
+			log.Fatal(context.Background(), "register validation", "error", err)
+		}
 
 		r = &rest{
 			conf:         conf,
 			configreader: confReader,
+			log:          log,
+			metrics:      metrics,
+			tracer:       tracer,
 			http:         httpServ,
 			uc:           uc,
 			auth:         auth,
+			redis:        redis,
+			db:           db,
+			midtrans:     midtrans,
+			i18n:         i18n,
+			shutdown:     make(chan struct{}),
 		}
 
-		switch r.conf.CORS.Mode {
-		case "allowall":
-			r.http.Use(cors.New(cors.Config{
+		r.http.Use(r.RequestID, r.Language, r.Trace, r.LogRequest, r.Metrics)
+
+		r.cors = map[string]gin.HandlerFunc{
+			corsModeAllowAll: cors.New(cors.Config{
 				AllowAllOrigins: true,
 				AllowHeaders:    []string{"*"},
 				AllowMethods: []string{
@@ -70,15 +107,17 @@ func Init(conf config.GinConfig, confReader configreader.Interface, uc *usecase.
 					http.MethodPatch,
 					http.MethodDelete,
 				},
-			}))
-		default:
-			r.http.Use(cors.New(cors.DefaultConfig()))
+			}),
+			corsModeDefault: cors.New(cors.DefaultConfig()),
 		}
+		r.http.Use(r.CORS)
 
 		// Set Recovery
 		r.http.Use(gin.Recovery())
 
 		r.Register()
+
+		r.configreader.Subscribe(r.reloadConfig)
 	})
 
 	return r
@@ -94,39 +133,81 @@ func (r *rest) Run() {
 		Addr:    port,
 		Handler: r.http,
 	}
+	server.RegisterOnShutdown(func() {
+		close(r.shutdown)
+	})
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Println(fmt.Sprintf("Serving HTTP error: %s", err.Error()))
+			r.log.Error(context.Background(), "serving http error", "error", err)
 		}
 	}()
-	fmt.Println(fmt.Sprintf("Listening and Serving HTTP on %s", server.Addr))
+	r.log.Info(context.Background(), "listening and serving http", "address", server.Addr)
 
 	// Wait for interrupt signal to gracefully shutdown the server with
 	// a timeout of 5 seconds.
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscall.SIGTERM
 	// kill -2 is syscall.SIGINT
 	// kill -9 is syscall.SIGKILL but can't be caught, so don't need to add it
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	atomic.StoreInt32(&r.shuttingDown, 1)
+	conf := r.config()
+	r.log.Info(context.Background(), "shutting down server", "drain_delay", conf.Health.ShutdownDelay.String())
+
+	// Keep serving while readiness reports failure so load balancers stop
+	// sending new traffic before the listener is closed.
+	time.Sleep(conf.Health.ShutdownDelay)
 
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
-	ctx, cancel := context.WithTimeout(context.Background(), r.conf.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal(fmt.Sprintf("Server forced to shutdown: %v", err))
+		r.log.Error(ctx, "server forced to shutdown", "error", err)
+		server.Close()
+	}
+
+	// the traces are flushed even when the server was forced to shutdown and
+	// ctx is done already
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
+	defer flushCancel()
+
+	if err := r.tracer.Shutdown(flushCtx); err != nil {
+		r.log.Error(ctx, "failed to flush traces", "error", err)
+	}
+
+	r.log.Info(ctx, "server exiting")
+}
+
+// reloadConfig applies a reloaded config to the middlewares and health checks.
+// Port, Mode and Timeout are only read on start and need a restart.
+func (r *rest) reloadConfig(cfg interface{}) {
+	app, ok := cfg.(*config.Application)
+	if !ok {
+		return
 	}
 
-	log.Println("Server exiting")
+	r.confMu.Lock()
+	defer r.confMu.Unlock()
+	r.conf = app.Gin
+}
+
+func (r *rest) config() config.GinConfig {
+	r.confMu.RLock()
+	defer r.confMu.RUnlock()
+	return r.conf
 }
 
 func (r *rest) Register() {
 	r.registerSwaggerRoutes()
-	publicApi := r.http.Group("/public")
+	r.http.GET("/metrics", gin.WrapH(r.metrics.Handler()))
+	r.http.GET("/healthz", r.Liveness)
+	r.http.GET("/readyz", r.Readiness)
+
+	publicApi := r.http.Group("/public", r.RateLimit("public"))
 	publicApi.GET("/", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{
 			"msg": "hello world",
@@ -136,34 +217,87 @@ func (r *rest) Register() {
 	api := r.http.Group("/api")
 	v1 := api.Group("/v1")
 
-	v1.GET("/", r.VerifyUser, func(ctx *gin.Context) {
+	v1.GET("/", r.RateLimit("api"), r.VerifyUser, func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{
 			"msg": "hello from api",
 		})
 	})
 
-	auth := v1.Group("/auth")
+	auth := v1.Group("/auth", r.RateLimit("auth"))
 	auth.POST("/register", r.RegisterUser)
 	auth.POST("/login", r.LoginUser)
+	auth.POST("/login/mfa", r.LoginUserMFA)
+
+	me := v1.Group("/me", r.RateLimit("api"))
+	me.GET("", r.VerifyUser, r.GetProfile)
+	me.PATCH("", r.VerifyUser, r.UpdateProfile)
+	me.PUT("/password", r.VerifyUser, r.ChangePassword)
+
+	mfa := me.Group("/mfa")
+	mfa.POST("/enroll", r.VerifyUserPendingMFA, r.EnrollMFA)
+	mfa.POST("/confirm", r.VerifyUserPendingMFA, r.ConfirmMFA)
+	mfa.POST("/recovery-codes", r.VerifyUser, r.RegenerateRecoveryCodes)
+
+	address := me.Group("/address")
+	address.GET("", r.VerifyUser, r.GetListAddress)
+	address.POST("", r.VerifyUser, r.CreateAddress)
+	address.GET("/:address_id", r.VerifyUser, r.GetAddress)
+	address.PATCH("/:address_id", r.VerifyUser, r.UpdateAddress)
+	address.DELETE("/:address_id", r.VerifyUser, r.DeleteAddress)
 
-	category := v1.Group("/category")
+	category := v1.Group("/category", r.RateLimit("api"))
 	category.GET("", r.VerifyUser, r.GetListCategory)
 
-	product := v1.Group("/product")
+	product := v1.Group("/product", r.RateLimit("api"))
 	product.GET("", r.VerifyUser, r.GetListProduct)
 	product.GET("/:product_id", r.VerifyUser, r.GetProduct)
 
-	cart := v1.Group("/cart")
+	cart := v1.Group("/cart", r.RateLimit("api"))
 	cart.POST("", r.VerifyUser, r.CreateCart)
 	cart.GET("", r.VerifyUser, r.GetListCart)
 	cart.DELETE("/:cart_id", r.VerifyUser, r.DeleteCart)
 
-	transaction := v1.Group("/transaction")
+	transaction := v1.Group("/transaction", r.RateLimit("api"))
 	transaction.POST("", r.VerifyUser, r.CreateOrder)
+	transaction.GET("/stream", r.VerifyUser, r.StreamTransactionStatus)
 	transaction.GET("/:transaction_id/payment-detail", r.VerifyUser, r.VerifyTransaction, r.GetPaymentDetail)
+	transaction.POST("/:transaction_id/cancel", r.VerifyUser, r.VerifyTransaction, r.CancelOrder)
+	transaction.GET("/:transaction_id/tracking", r.VerifyUser, r.VerifyTransaction, r.TrackOrder)
+	transaction.GET("/:transaction_id/invoice", r.VerifyUser, r.VerifyTransaction, r.GetInvoice)
+
+	shipping := v1.Group("/shipping", r.RateLimit("api"))
+	shipping.GET("/quote", r.VerifyUser, r.GetShippingQuote)
 
 	midtransTransaction := v1.Group("/midtrans-transaction")
 	midtransTransaction.POST("/handle", r.HandleNotification)
+
+	admin := v1.Group("/admin", r.RateLimit("api"), r.VerifyUser, r.VerifyAdmin)
+	admin.POST("/cache/flush", r.FlushCache)
+
+	webhook := admin.Group("/webhooks")
+	webhook.GET("", r.GetListWebhook)
+	webhook.POST("", r.CreateWebhook)
+	webhook.GET("/:webhook_id", r.GetWebhook)
+	webhook.PATCH("/:webhook_id", r.UpdateWebhook)
+	webhook.DELETE("/:webhook_id", r.DeleteWebhook)
+	webhook.GET("/:webhook_id/deliveries", r.GetListWebhookDelivery)
+	webhook.POST("/:webhook_id/deliveries/:delivery_id/redeliver", r.RedeliverWebhook)
+
+	refund := admin.Group("/transactions/:transaction_id/refunds")
+	refund.GET("", r.GetListRefund)
+	refund.POST("", r.CreateRefund)
+
+	shipment := admin.Group("/transactions/:transaction_id/shipments")
+	shipment.GET("", r.GetListShipment)
+	shipment.POST("", r.CreateShipment)
+	shipment.POST("/:shipment_id/deliver", r.DeliverShipment)
+
+	report := admin.Group("/reports")
+	report.GET("/revenue", r.GetRevenueReport)
+	report.GET("/products", r.GetProductReport)
+	report.GET("/categories", r.GetCategoryReport)
+	report.GET("/payment-methods", r.GetPaymentMethodReport)
+	report.GET("/summary", r.GetSalesSummary)
 }
 
 func (r *rest) registerSwaggerRoutes() {
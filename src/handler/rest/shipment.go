@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"go-clean/src/business/entity"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get Shipping Quote
+// @Description Price the delivery of the user's cart with every courier service, address_id prices it to a saved address
+// @Security BearerAuth
+// @Tags Shipping
+// @Produce json
+// @Param address_id query integer false "address id"
+// @Success 200 {object} entity.Response{data=[]entity.ShippingQuote{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/shipping/quote [GET]
+func (r *rest) GetShippingQuote(ctx *gin.Context) {
+	var param entity.ShippingQuoteParam
+	if err := ctx.ShouldBindQuery(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	quotes, err := r.uc.Shipment.Quote(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "shipping.quote.success", quotes)
+}
+
+// @Summary Track Order
+// @Description Get the fulfilment status and the shipments of an Order
+// @Security BearerAuth
+// @Tags Transaction
+// @Produce json
+// @Param transaction_id path integer true "transaction id"
+// @Success 200 {object} entity.Response{data=entity.Tracking{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/transaction/{transaction_id}/tracking [GET]
+func (r *rest) TrackOrder(ctx *gin.Context) {
+	var param entity.ShipmentParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	tracking, err := r.uc.Shipment.Track(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "shipment.track.success", tracking)
+}
+
+// @Summary Create Shipment
+// @Description Mark items of a paid order shipped with a courier and tracking number, everything not shipped yet is shipped when no items are given
+// @Security BearerAuth
+// @Tags Admin
+// @Param transaction_id path int true "transaction id"
+// @Param shipment body entity.CreateShipmentParam true "shipment info"
+// @Produce json
+// @Success 201 {object} entity.Response{data=entity.Shipment{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 409 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/transactions/{transaction_id}/shipments [POST]
+func (r *rest) CreateShipment(ctx *gin.Context) {
+	var param entity.ShipmentParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var createParam entity.CreateShipmentParam
+	if err := ctx.ShouldBindJSON(&createParam); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	shipment, err := r.uc.Shipment.Create(ctx.Request.Context(), param, createParam)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusCreated, "shipment.create.success", shipment)
+}
+
+// @Summary Get List Shipment
+// @Description Get All Shipments of an Order
+// @Security BearerAuth
+// @Tags Admin
+// @Param transaction_id path int true "transaction id"
+// @Produce json
+// @Success 200 {object} entity.Response{data=[]entity.Shipment{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/transactions/{transaction_id}/shipments [GET]
+func (r *rest) GetListShipment(ctx *gin.Context) {
+	var param entity.ShipmentParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	shipments, err := r.uc.Shipment.GetList(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "shipment.list.success", shipments)
+}
+
+// @Summary Deliver Shipment
+// @Description Mark a Shipment delivered
+// @Security BearerAuth
+// @Tags Admin
+// @Param transaction_id path int true "transaction id"
+// @Param shipment_id path int true "shipment id"
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.Shipment{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 409 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/transactions/{transaction_id}/shipments/{shipment_id}/deliver [POST]
+func (r *rest) DeliverShipment(ctx *gin.Context) {
+	var param entity.ShipmentParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	shipment, err := r.uc.Shipment.Deliver(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "shipment.deliver.success", shipment)
+}
@@ -1,12 +1,19 @@
 package rest
 
 import (
+	"fmt"
 	"go-clean/src/business/entity"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// sseHeartbeatInterval is how often an idle status stream sends a comment, so
+// proxies do not close the connection.
+const sseHeartbeatInterval = 15 * time.Second
+
 // @Summary Create Order
 // @Description Create New Order
 // @Security BearerAuth
@@ -32,5 +39,82 @@ func (r *rest) CreateOrder(ctx *gin.Context) {
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusCreated, "successfully created new order", gin.H{"id": id})
+	r.httpRespSuccess(ctx, http.StatusCreated, "transaction.create.success", gin.H{"id": id})
+}
+
+// @Summary Cancel Order
+// @Description Cancel an Order that is still waiting for payment, with restore_cart the items are put back into the cart
+// @Security BearerAuth
+// @Tags Transaction
+// @Produce json
+// @Param transaction_id path integer true "transaction id"
+// @Param restore_cart query boolean false "put the items back into the cart"
+// @Success 200 {object} entity.Response{}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 409 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/transaction/{transaction_id}/cancel [POST]
+func (r *rest) CancelOrder(ctx *gin.Context) {
+	var param entity.CancelTransactionParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := r.uc.Transaction.Cancel(ctx.Request.Context(), param); err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "transaction.cancel.success", nil)
+}
+
+// @Summary Stream Transaction Status
+// @Description Server-sent events of the payment status changes of the user's transactions, one "status" event per change
+// @Security BearerAuth
+// @Tags Transaction
+// @Produce text/event-stream
+// @Success 200 {object} entity.TransactionStatusUpdate{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/transaction/stream [GET]
+func (r *rest) StreamTransactionStatus(ctx *gin.Context) {
+	updates, err := r.uc.Transaction.StreamStatus(ctx.Request.Context())
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// updates is closed once the client disconnects and the request context
+	// is done, the stream also ends when the server shuts down
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-r.shutdown:
+			return false
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent("status", update)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		return true
+	})
 }
@@ -1,8 +1,13 @@
 package rest
 
 import (
+	"errors"
 	"go-clean/src/business/entity"
+	userUc "go-clean/src/business/usecase/user"
+	"go-clean/src/lib/apperror"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,13 +30,13 @@ func (r *rest) RegisterUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := r.uc.User.Create(userParam)
+	user, err := r.uc.User.Create(ctx.Request.Context(), userParam)
 	if err != nil {
 		r.httpRespError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusCreated, "successfully registered new user", user)
+	r.httpRespSuccess(ctx, http.StatusCreated, "user.register.success", user)
 }
 
 // @Summary Login User
@@ -39,10 +44,11 @@ func (r *rest) RegisterUser(ctx *gin.Context) {
 // @Tags Auth
 // @Param user body entity.LoginUserParam true "user info"
 // @Produce json
-// @Success 200 {object} entity.Response{}
+// @Success 200 {object} entity.Response{data=entity.LoginResult{}}
 // @Failure 400 {object} entity.Response{}
 // @Failure 401 {object} entity.Response{}
 // @Failure 404 {object} entity.Response{}
+// @Failure 429 {object} entity.Response{}
 // @Failure 500 {object} entity.Response{}
 // @Router /api/v1/auth/login [POST]
 func (r *rest) LoginUser(ctx *gin.Context) {
@@ -52,11 +58,208 @@ func (r *rest) LoginUser(ctx *gin.Context) {
 		return
 	}
 
-	token, err := r.uc.User.Login(userParam)
+	userParam.IPAddress = ctx.ClientIP()
+
+	result, err := r.uc.User.Login(ctx.Request.Context(), userParam)
+	if err != nil {
+		r.httpRespLoginError(ctx, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "user.login.success", result)
+}
+
+// @Summary Login User with MFA
+// @Description Exchange MFA Challenge Token and OTP or Recovery Code for Token
+// @Tags Auth
+// @Param user body entity.LoginMFAParam true "mfa info"
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.LoginResult{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 429 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/auth/login/mfa [POST]
+func (r *rest) LoginUserMFA(ctx *gin.Context) {
+	var param entity.LoginMFAParam
+	if err := ctx.ShouldBindJSON(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	param.IPAddress = ctx.ClientIP()
+
+	result, err := r.uc.User.LoginMFA(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespLoginError(ctx, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "user.login.success", result)
+}
+
+func (r *rest) httpRespLoginError(ctx *gin.Context, err error) {
+	var lockedErr *userUc.LockedError
+	if errors.As(err, &lockedErr) {
+		retryAfter := int(math.Ceil(lockedErr.RetryAfter.Seconds()))
+		ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+		r.httpRespError(ctx, http.StatusTooManyRequests, apperror.Wrap(apperror.CodeTooManyRequests, lockedErr.Error(), err).WithKey("auth.login_locked", retryAfter))
+		return
+	}
+
+	r.httpRespError(ctx, http.StatusInternalServerError, err)
+}
+
+// @Summary Get Profile
+// @Description Get Profile of the Logged in User
+// @Security BearerAuth
+// @Tags User
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.User{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me [GET]
+func (r *rest) GetProfile(ctx *gin.Context) {
+	user, err := r.uc.User.GetProfile(ctx.Request.Context())
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "user.profile.get.success", user)
+}
+
+// @Summary Update Profile
+// @Description Update Profile of the Logged in User
+// @Security BearerAuth
+// @Tags User
+// @Param user body entity.UpdateProfileParam true "profile info"
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.User{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me [PATCH]
+func (r *rest) UpdateProfile(ctx *gin.Context) {
+	var param entity.UpdateProfileParam
+	if err := ctx.ShouldBindJSON(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := r.uc.User.UpdateProfile(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "user.profile.update.success", user)
+}
+
+// @Summary Change Password
+// @Description Change Password of the Logged in User
+// @Security BearerAuth
+// @Tags User
+// @Param user body entity.ChangePasswordParam true "password info"
+// @Produce json
+// @Success 200 {object} entity.Response{}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/password [PUT]
+func (r *rest) ChangePassword(ctx *gin.Context) {
+	var param entity.ChangePasswordParam
+	if err := ctx.ShouldBindJSON(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := r.uc.User.ChangePassword(ctx.Request.Context(), param); err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "user.password.change.success", nil)
+}
+
+// @Summary Enroll MFA
+// @Description Generate TOTP Secret and otpauth URI for the Logged in User
+// @Security BearerAuth
+// @Tags User
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.MFAEnrolment{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/mfa/enroll [POST]
+func (r *rest) EnrollMFA(ctx *gin.Context) {
+	result, err := r.uc.User.EnrollMFA(ctx.Request.Context())
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "user.mfa.enrol.success", result)
+}
+
+// @Summary Confirm MFA
+// @Description Confirm MFA Enrolment with an OTP and Get Recovery Codes
+// @Security BearerAuth
+// @Tags User
+// @Param code body entity.MFACodeParam true "otp code"
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.MFARecoveryCodes{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/mfa/confirm [POST]
+func (r *rest) ConfirmMFA(ctx *gin.Context) {
+	var param entity.MFACodeParam
+	if err := ctx.ShouldBindJSON(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := r.uc.User.ConfirmMFA(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "user.mfa.enable.success", result)
+}
+
+// @Summary Regenerate MFA Recovery Codes
+// @Description Replace the MFA Recovery Codes, Requires an OTP
+// @Security BearerAuth
+// @Tags User
+// @Param code body entity.MFACodeParam true "otp code"
+// @Produce json
+// @Success 200 {object} entity.Response{data=entity.MFARecoveryCodes{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/me/mfa/recovery-codes [POST]
+func (r *rest) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var param entity.MFACodeParam
+	if err := ctx.ShouldBindJSON(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := r.uc.User.RegenerateRecoveryCodes(ctx.Request.Context(), param)
 	if err != nil {
 		r.httpRespError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	r.httpRespSuccess(ctx, http.StatusOK, "successfully login", gin.H{"token": token})
+	r.httpRespSuccess(ctx, http.StatusOK, "user.mfa.recovery_codes.success", result)
 }
@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-clean/src/business/entity"
+	"go-clean/src/lib/midtrans"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	passwordMinLength = 8
+
+	// validationMessageDefault is the catalog key of rules without a message.
+	validationMessageDefault = "validation.default"
+)
+
+var usernameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._]{2,29}$`)
+
+// registerValidation reports fields by their json name and adds the custom
+// rules to gin's validator.
+func registerValidation() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unsupported binding validator")
+	}
+
+	v.RegisterTagNameFunc(jsonFieldName)
+	if err := v.RegisterValidation("username", validateUsername); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("password", validatePassword); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("webhook_event", validateWebhookEvent); err != nil {
+		return err
+	}
+	return v.RegisterValidation("payment_method", validatePaymentMethod)
+}
+
+// jsonFieldName is the name of the field in the request body, fields without
+// a json tag keep their go name since that is what encoding/json matches.
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func validateUsername(fl validator.FieldLevel) bool {
+	return usernameRegex.MatchString(fl.Field().String())
+}
+
+func validatePassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len(password) < passwordMinLength {
+		return false
+	}
+
+	var upper, lower, digit bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+func validateWebhookEvent(fl validator.FieldLevel) bool {
+	return entity.IsWebhookEventType(fl.Field().String())
+}
+
+// validatePaymentMethod accepts the payment methods the gateway client knows
+// how to charge.
+func validatePaymentMethod(fl validator.FieldLevel) bool {
+	_, ok := midtrans.PaymentNames[int(fl.Field().Int())]
+	return ok
+}
+
+// fieldErrors translates binding errors into per field errors, ok is false
+// when err is not caused by an invalid field.
+func (r *rest) fieldErrors(ctx context.Context, err error) ([]entity.FieldError, bool) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		result := make([]entity.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			result = append(result, entity.FieldError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Message: r.validationMessage(ctx, fe.Tag(), fe.Param(), fe.Kind()),
+			})
+		}
+		return result, true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []entity.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: r.validationMessage(ctx, "type", typeErr.Type.Kind().String(), reflect.Invalid),
+		}}, true
+	}
+
+	return nil, false
+}
+
+// validationMessage is the message of the validation.<rule> catalog key. The
+// min, max and len rules of strings use validation.<rule>_length and those of
+// slices validation.<rule>_items, since their param is a count rather than a
+// value.
+func (r *rest) validationMessage(ctx context.Context, rule, param string, kind reflect.Kind) string {
+	key := "validation." + rule
+	switch kind {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if r.i18n.Has(key + "_items") {
+			key += "_items"
+		} else if r.i18n.Has(key + "_length") {
+			key += "_length"
+		}
+	case reflect.String:
+		if r.i18n.Has(key + "_length") {
+			key += "_length"
+		}
+	}
+	if !r.i18n.Has(key) {
+		return r.i18n.Translate(ctx, validationMessageDefault)
+	}
+
+	if param == "" {
+		return r.i18n.Translate(ctx, key)
+	}
+	return r.i18n.Translate(ctx, key, param)
+}
@@ -0,0 +1,208 @@
+package rest
+
+import (
+	"go-clean/src/business/entity"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create Webhook
+// @Description Subscribe a partner endpoint to order and payment events, payloads are signed with HMAC-SHA256 of the secret
+// @Security BearerAuth
+// @Tags Admin
+// @Param webhook body entity.CreateWebhookSubscriptionParam true "webhook info"
+// @Produce json
+// @Success 201 {object} entity.Response{data=entity.WebhookSubscription{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/webhooks [POST]
+func (r *rest) CreateWebhook(ctx *gin.Context) {
+	var param entity.CreateWebhookSubscriptionParam
+	if err := ctx.ShouldBindJSON(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	webhook, err := r.uc.Webhook.Create(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusCreated, "webhook.create.success", webhook)
+}
+
+// @Summary Get List Webhook
+// @Description Get All Webhook Subscriptions
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} entity.Response{data=[]entity.WebhookSubscription{}}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/webhooks [GET]
+func (r *rest) GetListWebhook(ctx *gin.Context) {
+	webhooks, err := r.uc.Webhook.GetList(ctx.Request.Context())
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "webhook.list.success", webhooks)
+}
+
+// @Summary Get Webhook
+// @Description Get a Webhook Subscription
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json
+// @Param webhook_id path int true "webhook id"
+// @Success 200 {object} entity.Response{data=entity.WebhookSubscription{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/webhooks/{webhook_id} [GET]
+func (r *rest) GetWebhook(ctx *gin.Context) {
+	var param entity.WebhookSubscriptionParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	webhook, err := r.uc.Webhook.Get(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "webhook.get.success", webhook)
+}
+
+// @Summary Update Webhook
+// @Description Update the url, secret, event types or active flag of a Webhook Subscription
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json
+// @Param webhook_id path int true "webhook id"
+// @Param webhook body entity.UpdateWebhookSubscriptionParam true "webhook info"
+// @Success 200 {object} entity.Response{data=entity.WebhookSubscription{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/webhooks/{webhook_id} [PATCH]
+func (r *rest) UpdateWebhook(ctx *gin.Context) {
+	var selectParam entity.WebhookSubscriptionParam
+	if err := ctx.ShouldBindUri(&selectParam); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var updateParam entity.UpdateWebhookSubscriptionParam
+	if err := ctx.ShouldBindJSON(&updateParam); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	webhook, err := r.uc.Webhook.Update(ctx.Request.Context(), selectParam, updateParam)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "webhook.update.success", webhook)
+}
+
+// @Summary Delete Webhook
+// @Description Delete a Webhook Subscription, its pending deliveries are dropped
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json
+// @Param webhook_id path int true "webhook id"
+// @Success 200 {object} entity.Response{}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/webhooks/{webhook_id} [DELETE]
+func (r *rest) DeleteWebhook(ctx *gin.Context) {
+	var param entity.WebhookSubscriptionParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := r.uc.Webhook.Delete(ctx.Request.Context(), param); err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "webhook.delete.success", nil)
+}
+
+// @Summary Get List Webhook Delivery
+// @Description Get the latest deliveries of a Webhook Subscription with their response and error
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json
+// @Param webhook_id path int true "webhook id"
+// @Success 200 {object} entity.Response{data=[]entity.WebhookDelivery{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/webhooks/{webhook_id}/deliveries [GET]
+func (r *rest) GetListWebhookDelivery(ctx *gin.Context) {
+	var param entity.WebhookDeliveryParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	deliveries, err := r.uc.Webhook.GetDeliveries(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "webhook.delivery.list.success", deliveries)
+}
+
+// @Summary Redeliver Webhook
+// @Description Send a Webhook Delivery again right away, whatever its status
+// @Security BearerAuth
+// @Tags Admin
+// @Produce json
+// @Param webhook_id path int true "webhook id"
+// @Param delivery_id path int true "delivery id"
+// @Success 200 {object} entity.Response{data=entity.WebhookDelivery{}}
+// @Failure 400 {object} entity.Response{}
+// @Failure 401 {object} entity.Response{}
+// @Failure 403 {object} entity.Response{}
+// @Failure 404 {object} entity.Response{}
+// @Failure 500 {object} entity.Response{}
+// @Router /api/v1/admin/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver [POST]
+func (r *rest) RedeliverWebhook(ctx *gin.Context) {
+	var param entity.WebhookDeliveryParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		r.httpRespError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	delivery, err := r.uc.Webhook.Redeliver(ctx.Request.Context(), param)
+	if err != nil {
+		r.httpRespError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	r.httpRespSuccess(ctx, http.StatusOK, "webhook.redeliver.success", delivery)
+}
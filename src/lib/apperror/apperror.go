@@ -0,0 +1,146 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+type Code string
+
+// Codes are stable and machine readable, clients may branch on them. Never
+// rename one, add a new code instead.
+const (
+	CodeInternal        Code = "INTERNAL"
+	CodeBadRequest      Code = "BAD_REQUEST"
+	CodeValidation      Code = "VALIDATION"
+	CodeUnauthorized    Code = "UNAUTHORIZED"
+	CodeForbidden       Code = "FORBIDDEN"
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeConflict        Code = "CONFLICT"
+	CodeTooManyRequests Code = "TOO_MANY_REQUESTS"
+	CodePaymentFailed   Code = "PAYMENT_FAILED"
+	CodeUnavailable     Code = "UNAVAILABLE"
+)
+
+var statusByCode = map[Code]int{
+	CodeInternal:        http.StatusInternalServerError,
+	CodeBadRequest:      http.StatusBadRequest,
+	CodeValidation:      http.StatusBadRequest,
+	CodeUnauthorized:    http.StatusUnauthorized,
+	CodeForbidden:       http.StatusForbidden,
+	CodeNotFound:        http.StatusNotFound,
+	CodeConflict:        http.StatusConflict,
+	CodeTooManyRequests: http.StatusTooManyRequests,
+	CodePaymentFailed:   http.StatusBadGateway,
+	CodeUnavailable:     http.StatusServiceUnavailable,
+}
+
+// Error is an error with a code and a message that is safe to show to the
+// client. The wrapped Err is for the logs only. Key and Args pick the message
+// from the i18n catalog, Message is the english fallback.
+type Error struct {
+	Code    Code
+	Message string
+	Key     string
+	Args    []interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + " : " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus is the response status of the code.
+func (e *Error) HTTPStatus() int {
+	if status, ok := statusByCode[e.Code]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// WithKey sets the catalog key of the client message.
+func (e *Error) WithKey(key string, args ...interface{}) *Error {
+	e.Key = key
+	e.Args = args
+	return e
+}
+
+func New(code Code, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Wrap keeps err as the cause of an error with the code and message.
+func Wrap(code Code, message string, err error) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Err:     err,
+	}
+}
+
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(CodeConflict, message)
+}
+
+func Validation(message string) *Error {
+	return New(CodeValidation, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, message)
+}
+
+func PaymentFailed(message string, err error) *Error {
+	return Wrap(CodePaymentFailed, message, err)
+}
+
+// As returns the *Error in the chain of err.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+
+	return nil, false
+}
+
+// Is reports whether err carries the code.
+func Is(err error, code Code) bool {
+	appErr, ok := As(err)
+	return ok && appErr.Code == code
+}
+
+// CodeFromStatus is the code of an untyped error answered with status.
+func CodeFromStatus(status int) Code {
+	for code, s := range statusByCode {
+		if s == status && code != CodeValidation {
+			return code
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+
+	return CodeBadRequest
+}
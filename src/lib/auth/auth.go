@@ -2,17 +2,31 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type contextKey string
 
 const (
 	userAuthInfo contextKey = "UserAuthInfo"
+
+	mfaChallengeTokenType = "mfa_challenge"
+	recoveryCodeAlphabet  = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoveryCodeLength    = 10
+	// totpPeriod is the seconds a TOTP code is generated for.
+	totpPeriod = 30
 )
 
 type Interface interface {
@@ -20,13 +34,55 @@ type Interface interface {
 	GetUserAuthInfo(ctx context.Context) (UserAuthInfo, error)
 	GenerateToken(user User) (string, error)
 	GenerateGuestToken() (string, error)
+	HashPassword(password string) (string, error)
+	ComparePassword(hashedPassword string, password string) error
+	// CompareDummyPassword spends the time of ComparePassword on a fixed hash,
+	// so a login for an unknown username is as slow as a wrong password.
+	CompareDummyPassword(password string)
+	NeedRehash(hashedPassword string) bool
+	IsMFARequired(role string) bool
+	GenerateMFAChallengeToken(user User) (string, error)
+	ParseMFAChallengeToken(token string) (uint, error)
+	GenerateTOTPKey(accountName string) (TOTPKey, error)
+	// ValidateTOTP checks code against secret and returns the time step it
+	// was generated for, a step is accepted once by the caller.
+	ValidateTOTP(secret string, code string) (int64, bool)
+	GenerateRecoveryCodes(n int) ([]string, error)
+	HashRecoveryCode(code string) string
+}
+
+type Config struct {
+	BcryptCost int
+	MFA        MFAConfig
+}
+
+type MFAConfig struct {
+	Issuer        string
+	RequiredRoles []string
+	ChallengeTTL  time.Duration
 }
 
 type auth struct {
+	conf Config
+
+	dummyOnce sync.Once
+	dummyHash []byte
 }
 
-func Init() Interface {
-	return &auth{}
+func Init(cfg Config) Interface {
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	if cfg.MFA.Issuer == "" {
+		cfg.MFA.Issuer = "Synapsis"
+	}
+	if cfg.MFA.ChallengeTTL <= 0 {
+		cfg.MFA.ChallengeTTL = 5 * time.Minute
+	}
+
+	return &auth{
+		conf: cfg,
+	}
 }
 
 func (a *auth) SetUserAuthInfo(ctx context.Context, user User, token string) context.Context {
@@ -51,6 +107,7 @@ func (a *auth) GetUserAuthInfo(ctx context.Context) (UserAuthInfo, error) {
 func (a *auth) GenerateToken(user User) (string, error) {
 	claim := jwt.MapClaims{}
 	claim["id"] = user.ID
+	claim["role"] = user.Role
 	claim["is_admin"] = user.IsAdmin
 	claim["is_guest"] = false
 
@@ -77,3 +134,146 @@ func (a *auth) GenerateGuestToken() (string, error) {
 
 	return signedToken, nil
 }
+
+func (a *auth) HashPassword(password string) (string, error) {
+	hashPass, err := bcrypt.GenerateFromPassword([]byte(password), a.conf.BcryptCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashPass), nil
+}
+
+func (a *auth) ComparePassword(hashedPassword string, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+}
+
+func (a *auth) CompareDummyPassword(password string) {
+	// the hash is generated on first use at the configured cost, so the
+	// comparison costs the same as one against a stored hash
+	a.dummyOnce.Do(func() {
+		hash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), a.conf.BcryptCost)
+		if err != nil {
+			return
+		}
+		a.dummyHash = hash
+	})
+
+	_ = bcrypt.CompareHashAndPassword(a.dummyHash, []byte(password))
+}
+
+// NeedRehash reports whether the hash was generated with a lower cost than the
+// configured one, so it can be upgraded after a successful login.
+func (a *auth) NeedRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return false
+	}
+
+	return cost < a.conf.BcryptCost
+}
+
+func (a *auth) IsMFARequired(role string) bool {
+	for _, r := range a.conf.MFA.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GenerateMFAChallengeToken issues a short lived token proving the password
+// step succeeded. It can only be exchanged for a real token with a valid OTP.
+func (a *auth) GenerateMFAChallengeToken(user User) (string, error) {
+	claim := jwt.MapClaims{}
+	claim["sub"] = user.ID
+	claim["typ"] = mfaChallengeTokenType
+	claim["exp"] = time.Now().Add(a.conf.MFA.ChallengeTTL).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
+	signedToken, err := token.SignedString([]byte(os.Getenv("JWT_KEY")))
+	if err != nil {
+		return "", err
+	}
+
+	return signedToken, nil
+}
+
+func (a *auth) ParseMFAChallengeToken(encodedToken string) (uint, error) {
+	token, err := jwt.Parse(encodedToken, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("token invalid")
+		}
+		return []byte(os.Getenv("JWT_KEY")), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	claim, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claim["typ"] != mfaChallengeTokenType {
+		return 0, errors.New("invalid mfa challenge token")
+	}
+
+	userID, ok := claim["sub"].(float64)
+	if !ok {
+		return 0, errors.New("invalid mfa challenge token")
+	}
+
+	return uint(userID), nil
+}
+
+func (a *auth) GenerateTOTPKey(accountName string) (TOTPKey, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      a.conf.MFA.Issuer,
+		AccountName: accountName,
+	})
+	if err != nil {
+		return TOTPKey{}, err
+	}
+
+	return TOTPKey{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+	}, nil
+}
+
+func (a *auth) ValidateTOTP(secret string, code string) (int64, bool) {
+	now := time.Now()
+	// the current step first, then one step of clock skew each way as
+	// totp.Validate allows
+	for _, skew := range []int64{0, -1, 1} {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		ok, err := totp.ValidateCustom(code, secret, at, totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err == nil && ok {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+
+	return 0, false
+}
+
+func (a *auth) GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := []string{}
+	for i := 0; i < n; i++ {
+		code, err := gonanoid.Generate(recoveryCodeAlphabet, recoveryCodeLength)
+		if err != nil {
+			return codes, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode hashes a recovery code for storage. The codes are random
+// enough that a fast hash is sufficient.
+func (a *auth) HashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -11,5 +11,12 @@ type User struct {
 	Username string
 	Password string
 	Name     string
+	Email    string
+	Role     string
 	IsAdmin  bool
 }
+
+type TOTPKey struct {
+	Secret string
+	URI    string
+}
@@ -2,30 +2,61 @@ package configreader
 
 import (
 	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
+const (
+	defaultEnvPrefix = "APP"
+	fileEnvSuffix    = "_FILE"
+)
+
 type Interface interface {
-	ReadConfig(cfg interface{})
+	ReadConfig(cfg interface{}) error
+	WatchConfig(cfg interface{}, onError func(err error))
+	AddValidator(fn Validator)
+	Subscribe(fn Subscriber)
 }
 
+// Validator inspects a reloaded config before it is applied, returning an
+// error rejects the whole reload.
+type Validator func(cfg interface{}) error
+
+// Subscriber receives a reloaded config once every validator accepted it.
+type Subscriber func(cfg interface{})
+
 type Options struct {
+	// ConfigFile is optional, without it the config comes from env only.
 	ConfigFile string
+	// EnvPrefix prefixes the env var of every key, e.g. APP_SQL_PASSWORD
+	// overrides SQL.Password. Defaults to APP.
+	EnvPrefix string
 }
 
 type configReader struct {
 	viper *viper.Viper
 	opt   Options
+
+	mu          sync.Mutex
+	validators  []Validator
+	subscribers []Subscriber
 }
 
 func Init(opt Options) Interface {
+	if opt.EnvPrefix == "" {
+		opt.EnvPrefix = defaultEnvPrefix
+	}
+
 	v := viper.New()
-	v.SetConfigFile(opt.ConfigFile)
 	v.SetConfigType("json")
-	if err := v.ReadInConfig(); err != nil {
-		panic(fmt.Errorf("fatal error found during reading file. err : %w", err))
-	}
+	v.SetEnvPrefix(opt.EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
 
 	c := &configReader{
 		viper: v,
@@ -35,8 +66,161 @@ func Init(opt Options) Interface {
 	return c
 }
 
-func (c *configReader) ReadConfig(cfg interface{}) {
-	if err := c.viper.Unmarshal(&cfg); err != nil {
-		panic(fmt.Errorf("fatal error found during unmarshaling config. err: %w", err))
+// ReadConfig fills cfg from the config file, then from env vars. A key can
+// also be read from the file named by its env var with the _FILE suffix, for
+// secrets mounted as files.
+func (c *configReader) ReadConfig(cfg interface{}) error {
+	if c.opt.ConfigFile != "" {
+		c.viper.SetConfigFile(c.opt.ConfigFile)
+		if err := c.viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file %s : %w", c.opt.ConfigFile, err)
+		}
+	}
+
+	keys := []string{}
+	collectKeys(reflect.TypeOf(cfg), "", &keys)
+	for _, key := range keys {
+		if err := c.bindEnv(key); err != nil {
+			return err
+		}
+	}
+
+	if err := c.viper.Unmarshal(cfg); err != nil {
+		return fmt.Errorf("failed to decode config : %w", err)
+	}
+
+	return nil
+}
+
+// WatchConfig reloads the config file whenever it changes. Each reload is
+// decoded into a fresh value of the type of cfg, checked by the validators and
+// then handed to the subscribers, cfg itself is never modified.
+func (c *configReader) WatchConfig(cfg interface{}, onError func(err error)) {
+	if c.opt.ConfigFile == "" {
+		return
+	}
+
+	t := reflect.TypeOf(cfg)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	c.viper.OnConfigChange(func(e fsnotify.Event) {
+		next := reflect.New(t).Interface()
+		if err := c.reload(next); err != nil && onError != nil {
+			onError(fmt.Errorf("config reload from %s rejected : %w", e.Name, err))
+		}
+	})
+	c.viper.WatchConfig()
+}
+
+func (c *configReader) AddValidator(fn Validator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.validators = append(c.validators, fn)
+}
+
+func (c *configReader) Subscribe(fn Subscriber) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribers = append(c.subscribers, fn)
+}
+
+func (c *configReader) reload(cfg interface{}) error {
+	if err := c.viper.Unmarshal(cfg); err != nil {
+		return fmt.Errorf("failed to decode config : %w", err)
+	}
+
+	c.mu.Lock()
+	validators := append([]Validator{}, c.validators...)
+	subscribers := append([]Subscriber{}, c.subscribers...)
+	c.mu.Unlock()
+
+	for _, validate := range validators {
+		if err := validate(cfg); err != nil {
+			return err
+		}
 	}
+
+	for _, notify := range subscribers {
+		notify(cfg)
+	}
+
+	return nil
+}
+
+func (c *configReader) bindEnv(key string) error {
+	env := c.envName(key)
+	if err := c.viper.BindEnv(key, env); err != nil {
+		return err
+	}
+
+	path, ok := os.LookupEnv(env + fileEnvSuffix)
+	if !ok {
+		return nil
+	}
+
+	if _, ok := os.LookupEnv(env); ok {
+		return fmt.Errorf("both %s and %s%s are set, use only one", env, env, fileEnvSuffix)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s%s : %w", env, fileEnvSuffix, err)
+	}
+	c.viper.Set(key, strings.TrimRight(string(content), "\r\n"))
+
+	return nil
+}
+
+func (c *configReader) envName(key string) string {
+	return c.opt.EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// collectKeys lists the viper key of every leaf field of t. Maps are skipped,
+// their entries can only be overridden by env when present in the file.
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := strings.ToLower(field.Name)
+		if field.Anonymous {
+			key = ""
+		}
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		} else if prefix != "" {
+			key = prefix
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		switch {
+		case ft.Kind() == reflect.Map:
+			continue
+		case ft.Kind() == reflect.Struct && !isLeafStruct(ft):
+			collectKeys(ft, key, keys)
+			continue
+		}
+
+		*keys = append(*keys, key)
+	}
+}
+
+func isLeafStruct(t reflect.Type) bool {
+	return t.PkgPath() == "time"
 }
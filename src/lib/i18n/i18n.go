@@ -0,0 +1,166 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"go-clean/src/lib/log"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+type contextKey string
+
+const (
+	languageKey contextKey = "Language"
+
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
+// locales holds one flat key to message catalog per language, named after
+// the language tag, e.g. locales/id.json.
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+type Interface interface {
+	// Match returns the supported language closest to an Accept-Language
+	// header, or the default language.
+	Match(acceptLanguage string) string
+	SetLanguage(ctx context.Context, lang string) context.Context
+	GetLanguage(ctx context.Context) string
+	// Translate returns the message of key in the language of ctx, formatted
+	// with args. A key missing from the language falls back to the default
+	// language, then to the key itself.
+	Translate(ctx context.Context, key string, args ...interface{}) string
+	Has(key string) bool
+}
+
+type Config struct {
+	DefaultLanguage string
+}
+
+type i18n struct {
+	conf      Config
+	catalogs  map[string]map[string]string
+	languages []string
+	matcher   language.Matcher
+}
+
+func Init(cfg Config, log log.Interface) Interface {
+	if cfg.DefaultLanguage == "" {
+		cfg.DefaultLanguage = LanguageEnglish
+	}
+
+	catalogs, err := load()
+	if err != nil {
+		log.Fatal(context.Background(), "load message catalogs", "error", err)
+	}
+
+	if _, ok := catalogs[cfg.DefaultLanguage]; !ok {
+		log.Fatal(context.Background(), "default language has no message catalog", "language", cfg.DefaultLanguage)
+	}
+
+	// the default language goes first, the matcher falls back to it
+	languages := []string{cfg.DefaultLanguage}
+	for lang := range catalogs {
+		if lang != cfg.DefaultLanguage {
+			languages = append(languages, lang)
+		}
+	}
+	sort.Strings(languages[1:])
+
+	tags := make([]language.Tag, 0, len(languages))
+	for _, lang := range languages {
+		tags = append(tags, language.Make(lang))
+
+		for key := range catalogs[cfg.DefaultLanguage] {
+			if _, ok := catalogs[lang][key]; !ok {
+				log.Warn(context.Background(), "message is not translated", "language", lang, "key", key)
+			}
+		}
+	}
+
+	return &i18n{
+		conf:      cfg,
+		catalogs:  catalogs,
+		languages: languages,
+		matcher:   language.NewMatcher(tags),
+	}
+}
+
+func load() (map[string]map[string]string, error) {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	catalogs := map[string]map[string]string{}
+	for _, f := range files {
+		raw, err := locales.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		catalog := map[string]string{}
+		if err := json.Unmarshal(raw, &catalog); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name(), err)
+		}
+		catalogs[strings.TrimSuffix(f.Name(), path.Ext(f.Name()))] = catalog
+	}
+
+	return catalogs, nil
+}
+
+func (i *i18n) Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return i.conf.DefaultLanguage
+	}
+
+	_, index, confidence := i.matcher.Match(tags...)
+	if confidence == language.No {
+		return i.conf.DefaultLanguage
+	}
+
+	return i.languages[index]
+}
+
+func (i *i18n) SetLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey, lang)
+}
+
+func (i *i18n) GetLanguage(ctx context.Context) string {
+	lang, ok := ctx.Value(languageKey).(string)
+	if !ok || lang == "" {
+		return i.conf.DefaultLanguage
+	}
+
+	return lang
+}
+
+func (i *i18n) Translate(ctx context.Context, key string, args ...interface{}) string {
+	message, ok := i.catalogs[i.GetLanguage(ctx)][key]
+	if !ok {
+		message, ok = i.catalogs[i.conf.DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+
+	return message
+}
+
+func (i *i18n) Has(key string) bool {
+	_, ok := i.catalogs[i.conf.DefaultLanguage][key]
+	return ok
+}
@@ -0,0 +1,24 @@
+package log
+
+import "context"
+
+const (
+	KeyRequestID = "request_id"
+)
+
+type contextKey string
+
+const requestIDKey contextKey = "request_id"
+
+func SetRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func GetRequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+type Interface interface {
+	Debug(ctx context.Context, msg string, keyvals ...interface{})
+	Info(ctx context.Context, msg string, keyvals ...interface{})
+	Warn(ctx context.Context, msg string, keyvals ...interface{})
+	Error(ctx context.Context, msg string, keyvals ...interface{})
+	Fatal(ctx context.Context, msg string, keyvals ...interface{})
+	SetLevel(level string) error
+}
+
+type Config struct {
+	// Level is one of trace, debug, info, warn, error, fatal or disabled.
+	Level string
+	// Format is either json or console.
+	Format string
+}
+
+type logger struct {
+	mu  sync.RWMutex
+	log zerolog.Logger
+}
+
+func Init(cfg Config) Interface {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		level = zerolog.InfoLevel
+	}
+
+	var w io.Writer = os.Stdout
+	if cfg.Format == FormatConsole {
+		w = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+
+	return &logger{
+		log: zerolog.New(w).Level(level).With().Timestamp().Logger(),
+	}
+}
+
+func (l *logger) Debug(ctx context.Context, msg string, keyvals ...interface{}) {
+	log := l.logger()
+	l.write(ctx, log.Debug(), msg, keyvals)
+}
+
+func (l *logger) Info(ctx context.Context, msg string, keyvals ...interface{}) {
+	log := l.logger()
+	l.write(ctx, log.Info(), msg, keyvals)
+}
+
+func (l *logger) Warn(ctx context.Context, msg string, keyvals ...interface{}) {
+	log := l.logger()
+	l.write(ctx, log.Warn(), msg, keyvals)
+}
+
+func (l *logger) Error(ctx context.Context, msg string, keyvals ...interface{}) {
+	log := l.logger()
+	l.write(ctx, log.Error(), msg, keyvals)
+}
+
+func (l *logger) Fatal(ctx context.Context, msg string, keyvals ...interface{}) {
+	log := l.logger()
+	l.write(ctx, log.Fatal(), msg, keyvals)
+}
+
+// SetLevel changes the level of every following log line, so the verbosity
+// can be raised on a running instance.
+func (l *logger) SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.log = l.log.Level(lvl)
+
+	return nil
+}
+
+func (l *logger) logger() *zerolog.Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	log := l.log
+	return &log
+}
+
+func (l *logger) write(ctx context.Context, event *zerolog.Event, msg string, keyvals []interface{}) {
+	if event == nil {
+		return
+	}
+
+	if requestID := GetRequestID(ctx); requestID != "" {
+		event = event.Str(KeyRequestID, requestID)
+	}
+
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if err, ok := keyvals[i+1].(error); ok {
+			keyvals[i+1] = err.Error()
+		}
+	}
+
+	event.Fields(keyvals).Msg(msg)
+}
+
+func parseLevel(level string) (zerolog.Level, error) {
+	if level == "" {
+		return zerolog.InfoLevel, nil
+	}
+
+	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return lvl, fmt.Errorf("invalid log level %q : %w", level, err)
+	}
+
+	return lvl, nil
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	gormPluginName   = "metrics"
+	gormStartTimeKey = "metrics:start_time"
+)
+
+// gormPlugin times every gorm callback chain and reports it to ObserveDBQuery.
+type gormPlugin struct {
+	metrics *metrics
+}
+
+func (m *metrics) GormPlugin() gorm.Plugin {
+	return &gormPlugin{metrics: m}
+}
+
+func (p *gormPlugin) Name() string {
+	return gormPluginName
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	chains := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, c := range chains {
+		if err := c.before("metrics:before_"+c.operation, p.before); err != nil {
+			return err
+		}
+		if err := c.after("metrics:after_"+c.operation, p.after(c.operation)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *gormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormStartTimeKey, time.Now())
+}
+
+func (p *gormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartTimeKey)
+		if !ok {
+			return
+		}
+
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		err := db.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		p.metrics.ObserveDBQuery(operation, table, time.Since(start), err)
+	}
+}
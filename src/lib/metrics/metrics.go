@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheStale = "stale"
+	CacheOK    = "ok"
+	CacheError = "error"
+
+	CacheOperationGet = "get"
+	CacheOperationSet = "set"
+)
+
+type Interface interface {
+	Handler() http.Handler
+	GormPlugin() gorm.Plugin
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+	ObserveDBQuery(operation, table string, duration time.Duration, err error)
+	IncCache(cache, operation, result string)
+	ObservePaymentGateway(operation string, duration time.Duration, err error)
+	ObserveWebhookDelivery(eventType string, duration time.Duration, err error)
+	IncOrdersCreated()
+	IncPaymentsSettled()
+}
+
+type Config struct {
+	Namespace string
+}
+
+type metrics struct {
+	conf     Config
+	registry *prometheus.Registry
+
+	httpDuration    *prometheus.HistogramVec
+	dbDuration      *prometheus.HistogramVec
+	dbErrors        *prometheus.CounterVec
+	cacheTotal      *prometheus.CounterVec
+	gatewayDuration *prometheus.HistogramVec
+	gatewayErrors   *prometheus.CounterVec
+	webhookDuration *prometheus.HistogramVec
+	webhookErrors   *prometheus.CounterVec
+	ordersCreated   prometheus.Counter
+	paymentsSettled prometheus.Counter
+}
+
+func Init(cfg Config) Interface {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "synapsis"
+	}
+
+	m := &metrics{
+		conf:     cfg,
+		registry: prometheus.NewRegistry(),
+	}
+	m.register()
+
+	return m
+}
+
+func (m *metrics) register() {
+	m.httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.conf.Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of http requests by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	m.dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.conf.Namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	m.dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.conf.Namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Number of failed database queries by operation and table.",
+	}, []string{"operation", "table"})
+
+	m.cacheTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.conf.Namespace,
+		Subsystem: "cache",
+		Name:      "operations_total",
+		Help:      "Number of cache operations by cache, operation and result.",
+	}, []string{"cache", "operation", "result"})
+
+	m.gatewayDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.conf.Namespace,
+		Subsystem: "payment_gateway",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of payment gateway calls by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	m.gatewayErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.conf.Namespace,
+		Subsystem: "payment_gateway",
+		Name:      "errors_total",
+		Help:      "Number of failed payment gateway calls by operation.",
+	}, []string{"operation"})
+
+	m.webhookDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.conf.Namespace,
+		Subsystem: "webhook",
+		Name:      "delivery_duration_seconds",
+		Help:      "Duration of outgoing webhook deliveries by event type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"event_type"})
+
+	m.webhookErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.conf.Namespace,
+		Subsystem: "webhook",
+		Name:      "delivery_errors_total",
+		Help:      "Number of failed outgoing webhook deliveries by event type.",
+	}, []string{"event_type"})
+
+	m.ordersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: m.conf.Namespace,
+		Name:      "orders_created_total",
+		Help:      "Number of orders created.",
+	})
+
+	m.paymentsSettled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: m.conf.Namespace,
+		Name:      "payments_settled_total",
+		Help:      "Number of payments settled.",
+	})
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.dbDuration,
+		m.dbErrors,
+		m.cacheTotal,
+		m.gatewayDuration,
+		m.gatewayErrors,
+		m.webhookDuration,
+		m.webhookErrors,
+		m.ordersCreated,
+		m.paymentsSettled,
+	)
+}
+
+func (m *metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func (m *metrics) ObserveDBQuery(operation, table string, duration time.Duration, err error) {
+	m.dbDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+	if err != nil {
+		m.dbErrors.WithLabelValues(operation, table).Inc()
+	}
+}
+
+func (m *metrics) IncCache(cache, operation, result string) {
+	m.cacheTotal.WithLabelValues(cache, operation, result).Inc()
+}
+
+func (m *metrics) ObservePaymentGateway(operation string, duration time.Duration, err error) {
+	m.gatewayDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	if err != nil {
+		m.gatewayErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func (m *metrics) ObserveWebhookDelivery(eventType string, duration time.Duration, err error) {
+	m.webhookDuration.WithLabelValues(eventType).Observe(duration.Seconds())
+	if err != nil {
+		m.webhookErrors.WithLabelValues(eventType).Inc()
+	}
+}
+
+func (m *metrics) IncOrdersCreated() {
+	m.ordersCreated.Inc()
+}
+
+func (m *metrics) IncPaymentsSettled() {
+	m.paymentsSettled.Inc()
+}
@@ -8,6 +8,12 @@ const (
 	GopayPayment = 1
 )
 
+// PaymentNames are the payment methods as printed for customers, e.g. on an
+// invoice.
+var PaymentNames = map[int]string{
+	GopayPayment: "GoPay",
+}
+
 type CreateOrderParam struct {
 	PaymentID       int
 	OrderID         uint
@@ -1,17 +1,31 @@
 package midtrans
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
+	"go-clean/src/lib/tracer"
+	"net/http"
 	"time"
 
 	midtransSdk "github.com/midtrans/midtrans-go"
 	"github.com/midtrans/midtrans-go/coreapi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Interface interface {
-	CreateOrder(param CreateOrderParam) (*coreapi.ChargeResponse, error)
-	HandleNotification(id string) (*coreapi.TransactionStatusResponse, error)
+	CreateOrder(ctx context.Context, param CreateOrderParam) (*coreapi.ChargeResponse, error)
+	HandleNotification(ctx context.Context, id string) (*coreapi.TransactionStatusResponse, error)
+	// CancelOrder cancels a charge that is not paid yet.
+	CancelOrder(ctx context.Context, id string) (*coreapi.CancelResponse, error)
+	// RefundOrder refunds req.Amount of a settled charge, the refund key of
+	// req makes a retried refund idempotent at midtrans.
+	RefundOrder(ctx context.Context, id string, req *coreapi.RefundReq) (*coreapi.RefundResponse, error)
+	Ping(ctx context.Context) error
 }
 
 type Config struct {
@@ -20,12 +34,18 @@ type Config struct {
 
 type midtrans struct {
 	conf    Config
+	log     log.Interface
+	metrics metrics.Interface
+	tracer  tracer.Interface
 	coreapi *coreapi.Client
 }
 
-func Init(cfg Config) Interface {
+func Init(cfg Config, log log.Interface, metrics metrics.Interface, tracer tracer.Interface) Interface {
 	m := &midtrans{
-		conf: cfg,
+		conf:    cfg,
+		log:     log,
+		metrics: metrics,
+		tracer:  tracer,
 	}
 	m.connect()
 	return m
@@ -37,8 +57,7 @@ func (m *midtrans) connect() {
 	m.coreapi = &c
 }
 
-func (m *midtrans) CreateOrder(param CreateOrderParam) (*coreapi.ChargeResponse, error) {
-	fmt.Printf("isi param : %#v\n", param)
+func (m *midtrans) CreateOrder(ctx context.Context, param CreateOrderParam) (*coreapi.ChargeResponse, error) {
 	chargeReq := &coreapi.ChargeReq{
 		TransactionDetails: midtransSdk.TransactionDetails{
 			OrderID:  fmt.Sprintf("%s-%d-%d", "SYN", param.OrderID, time.Now().Unix()),
@@ -57,19 +76,97 @@ func (m *midtrans) CreateOrder(param CreateOrderParam) (*coreapi.ChargeResponse,
 		return &coreapi.ChargeResponse{}, errors.New("undeifned payment method")
 	}
 
+	m.log.Debug(ctx, "midtrans charge transaction", "order_id", chargeReq.TransactionDetails.OrderID, "payment_type", chargeReq.PaymentType, "gross_amount", param.GrossAmount)
+
+	_, span := m.tracer.Start(ctx, "midtrans.ChargeTransaction", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("midtrans.order_id", chargeReq.TransactionDetails.OrderID)))
+	defer span.End()
+
+	start := time.Now()
 	coreApiRes, err := m.coreapi.ChargeTransaction(chargeReq)
+	m.metrics.ObservePaymentGateway("charge_transaction", time.Since(start), gatewayError(err))
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return coreApiRes, err
 	}
 
 	return coreApiRes, nil
 }
 
-func (m *midtrans) HandleNotification(id string) (*coreapi.TransactionStatusResponse, error) {
+func (m *midtrans) HandleNotification(ctx context.Context, id string) (*coreapi.TransactionStatusResponse, error) {
+	_, span := m.tracer.Start(ctx, "midtrans.CheckTransaction", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("midtrans.order_id", id)))
+	defer span.End()
+
+	start := time.Now()
 	midtransReport, err := m.coreapi.CheckTransaction(id)
+	m.metrics.ObservePaymentGateway("check_transaction", time.Since(start), gatewayError(err))
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return midtransReport, err
 	}
 
 	return midtransReport, nil
 }
+
+func (m *midtrans) CancelOrder(ctx context.Context, id string) (*coreapi.CancelResponse, error) {
+	_, span := m.tracer.Start(ctx, "midtrans.CancelTransaction", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("midtrans.order_id", id)))
+	defer span.End()
+
+	start := time.Now()
+	cancelRes, err := m.coreapi.CancelTransaction(id)
+	m.metrics.ObservePaymentGateway("cancel_transaction", time.Since(start), gatewayError(err))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return cancelRes, err
+	}
+
+	return cancelRes, nil
+}
+
+func (m *midtrans) RefundOrder(ctx context.Context, id string, req *coreapi.RefundReq) (*coreapi.RefundResponse, error) {
+	_, span := m.tracer.Start(ctx, "midtrans.RefundTransaction", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("midtrans.order_id", id), attribute.String("midtrans.refund_key", req.RefundKey)))
+	defer span.End()
+
+	start := time.Now()
+	refundRes, err := m.coreapi.RefundTransaction(id, req)
+	m.metrics.ObservePaymentGateway("refund_transaction", time.Since(start), gatewayError(err))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return refundRes, err
+	}
+
+	return refundRes, nil
+}
+
+// Ping checks that the gateway api is reachable, any http response counts as
+// reachable since the request is not authenticated.
+func (m *midtrans) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.coreapi.Env.BaseUrl(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// gatewayError converts the sdk error pointer into an error interface without
+// turning a nil pointer into a non nil error.
+func gatewayError(err *midtransSdk.Error) error {
+	if err == nil {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,34 @@
+package migration
+
+import "time"
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version uint64
+	Name    string
+	Applied bool
+	// Dirty is set when the migration failed halfway, AppliedAt is then the
+	// time of the last statement that succeeded.
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Name    string `gorm:"size:255"`
+	// Statements counts the statements of the up file already applied,
+	// Dirty is set until all of them are.
+	Statements int
+	Dirty      bool
+	AppliedAt  time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
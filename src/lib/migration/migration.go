@@ -0,0 +1,371 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-clean/src/lib/log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	lockName = "synapsis_schema_migrations"
+
+	// errNoSuchTable is the mysql error of a query on a missing table.
+	errNoSuchTable = 1146
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Interface interface {
+	Up(ctx context.Context) error
+	Down(ctx context.Context, steps int) error
+	Status(ctx context.Context) ([]Status, error)
+	Adopt(ctx context.Context) error
+}
+
+type Config struct {
+	// Dir holds the numbered <version>_<name>.up.sql and .down.sql files.
+	Dir string
+	// LockTimeout is how long to wait for another replica to finish
+	// migrating before giving up.
+	LockTimeout time.Duration
+}
+
+type migration struct {
+	conf Config
+	db   *gorm.DB
+	log  log.Interface
+}
+
+func Init(cfg Config, db *gorm.DB, log log.Interface) Interface {
+	if cfg.Dir == "" {
+		cfg.Dir = "./etc/migrations"
+	}
+
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = time.Minute
+	}
+
+	m := &migration{
+		conf: cfg,
+		db:   db,
+		log:  log,
+	}
+
+	return m
+}
+
+// Up applies every pending migration in version order. Progress is recorded
+// after each statement, so a migration that failed halfway resumes from the
+// statement that failed instead of running the earlier ones again.
+func (m *migration) Up(ctx context.Context) error {
+	migrations, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	return m.withLock(ctx, func(tx *gorm.DB) error {
+		applied, err := m.applied(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, mg := range migrations {
+			record, ok := applied[mg.Version]
+			if ok && !record.Dirty {
+				continue
+			}
+			if !ok {
+				record = schemaMigration{Version: mg.Version, Name: mg.Name}
+			}
+
+			stmts := splitStatements(mg.Up)
+			m.log.Info(ctx, "applying migration", "version", mg.Version, "name", mg.Name, "from_statement", record.Statements+1)
+			for i := record.Statements; i < len(stmts); i++ {
+				if err := tx.Exec(stmts[i]).Error; err != nil {
+					return fmt.Errorf("failed to apply statement %d of migration %d_%s : %w", i+1, mg.Version, mg.Name, err)
+				}
+
+				record.Statements = i + 1
+				record.Dirty = record.Statements < len(stmts)
+				record.AppliedAt = time.Now()
+				if err := m.save(tx, record); err != nil {
+					return err
+				}
+			}
+
+			// a migration without statements still has to be recorded
+			if len(stmts) == 0 {
+				record.AppliedAt = time.Now()
+				if err := m.save(tx, record); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m *migration) Down(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return errors.New("steps must be greater than zero")
+	}
+
+	migrations, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	byVersion := make(map[uint64]Migration, len(migrations))
+	for _, mg := range migrations {
+		byVersion[mg.Version] = mg
+	}
+
+	return m.withLock(ctx, func(tx *gorm.DB) error {
+		applied := []schemaMigration{}
+		if err := tx.Order("version desc").Limit(steps).Find(&applied).Error; err != nil {
+			return err
+		}
+
+		for _, a := range applied {
+			if a.Dirty {
+				return fmt.Errorf("migration %d_%s is partially applied, repair it by hand before reverting", a.Version, a.Name)
+			}
+
+			mg, ok := byVersion[a.Version]
+			if !ok || mg.Down == "" {
+				return fmt.Errorf("no down migration found for version %d", a.Version)
+			}
+
+			m.log.Info(ctx, "reverting migration", "version", mg.Version, "name", mg.Name)
+			if err := m.exec(tx, mg.Down); err != nil {
+				return fmt.Errorf("failed to revert migration %d_%s : %w", mg.Version, mg.Name, err)
+			}
+
+			if err := tx.Delete(&schemaMigration{}, a.Version).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status lists every migration with whether it is applied. It changes
+// nothing, so it is safe to run next to a deploy.
+func (m *migration) Status(ctx context.Context) ([]Status, error) {
+	result := []Status{}
+
+	migrations, err := m.load()
+	if err != nil {
+		return result, err
+	}
+
+	// the table is read as it is, its schema is only changed under the lock
+	// by Up and Down. Without the table nothing is applied yet.
+	applied, err := m.applied(m.db.WithContext(ctx))
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchTable {
+		applied, err = map[uint64]schemaMigration{}, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	for _, mg := range migrations {
+		status := Status{
+			Version: mg.Version,
+			Name:    mg.Name,
+		}
+		if a, ok := applied[mg.Version]; ok {
+			appliedAt := a.AppliedAt
+			status.Applied = !a.Dirty
+			status.Dirty = a.Dirty
+			status.AppliedAt = &appliedAt
+		}
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+// Adopt records every migration as applied without running it. It is used
+// after gorm AutoMigrate already synced the schema from the entities, so a
+// later Up does not add the same columns again.
+func (m *migration) Adopt(ctx context.Context) error {
+	migrations, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	return m.withLock(ctx, func(tx *gorm.DB) error {
+		applied, err := m.applied(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, mg := range migrations {
+			if a, ok := applied[mg.Version]; ok && !a.Dirty {
+				continue
+			}
+
+			if err := m.save(tx, schemaMigration{
+				Version:    mg.Version,
+				Name:       mg.Name,
+				Statements: len(splitStatements(mg.Up)),
+				AppliedAt:  time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// withLock runs fn on a single connection holding a mysql named lock, so only
+// one replica migrates at a time.
+func (m *migration) withLock(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(tx *gorm.DB) error {
+		var locked int
+		if err := tx.Raw("SELECT GET_LOCK(?, ?)", lockName, int(m.conf.LockTimeout.Seconds())).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if locked != 1 {
+			return errors.New("timed out waiting for the migration lock")
+		}
+		defer func() {
+			if err := tx.Exec("SELECT RELEASE_LOCK(?)", lockName).Error; err != nil {
+				m.log.Error(ctx, "failed to release migration lock", "error", err)
+			}
+		}()
+
+		if err := tx.AutoMigrate(&schemaMigration{}); err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+func (m *migration) applied(db *gorm.DB) (map[uint64]schemaMigration, error) {
+	rows := []schemaMigration{}
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64]schemaMigration, len(rows))
+	for _, r := range rows {
+		result[r.Version] = r
+	}
+
+	return result, nil
+}
+
+// save inserts the record of a migration or updates the one left by an
+// earlier partial run.
+func (m *migration) save(db *gorm.DB, record schemaMigration) error {
+	return db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&record).Error
+}
+
+// exec runs every statement of a migration file, mysql does not accept
+// several statements in one query without multiStatements on the dsn.
+func (m *migration) exec(db *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *migration) load() ([]Migration, error) {
+	entries, err := os.ReadDir(m.conf.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := os.ReadFile(filepath.Join(m.conf.Dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mg
+		}
+		if mg.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, mg.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			mg.Up = string(content)
+		} else {
+			mg.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		if mg.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mg.Version, mg.Name)
+		}
+		result = append(result, *mg)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+func splitStatements(script string) []string {
+	result := []string{}
+	for _, stmt := range strings.Split(script, ";") {
+		lines := []string{}
+		for _, line := range strings.Split(stmt, "\n") {
+			if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "--") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+
+		if len(lines) > 0 {
+			result = append(result, strings.Join(lines, "\n"))
+		}
+	}
+
+	return result
+}
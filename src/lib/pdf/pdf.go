@@ -0,0 +1,239 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// A4 in points, the origin is the bottom left corner of the page.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+const (
+	AlignLeft = iota
+	AlignRight
+)
+
+type Interface interface {
+	// Render writes doc as a PDF 1.4 file. Text is set in the standard
+	// Helvetica fonts, so no font is embedded and characters outside
+	// Latin-1 are printed as '?'.
+	Render(doc Document) ([]byte, error)
+}
+
+type Config struct {
+	// Compress deflates the page contents.
+	Compress bool
+}
+
+type Document struct {
+	Title string
+	Pages []Page
+}
+
+type Page struct {
+	Texts []Text
+	Lines []Line
+}
+
+type Text struct {
+	// X is the left edge of the text, or its right edge with AlignRight. Y is
+	// the baseline.
+	X     float64
+	Y     float64
+	Size  float64
+	Bold  bool
+	Align int
+	Value string
+}
+
+type Line struct {
+	X1    float64
+	Y1    float64
+	X2    float64
+	Y2    float64
+	Width float64
+}
+
+type pdf struct {
+	conf Config
+}
+
+func Init(cfg Config) Interface {
+	p := &pdf{
+		conf: cfg,
+	}
+
+	return p
+}
+
+func (p *pdf) Render(doc Document) ([]byte, error) {
+	pages := doc.Pages
+	if len(pages) == 0 {
+		pages = []Page{{}}
+	}
+
+	// objects 1 to 5 are fixed, every page adds a page and a contents object
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title %s /Producer (go-clean) >>", literal(doc.Title)),
+	}
+
+	kids := []string{}
+	for _, page := range pages {
+		stream, err := p.stream(content(page))
+		if err != nil {
+			return nil, err
+		}
+
+		pageID := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", PageWidth, PageHeight, pageID+1),
+			stream,
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes(), nil
+}
+
+func (p *pdf) stream(data []byte) (string, error) {
+	if !p.conf.Compress {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data), nil
+	}
+
+	buf := &bytes.Buffer{}
+	w := zlib.NewWriter(buf)
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", buf.Len(), buf.Bytes()), nil
+}
+
+func content(page Page) []byte {
+	buf := &bytes.Buffer{}
+
+	for _, l := range page.Lines {
+		width := l.Width
+		if width == 0 {
+			width = 0.5
+		}
+		fmt.Fprintf(buf, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, l.X1, l.Y1, l.X2, l.Y2)
+	}
+
+	for _, t := range page.Texts {
+		font := "F1"
+		if t.Bold {
+			font = "F2"
+		}
+
+		x := t.X
+		if t.Align == AlignRight {
+			x -= TextWidth(t.Value, t.Size, t.Bold)
+		}
+		fmt.Fprintf(buf, "BT /%s %.2f Tf %.2f %.2f Td %s Tj ET\n", font, t.Size, x, t.Y, literal(t.Value))
+	}
+
+	return buf.Bytes()
+}
+
+// literal is s as a PDF string in WinAnsiEncoding.
+func literal(s string) string {
+	buf := &strings.Builder{}
+	buf.WriteByte('(')
+	for _, c := range encode(s) {
+		switch c {
+		case '(', ')', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte(')')
+
+	return buf.String()
+}
+
+// encode maps s to Latin-1, the part of WinAnsiEncoding that matches
+// unicode.
+func encode(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < ' ' || (r > '~' && r < 0xa0) || r > 0xff {
+			r = '?'
+		}
+		b = append(b, byte(r))
+	}
+
+	return b
+}
+
+// TextWidth is the width of s in points, e.g. to right align or to cut a
+// text that does not fit a column.
+func TextWidth(s string, size float64, bold bool) float64 {
+	widths := helvetica
+	if bold {
+		widths = helveticaBold
+	}
+
+	total := 0
+	for _, c := range encode(s) {
+		if c >= ' ' && c <= '~' {
+			total += widths[c-' ']
+		} else {
+			total += 556
+		}
+	}
+
+	return float64(total) * size / 1000
+}
+
+// glyph widths of ' ' to '~' in thousandths of the font size, from the
+// Adobe font metrics of the standard fonts.
+var (
+	helvetica = [...]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBold = [...]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
@@ -0,0 +1,179 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"go-clean/src/lib/log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	breakerClosed = iota
+	breakerOpen
+	breakerHalfOpen
+
+	defaultFailureThreshold = 5
+	defaultBreakerCooldown  = 5 * time.Second
+)
+
+// ErrUnavailable is returned without calling redis while the circuit breaker
+// is open.
+var ErrUnavailable = errors.New("redis is unavailable")
+
+type BreakerConfig struct {
+	// FailureThreshold is the number of failed commands in a row that opens
+	// the breaker, defaults to 5.
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before a command is let
+	// through to probe redis again, defaults to 5 seconds.
+	Cooldown time.Duration
+}
+
+// breaker is a circuit breaker installed as a redis hook. Once open, commands
+// fail fast with ErrUnavailable instead of waiting on dial timeouts, until a
+// probe after the cooldown succeeds.
+type breaker struct {
+	conf BreakerConfig
+	log  log.Interface
+	// onClose runs when redis answers after failing, also when the failures
+	// stayed below the threshold and the breaker never opened.
+	onClose func()
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	changed  time.Time
+}
+
+func newBreaker(cfg BreakerConfig, log log.Interface, onClose func()) *breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultFailureThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultBreakerCooldown
+	}
+
+	return &breaker{
+		conf:    cfg,
+		log:     log,
+		onClose: onClose,
+	}
+}
+
+// allow reports whether a command may be sent. After the cooldown one probe
+// is let through, another one only when the probe did not report back in
+// time.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerClosed {
+		return true
+	}
+
+	if time.Since(b.changed) < b.conf.Cooldown {
+		return false
+	}
+
+	b.state, b.changed = breakerHalfOpen, time.Now()
+	return true
+}
+
+func (b *breaker) record(err error) {
+	if err != nil && !isConnectionError(err) {
+		// redis replied or the caller gave up, neither says redis is down
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.failures++
+		if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.conf.FailureThreshold) {
+			if b.state == breakerClosed {
+				b.log.Error(context.Background(), "redis circuit breaker opened", "failures", b.failures, "error", err)
+			}
+			b.state, b.changed = breakerOpen, time.Now()
+		}
+		return
+	}
+
+	recovered := b.failures > 0 || b.state != breakerClosed
+	b.failures = 0
+	if b.state != breakerClosed {
+		b.state, b.changed = breakerClosed, time.Now()
+		b.log.Info(context.Background(), "redis circuit breaker closed, reconnected to redis")
+	}
+	if recovered && b.onClose != nil {
+		b.onClose()
+	}
+}
+
+// trip opens the breaker right away, used when redis is down at boot.
+func (b *breaker) trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state, b.changed = breakerOpen, time.Now()
+}
+
+// failing reports whether the breaker is open or commands failed since the
+// last one that succeeded.
+func (b *breaker) failing() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state != breakerClosed || b.failures > 0
+}
+
+func (b *breaker) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (b *breaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !b.allow() {
+			cmd.SetErr(ErrUnavailable)
+			return ErrUnavailable
+		}
+
+		err := next(ctx, cmd)
+		b.record(err)
+		return err
+	}
+}
+
+func (b *breaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !b.allow() {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrUnavailable)
+			}
+			return ErrUnavailable
+		}
+
+		err := next(ctx, cmds)
+		b.record(err)
+		return err
+	}
+}
+
+// isConnectionError tells a redis outage from a reply error or a caller
+// cancelling its request.
+func isConnectionError(err error) bool {
+	var redisErr redis.Error
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &redisErr):
+		return false
+	case errors.Is(err, context.Canceled):
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, redis.ErrClosed) || errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
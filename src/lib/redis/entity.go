@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	RetryAfter time.Duration
+}
+
+type Message = redis.Message
+
+type subscription struct {
+	pubsub *redis.PubSub
+}
+
+func (s *subscription) Channel() <-chan *Message {
+	return s.pubsub.Channel()
+}
+
+func (s *subscription) Close() error {
+	return s.pubsub.Close()
+}
+
+// Pipeliner queues commands that are sent to redis in one round trip, see
+// Interface.Pipeline.
+type Pipeliner interface {
+	SetEX(key string, val string, expTime time.Duration)
+	Del(keys ...string)
+	Expire(key string, expTime time.Duration)
+	SAdd(key string, members ...string)
+}
+
+type pipeliner struct {
+	ctx  context.Context
+	pipe redis.Pipeliner
+}
+
+func (p *pipeliner) SetEX(key string, val string, expTime time.Duration) {
+	p.pipe.SetEx(p.ctx, key, val, expTime)
+}
+
+func (p *pipeliner) Del(keys ...string) {
+	p.pipe.Del(p.ctx, keys...)
+}
+
+func (p *pipeliner) Expire(key string, expTime time.Duration) {
+	p.pipe.Expire(p.ctx, key, expTime)
+}
+
+func (p *pipeliner) SAdd(key string, members ...string) {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	p.pipe.SAdd(p.ctx, key, args...)
+}
@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"container/list"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFallbackSize = 10000
+)
+
+type FallbackConfig struct {
+	// Size is the number of keys kept in memory while redis is unavailable,
+	// the least recently used key is evicted first. Defaults to 10000.
+	Size int
+}
+
+// fallback is a bounded in-process LRU that stands in for redis during an
+// outage. It only holds what was written during the outage and is emptied
+// once redis is back, so it never serves values older than the outage.
+type fallback struct {
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type fallbackEntry struct {
+	key      string
+	value    string
+	expireAt time.Time
+	tags     []string
+}
+
+func newFallback(cfg FallbackConfig) *fallback {
+	if cfg.Size <= 0 {
+		cfg.Size = defaultFallbackSize
+	}
+
+	return &fallback{
+		size:  cfg.Size,
+		ll:    list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+func (f *fallback) get(key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	el, ok := f.items[key]
+	if !ok {
+		return "", false
+	}
+
+	entry := el.Value.(*fallbackEntry)
+	if time.Now().After(entry.expireAt) {
+		f.remove(el)
+		return "", false
+	}
+
+	f.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (f *fallback) set(key string, value string, expTime time.Duration, tags ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry := &fallbackEntry{
+		key:      key,
+		value:    value,
+		expireAt: time.Now().Add(expTime),
+		tags:     tags,
+	}
+
+	if el, ok := f.items[key]; ok {
+		el.Value = entry
+		f.ll.MoveToFront(el)
+		return
+	}
+
+	f.items[key] = f.ll.PushFront(entry)
+	if f.ll.Len() > f.size {
+		f.remove(f.ll.Back())
+	}
+}
+
+func (f *fallback) del(keys ...string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var deleted int64
+	for _, key := range keys {
+		if el, ok := f.items[key]; ok {
+			f.remove(el)
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
+// delWhere deletes every entry matched by fn.
+func (f *fallback) delWhere(fn func(entry *fallbackEntry) bool) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var deleted int64
+	for el := f.ll.Front(); el != nil; {
+		next := el.Next()
+		if fn(el.Value.(*fallbackEntry)) {
+			f.remove(el)
+			deleted++
+		}
+		el = next
+	}
+
+	return deleted
+}
+
+func (f *fallback) delTag(tag string) int64 {
+	return f.delWhere(func(entry *fallbackEntry) bool {
+		for _, t := range entry.tags {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// delPattern deletes the keys matching a redis glob pattern, close enough to
+// path.Match for the key patterns used here.
+func (f *fallback) delPattern(pattern string) int64 {
+	return f.delWhere(func(entry *fallbackEntry) bool {
+		ok, _ := path.Match(pattern, entry.key)
+		return ok
+	})
+}
+
+func (f *fallback) purge() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ll.Init()
+	f.items = map[string]*list.Element{}
+}
+
+func (f *fallback) remove(el *list.Element) {
+	f.ll.Remove(el)
+	delete(f.items, el.Value.(*fallbackEntry).key)
+}
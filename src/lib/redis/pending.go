@@ -0,0 +1,126 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// replayTimeout bounds replaying the pending deletes once redis is back.
+const replayTimeout = 30 * time.Second
+
+// pending remembers the deletes that only reached the fallback during an
+// outage. They are replayed once redis is back, otherwise redis would serve
+// the entries invalidated while it was down until they expire.
+type pending struct {
+	size int
+
+	mu       sync.Mutex
+	keys     map[string]struct{}
+	tags     map[string]struct{}
+	patterns map[string]struct{}
+	// dropped is set when more keys than size were deleted during the
+	// outage, those deletes are lost.
+	dropped bool
+}
+
+func newPending(size int) *pending {
+	if size <= 0 {
+		size = defaultFallbackSize
+	}
+
+	return &pending{
+		size:     size,
+		keys:     map[string]struct{}{},
+		tags:     map[string]struct{}{},
+		patterns: map[string]struct{}{},
+	}
+}
+
+func (p *pending) addKeys(keys ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range keys {
+		if len(p.keys) >= p.size {
+			p.dropped = true
+			return
+		}
+		p.keys[key] = struct{}{}
+	}
+}
+
+func (p *pending) addTag(tag string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tags[tag] = struct{}{}
+}
+
+func (p *pending) addPattern(pattern string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.patterns[pattern] = struct{}{}
+}
+
+// take empties pending and returns what it held.
+func (p *pending) take() (keys []string, tags []string, patterns []string, dropped bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key := range p.keys {
+		keys = append(keys, key)
+	}
+	for tag := range p.tags {
+		tags = append(tags, tag)
+	}
+	for pattern := range p.patterns {
+		patterns = append(patterns, pattern)
+	}
+	dropped = p.dropped
+
+	p.keys, p.tags, p.patterns, p.dropped = map[string]struct{}{}, map[string]struct{}{}, map[string]struct{}{}, false
+
+	return keys, tags, patterns, dropped
+}
+
+// reconnected runs when redis answers after failing. The fallback only held
+// what was written during the outage and is emptied, the deletes it took are
+// replayed on redis. A delete that fails again is pending until the next
+// reconnect.
+func (c *cache) reconnected() {
+	c.fallback.purge()
+
+	// the breaker calls this while holding its lock, commands have to wait
+	// until it is released
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+		defer cancel()
+
+		keys, tags, patterns, dropped := c.pending.take()
+		if dropped {
+			c.log.Error(ctx, "too many cache deletes during the redis outage, some entries may be served until they expire")
+		}
+
+		if len(keys) > 0 {
+			if err := c.Del(ctx, keys...); err != nil {
+				c.log.Error(ctx, "failed to replay cache deletes", "keys", len(keys), "error", err)
+			}
+		}
+
+		if len(tags) > 0 {
+			if _, err := c.InvalidateTags(ctx, tags...); err != nil {
+				c.log.Error(ctx, "failed to replay cache tag invalidations", "tags", tags, "error", err)
+			}
+		}
+
+		for _, pattern := range patterns {
+			if _, err := c.DelByPattern(ctx, pattern); err != nil {
+				c.log.Error(ctx, "failed to replay cache pattern deletes", "pattern", pattern, "error", err)
+			}
+		}
+
+		if len(keys)+len(tags)+len(patterns) > 0 {
+			c.log.Info(ctx, "replayed cache deletes of the redis outage", "keys", len(keys), "tags", len(tags), "patterns", len(patterns))
+		}
+	}()
+}
@@ -0,0 +1,270 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bsm/redislock"
+)
+
+const (
+	ReadThroughHit   = "hit"
+	ReadThroughStale = "stale"
+	ReadThroughMiss  = "miss"
+
+	lockKey = `synapsis:lock:%s`
+
+	defaultLockTTL    = 5 * time.Second
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// ReadThroughOptions tunes ReadThrough for one kind of key.
+type ReadThroughOptions struct {
+	// TTL is how long a loaded value is fresh.
+	TTL time.Duration
+	// StaleTTL is how long a value is still served after TTL while one
+	// caller reloads it in the background. Zero disables it.
+	StaleTTL time.Duration
+	// LockTTL bounds how long a load may hold the distributed lock, callers
+	// of the other instances wait at most as long. Defaults to 5 seconds.
+	LockTTL time.Duration
+	Tags    []string
+}
+
+// ReadThroughResult carries the value with how it was found. GetErr and
+// SetErr are cache failures the value was loaded around, Value is still
+// valid when they are set.
+type ReadThroughResult struct {
+	Value  string
+	Status string
+	GetErr error
+	SetErr error
+}
+
+// LoadFunc loads the value of a key from the source of truth on a miss.
+type LoadFunc func(ctx context.Context) (string, error)
+
+// cachedValue is what ReadThrough stores, FreshUntil in unix milliseconds
+// tells a fresh value from a stale one.
+type cachedValue struct {
+	Value      string `json:"v"`
+	FreshUntil int64  `json:"f"`
+}
+
+// ReadThrough returns the cached value of key or loads it on a miss. Only one
+// load per key runs in this process, and the distributed lock keeps the other
+// instances waiting for it instead of hitting the database too. A stale value
+// is returned right away and refreshed in the background.
+func (c *cache) ReadThrough(ctx context.Context, key string, opt ReadThroughOptions, load LoadFunc) (ReadThroughResult, error) {
+	if opt.LockTTL <= 0 {
+		opt.LockTTL = defaultLockTTL
+	}
+
+	result := ReadThroughResult{}
+	cached, err := c.getCachedValue(ctx, key)
+	switch {
+	case err == nil && cached.isFresh():
+		result.Value, result.Status = cached.Value, ReadThroughHit
+		return result, nil
+	case err == nil:
+		c.revalidate(key, opt, load)
+		result.Value, result.Status = cached.Value, ReadThroughStale
+		return result, nil
+	case !errors.Is(err, Nil):
+		result.GetErr = err
+	}
+
+	// the load is shared by every caller waiting on key, so it must not end
+	// with the context of the caller that started it. It keeps the values of
+	// that context and may wait for the lock and then load under it.
+	flight := c.group.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(detach(ctx), 2*opt.LockTTL)
+		defer cancel()
+
+		return c.loadLocked(loadCtx, key, opt, load)
+	})
+
+	var loaded ReadThroughResult
+	select {
+	case <-ctx.Done():
+		return result, ctx.Err()
+	case res := <-flight:
+		if res.Err != nil {
+			return result, res.Err
+		}
+		loaded = res.Val.(ReadThroughResult)
+	}
+
+	result.Value, result.Status, result.SetErr = loaded.Value, loaded.Status, loaded.SetErr
+
+	return result, nil
+}
+
+// loadLocked loads the key while holding its distributed lock. The cache is
+// checked again once the lock is held, another instance may have filled it.
+// When redis fails the value is still loaded, a cache outage must not fail
+// the read.
+func (c *cache) loadLocked(ctx context.Context, key string, opt ReadThroughOptions, load LoadFunc) (ReadThroughResult, error) {
+	result := ReadThroughResult{
+		Status: ReadThroughMiss,
+	}
+
+	lock, err := c.rlock.Obtain(ctx, fmt.Sprintf(lockKey, key), opt.LockTTL, &redislock.Options{
+		RetryStrategy: redislock.LimitRetry(redislock.LinearBackoff(lockRetryInterval), int(opt.LockTTL/lockRetryInterval)),
+	})
+	switch {
+	case err == nil:
+		defer c.release(lock)
+		if cached, err := c.getCachedValue(ctx, key); err == nil && cached.isFresh() {
+			result.Value, result.Status = cached.Value, ReadThroughHit
+			return result, nil
+		}
+	case errors.Is(err, redislock.ErrNotObtained):
+		if cached, err := c.getCachedValue(ctx, key); err == nil {
+			result.Value, result.Status = cached.Value, ReadThroughHit
+			return result, nil
+		}
+	case errors.Is(err, ErrUnavailable):
+		// redis is down, the single flight of this process has to do
+	default:
+		c.log.Warn(ctx, "failed to obtain cache lock", "key", key, "error", err)
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	result.Value = value
+	result.SetErr = c.setCachedValue(ctx, key, value, opt)
+
+	return result, nil
+}
+
+// revalidate reloads a stale key in the background. It gives up when the
+// lock is taken, the holder is already reloading it.
+func (c *cache) revalidate(key string, opt ReadThroughOptions, load LoadFunc) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), opt.LockTTL)
+		defer cancel()
+
+		// a flight of its own, callers on a miss must never share its empty
+		// result when the lock is taken
+		_, err, _ := c.group.Do("revalidate:"+key, func() (interface{}, error) {
+			lock, err := c.rlock.Obtain(ctx, fmt.Sprintf(lockKey, key), opt.LockTTL, nil)
+			switch {
+			case err == nil:
+				defer c.release(lock)
+			case errors.Is(err, redislock.ErrNotObtained):
+				return ReadThroughResult{}, nil
+			case !errors.Is(err, ErrUnavailable):
+				return ReadThroughResult{}, err
+			}
+
+			value, err := load(ctx)
+			if err != nil {
+				return ReadThroughResult{}, err
+			}
+
+			return ReadThroughResult{Value: value}, c.setCachedValue(ctx, key, value, opt)
+		})
+		if err != nil {
+			c.log.Error(ctx, "failed to revalidate cache", "key", key, "error", err)
+		}
+	}()
+}
+
+func (c *cache) release(lock *redislock.Lock) {
+	if err := lock.Release(context.Background()); err != nil && !errors.Is(err, redislock.ErrLockNotHeld) {
+		c.log.Warn(context.Background(), "failed to release cache lock", "key", lock.Key(), "error", err)
+	}
+}
+
+func (c *cache) getCachedValue(ctx context.Context, key string) (cachedValue, error) {
+	cached := cachedValue{}
+
+	raw, err := c.Get(ctx, key)
+	if err != nil {
+		return cached, err
+	}
+
+	if err := json.Unmarshal([]byte(raw), &cached); err != nil {
+		return cached, fmt.Errorf("failed to unmarshal cached value : %w", err)
+	}
+
+	// values cached before they were wrapped decode without FreshUntil, they
+	// are a miss rather than an empty value
+	if cached.FreshUntil == 0 {
+		return cachedValue{}, Nil
+	}
+
+	return cached, nil
+}
+
+func (c *cache) setCachedValue(ctx context.Context, key string, value string, opt ReadThroughOptions) error {
+	raw, err := json.Marshal(cachedValue{
+		Value:      value,
+		FreshUntil: time.Now().Add(opt.TTL).UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.SetEXWithTags(ctx, key, string(raw), opt.TTL+opt.StaleTTL, opt.Tags...)
+}
+
+func (v cachedValue) isFresh() bool {
+	return time.Now().UnixMilli() < v.FreshUntil
+}
+
+// ReadThroughJSON is ReadThrough for values stored as json. It decodes the
+// value into dest, load returns the value to encode on a miss.
+func ReadThroughJSON(ctx context.Context, r Interface, key string, opt ReadThroughOptions, dest interface{}, load func(ctx context.Context) (interface{}, error)) (ReadThroughResult, error) {
+	result, err := r.ReadThrough(ctx, key, opt, func(ctx context.Context) (string, error) {
+		v, err := load(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+
+		return string(raw), nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal([]byte(result.Value), dest); err != nil {
+		return result, fmt.Errorf("failed to unmarshal redis : %w", err)
+	}
+
+	return result, nil
+}
+
+// detachedContext keeps the values of a context without its deadline and
+// cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
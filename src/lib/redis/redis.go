@@ -4,20 +4,93 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
-	"log"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/tracer"
 	"time"
 
 	"github.com/bsm/redislock"
+	gonanoid "github.com/matoous/go-nanoid/v2"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
 	Nil = redis.Nil
+
+	tagKey = `synapsis:tag:%s`
+
+	// scanCount is the number of keys asked per SCAN call in DelByPattern.
+	scanCount = 500
 )
 
+// incrEXScript increments KEYS[1] and sets its expiry in ms when it is new.
+var incrEXScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// slidingWindowScript keeps one sorted set member per request inside the
+// window and returns {allowed, remaining, retry after in ms}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+
+local retry = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+end
+return {0, 0, retry}
+`)
+
 type Interface interface {
 	Get(ctx context.Context, key string) (string, error)
 	SetEX(ctx context.Context, key string, val string, expTime time.Duration) error
+	// Incr, IncrEX, Expire, TTL and SlidingWindow have no fallback, they
+	// return ErrUnavailable during an outage. Counters built on them, like
+	// the login lockout and the rate limit, do not hold until redis is back.
+	Incr(ctx context.Context, key string) (int64, error)
+	// IncrEX increments key and sets expTime when the increment created it,
+	// in one round trip so the counter never lives without an expiry.
+	IncrEX(ctx context.Context, key string, expTime time.Duration) (int64, error)
+	Expire(ctx context.Context, key string, expTime time.Duration) error
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Del, InvalidateTags and DelByPattern succeed during an outage, the
+	// deletes are replayed on redis once it is back.
+	Del(ctx context.Context, keys ...string) error
+	MGet(ctx context.Context, keys ...string) (map[string]string, error)
+	SetEXWithTags(ctx context.Context, key string, val string, expTime time.Duration, tags ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) (int64, error)
+	DelByPattern(ctx context.Context, pattern string) (int64, error)
+	Pipeline(ctx context.Context, fn func(pipe Pipeliner) error) error
+	ReadThrough(ctx context.Context, key string, opt ReadThroughOptions, load LoadFunc) (ReadThroughResult, error)
+	SlidingWindow(ctx context.Context, key string, limit int64, window time.Duration) (RateLimitResult, error)
+	XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+	Publish(ctx context.Context, channel string, message string) error
+	// Subscribe listens to the channels until the subscription is closed, it
+	// resubscribes on its own after the connection to redis is lost.
+	Subscribe(ctx context.Context, channels ...string) Subscription
+	Ping(ctx context.Context) error
+}
+
+// Subscription receives the messages published to its channels. Messages
+// published while it is disconnected are lost.
+type Subscription interface {
+	Channel() <-chan *Message
+	Close() error
 }
 
 type TLSConfig struct {
@@ -32,19 +105,37 @@ type Config struct {
 	Username string
 	Password string
 	TLS      TLSConfig
+	Fallback FallbackConfig
+	Breaker  BreakerConfig
 }
 
 type cache struct {
-	conf  Config
-	rdb   *redis.Client
-	rlock *redislock.Client
+	conf   Config
+	log    log.Interface
+	tracer tracer.Interface
+	rdb    *redis.Client
+	rlock  *redislock.Client
+	group  singleflight.Group
+	// breaker and fallback keep the cache working, in memory, while redis is
+	// down.
+	breaker  *breaker
+	fallback *fallback
+	pending  *pending
 }
 
-func Init(cfg Config) Interface {
+// Init never fails on an unreachable redis, the server starts on the
+// in-process fallback and switches to redis once it is reachable.
+func Init(cfg Config, log log.Interface, tracer tracer.Interface) Interface {
 	c := &cache{
-		conf: cfg,
+		conf:     cfg,
+		log:      log,
+		tracer:   tracer,
+		fallback: newFallback(cfg.Fallback),
+		pending:  newPending(cfg.Fallback.Size),
 	}
+	c.breaker = newBreaker(cfg.Breaker, log, c.reconnected)
 	c.connect(context.Background())
+	go c.reconnect()
 	return c
 }
 
@@ -63,19 +154,44 @@ func (c *cache) connect(ctx context.Context) {
 	}
 
 	client := redis.NewClient(&redisOpts)
+	client.AddHook(c.tracer.RedisHook())
+	client.AddHook(c.breaker)
+	c.rdb = client
+	c.rlock = redislock.New(client)
 
-	err := client.Ping(ctx).Err()
-	if err != nil {
-		log.Fatalf("[FATAL] cannot connect to redis on address @%s:%v, with error: %s", c.conf.Host, c.conf.Port, err)
+	if err := client.Ping(ctx).Err(); err != nil {
+		c.breaker.trip()
+		c.log.Error(ctx, "cannot connect to redis, using the in-process fallback cache", "address", redisOpts.Addr, "error", err)
+		return
 	}
-	c.rdb = client
-	log.Printf("REDIS: Address @%s:%v", c.conf.Host, c.conf.Port)
+	c.log.Info(ctx, "connected to redis", "address", redisOpts.Addr)
+}
 
-	c.rlock = redislock.New(client)
+// reconnect probes redis while it is failing, so the server switches back to
+// redis even when no request reaches the cache.
+func (c *cache) reconnect() {
+	ticker := time.NewTicker(c.breaker.conf.Cooldown)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !c.breaker.failing() {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.breaker.conf.Cooldown)
+		_ = c.rdb.Ping(ctx).Err()
+		cancel()
+	}
 }
 
 func (c *cache) Get(ctx context.Context, key string) (string, error) {
 	s, err := c.rdb.Get(ctx, key).Result()
+	if isConnectionError(err) {
+		if v, ok := c.fallback.get(key); ok {
+			return v, nil
+		}
+		return "", Nil
+	}
 	if err != nil {
 		return s, err
 	}
@@ -85,9 +201,251 @@ func (c *cache) Get(ctx context.Context, key string) (string, error) {
 
 func (c *cache) SetEX(ctx context.Context, key string, val string, expTime time.Duration) error {
 	err := c.rdb.SetEx(ctx, key, val, expTime).Err()
+	if isConnectionError(err) {
+		c.fallback.set(key, val, expTime)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (c *cache) Incr(ctx context.Context, key string) (int64, error) {
+	n, err := c.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (c *cache) IncrEX(ctx context.Context, key string, expTime time.Duration) (int64, error) {
+	n, err := incrEXScript.Run(ctx, c.rdb, []string{key}, expTime.Milliseconds()).Int64()
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (c *cache) Expire(ctx context.Context, key string, expTime time.Duration) error {
+	if err := c.rdb.Expire(ctx, key, expTime).Err(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *cache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	d, err := c.rdb.TTL(ctx, key).Result()
+	if err != nil {
+		return d, err
+	}
+
+	return d, nil
+}
+
+func (c *cache) Del(ctx context.Context, keys ...string) error {
+	err := c.rdb.Del(ctx, keys...).Err()
+	if isConnectionError(err) {
+		c.fallback.del(keys...)
+		c.pending.addKeys(keys...)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MGet returns the value of every key that exists, missing keys are left out
+// of the map.
+func (c *cache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	result := map[string]string{}
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	values, err := c.rdb.MGet(ctx, keys...).Result()
+	if isConnectionError(err) {
+		for _, key := range keys {
+			if v, ok := c.fallback.get(key); ok {
+				result[key] = v
+			}
+		}
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			result[keys[i]] = s
+		}
+	}
+
+	return result, nil
+}
+
+// SetEXWithTags sets the key and adds it to the set of each tag, so every key
+// of a tag can be dropped at once with InvalidateTags. A tag set expires with
+// the newest key added to it.
+func (c *cache) SetEXWithTags(ctx context.Context, key string, val string, expTime time.Duration, tags ...string) error {
+	err := c.Pipeline(ctx, func(pipe Pipeliner) error {
+		pipe.SetEX(key, val, expTime)
+		for _, tag := range tags {
+			pipe.SAdd(fmt.Sprintf(tagKey, tag), key)
+			pipe.Expire(fmt.Sprintf(tagKey, tag), expTime)
+		}
+		return nil
+	})
+	if isConnectionError(err) {
+		c.fallback.set(key, val, expTime, tags...)
+		return nil
+	}
+
+	return err
+}
+
+// InvalidateTags deletes every key added with one of the tags and returns the
+// number of deleted keys.
+func (c *cache) InvalidateTags(ctx context.Context, tags ...string) (int64, error) {
+	var deleted int64
+	for _, tag := range tags {
+		key := fmt.Sprintf(tagKey, tag)
+		members, err := c.rdb.SMembers(ctx, key).Result()
+		if isConnectionError(err) {
+			deleted += c.fallback.delTag(tag)
+			c.pending.addTag(tag)
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+
+		if len(members) > 0 {
+			n, err := c.rdb.Del(ctx, members...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += n
+		}
+
+		if err := c.rdb.Del(ctx, key).Err(); err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// DelByPattern deletes every key matching the glob pattern. Keys are walked
+// with SCAN, so a large keyspace does not block redis.
+func (c *cache) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	var (
+		deleted int64
+		cursor  uint64
+	)
+
+	for {
+		keys, next, err := c.rdb.Scan(ctx, cursor, pattern, scanCount).Result()
+		if isConnectionError(err) {
+			c.pending.addPattern(pattern)
+			return deleted + c.fallback.delPattern(pattern), nil
+		}
+		if err != nil {
+			return deleted, err
+		}
+
+		if len(keys) > 0 {
+			n, err := c.rdb.Unlink(ctx, keys...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += n
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+// Pipeline sends every command queued by fn in one round trip. Nothing is
+// sent when fn returns an error.
+func (c *cache) Pipeline(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	pipe := c.rdb.Pipeline()
+	if err := fn(&pipeliner{ctx: ctx, pipe: pipe}); err != nil {
+		pipe.Discard()
+		return err
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *cache) SlidingWindow(ctx context.Context, key string, limit int64, window time.Duration) (RateLimitResult, error) {
+	result := RateLimitResult{
+		Limit: limit,
+	}
+
+	member, err := gonanoid.New()
+	if err != nil {
+		return result, err
+	}
+
+	res, err := slidingWindowScript.Run(ctx, c.rdb, []string{key}, time.Now().UnixMilli(), window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return result, err
+	}
+
+	if len(res) != 3 {
+		return result, fmt.Errorf("unexpected sliding window result : %v", res)
+	}
+
+	result.Allowed = res[0] == 1
+	result.Remaining = res[1]
+	result.RetryAfter = time.Duration(res[2]) * time.Millisecond
+
+	return result, nil
+}
+
+// XAdd appends values to stream and returns the id of the entry. The stream
+// is trimmed to about maxLen entries when maxLen is positive.
+func (c *cache) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	id, err := c.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+// Publish sends message to the current subscribers of channel, it is not kept
+// for later subscribers.
+func (c *cache) Publish(ctx context.Context, channel string, message string) error {
+	return c.rdb.Publish(ctx, channel, message).Err()
+}
+
+func (c *cache) Subscribe(ctx context.Context, channels ...string) Subscription {
+	return &subscription{
+		pubsub: c.rdb.Subscribe(ctx, channels...),
+	}
+}
+
+func (c *cache) Ping(ctx context.Context) error {
+	return c.rdb.Ping(ctx).Err()
+}
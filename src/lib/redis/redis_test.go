@@ -0,0 +1,300 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/tracer"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCooldown = 20 * time.Millisecond
+
+var errConnRefused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+func newTestCache(t *testing.T, mr *miniredis.Miniredis) *cache {
+	t.Helper()
+
+	return Init(Config{
+		Protocol: "tcp",
+		Host:     mr.Host(),
+		Port:     mr.Port(),
+		Breaker: BreakerConfig{
+			FailureThreshold: 2,
+			Cooldown:         testCooldown,
+		},
+	}, log.Init(log.Config{Level: "disabled"}), tracer.Init(tracer.Config{})).(*cache)
+}
+
+// waitClosed waits until a probe found redis answering again.
+func waitClosed(t *testing.T, c *cache) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		return !c.breaker.failing()
+	}, time.Second, testCooldown/2)
+}
+
+func Test_breaker(t *testing.T) {
+	tests := []struct {
+		name       string
+		steps      func(b *breaker)
+		wantState  int
+		wantClosed bool
+	}{
+		{
+			name: "stays closed below the failure threshold",
+			steps: func(b *breaker) {
+				b.record(errConnRefused)
+			},
+			wantState: breakerClosed,
+		},
+		{
+			name: "reply errors do not count as failures",
+			steps: func(b *breaker) {
+				b.record(Nil)
+				b.record(Nil)
+				b.record(context.Canceled)
+			},
+			wantState: breakerClosed,
+		},
+		{
+			name: "a success resets the failures",
+			steps: func(b *breaker) {
+				b.record(errConnRefused)
+				b.record(nil)
+				b.record(errConnRefused)
+			},
+			wantState:  breakerClosed,
+			wantClosed: true,
+		},
+		{
+			name: "opens at the failure threshold",
+			steps: func(b *breaker) {
+				b.record(errConnRefused)
+				b.record(errConnRefused)
+			},
+			wantState: breakerOpen,
+		},
+		{
+			name: "refuses commands during the cooldown",
+			steps: func(b *breaker) {
+				b.trip()
+				assert.False(t, b.allow())
+			},
+			wantState: breakerOpen,
+		},
+		{
+			name: "lets one probe through after the cooldown",
+			steps: func(b *breaker) {
+				b.trip()
+				time.Sleep(testCooldown)
+				assert.True(t, b.allow())
+				assert.False(t, b.allow())
+			},
+			wantState: breakerHalfOpen,
+		},
+		{
+			name: "opens again when the probe fails",
+			steps: func(b *breaker) {
+				b.trip()
+				time.Sleep(testCooldown)
+				b.allow()
+				b.record(errConnRefused)
+			},
+			wantState: breakerOpen,
+		},
+		{
+			name: "reports a recovery below the failure threshold",
+			steps: func(b *breaker) {
+				b.record(errConnRefused)
+				b.record(nil)
+			},
+			wantState:  breakerClosed,
+			wantClosed: true,
+		},
+		{
+			name: "closes when the probe succeeds",
+			steps: func(b *breaker) {
+				b.trip()
+				time.Sleep(testCooldown)
+				b.allow()
+				b.record(nil)
+			},
+			wantState:  breakerClosed,
+			wantClosed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closed := false
+			b := newBreaker(BreakerConfig{
+				FailureThreshold: 2,
+				Cooldown:         testCooldown,
+			}, log.Init(log.Config{Level: "disabled"}), func() {
+				closed = true
+			})
+
+			tt.steps(b)
+			assert.Equal(t, tt.wantState, b.state)
+			assert.Equal(t, tt.wantClosed, closed)
+		})
+	}
+}
+
+func Test_cache_Fallback(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// outage runs while redis is down, after runs once it is back
+		outage func(t *testing.T, c *cache)
+		after  func(t *testing.T, c *cache, mr *miniredis.Miniredis)
+	}{
+		{
+			name: "reads what was written during the outage",
+			outage: func(t *testing.T, c *cache) {
+				assert.NoError(t, c.SetEX(ctx, "key", "outage", time.Minute))
+
+				got, err := c.Get(ctx, "key")
+				assert.NoError(t, err)
+				assert.Equal(t, "outage", got)
+
+				values, err := c.MGet(ctx, "key", "missing")
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]string{"key": "outage"}, values)
+			},
+		},
+		{
+			name: "misses keys not written during the outage",
+			outage: func(t *testing.T, c *cache) {
+				_, err := c.Get(ctx, "cached")
+				assert.ErrorIs(t, err, Nil)
+			},
+		},
+		{
+			name: "drops tagged keys during the outage",
+			outage: func(t *testing.T, c *cache) {
+				assert.NoError(t, c.SetEXWithTags(ctx, "key", "outage", time.Minute, "tag"))
+
+				deleted, err := c.InvalidateTags(ctx, "tag")
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), deleted)
+
+				_, err = c.Get(ctx, "key")
+				assert.ErrorIs(t, err, Nil)
+			},
+		},
+		{
+			name: "has no fallback for counters",
+			outage: func(t *testing.T, c *cache) {
+				_, err := c.IncrEX(ctx, "counter", time.Minute)
+				assert.Error(t, err)
+
+				_, err = c.TTL(ctx, "counter")
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "reads redis again once it is back",
+			outage: func(t *testing.T, c *cache) {
+				assert.NoError(t, c.SetEX(ctx, "cached", "outage", time.Minute))
+			},
+			after: func(t *testing.T, c *cache, mr *miniredis.Miniredis) {
+				got, err := c.Get(ctx, "cached")
+				assert.NoError(t, err)
+				assert.Equal(t, "redis", got)
+
+				assert.NoError(t, c.SetEX(ctx, "key", "after", time.Minute))
+				got, err = mr.Get("key")
+				assert.NoError(t, err)
+				assert.Equal(t, "after", got)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			assert.NoError(t, mr.Set("cached", "redis"))
+
+			c := newTestCache(t, mr)
+			mr.Close()
+
+			tt.outage(t, c)
+			if tt.after == nil {
+				return
+			}
+
+			assert.NoError(t, mr.Restart())
+			waitClosed(t, c)
+			tt.after(t, c, mr)
+		})
+	}
+}
+
+func Test_cache_ReplayDeletes(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		outage   func(t *testing.T, c *cache)
+		wantKeys []string
+	}{
+		{
+			name: "replays key deletes",
+			outage: func(t *testing.T, c *cache) {
+				assert.NoError(t, c.Del(ctx, "key"))
+			},
+			wantKeys: []string{"product:1", "tagged", "synapsis:tag:tag"},
+		},
+		{
+			name: "replays tag invalidations",
+			outage: func(t *testing.T, c *cache) {
+				_, err := c.InvalidateTags(ctx, "tag")
+				assert.NoError(t, err)
+			},
+			wantKeys: []string{"key", "product:1"},
+		},
+		{
+			name: "replays pattern deletes",
+			outage: func(t *testing.T, c *cache) {
+				_, err := c.DelByPattern(ctx, "product:*")
+				assert.NoError(t, err)
+			},
+			wantKeys: []string{"key", "tagged", "synapsis:tag:tag"},
+		},
+		{
+			name: "replays nothing without deletes",
+			outage: func(t *testing.T, c *cache) {
+				assert.NoError(t, c.SetEX(ctx, "key", "outage", time.Minute))
+			},
+			wantKeys: []string{"key", "product:1", "tagged", "synapsis:tag:tag"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+
+			c := newTestCache(t, mr)
+			assert.NoError(t, c.SetEX(ctx, "key", "redis", time.Minute))
+			assert.NoError(t, c.SetEX(ctx, "product:1", "redis", time.Minute))
+			assert.NoError(t, c.SetEXWithTags(ctx, "tagged", "redis", time.Minute, "tag"))
+
+			mr.Close()
+			tt.outage(t, c)
+			assert.NoError(t, mr.Restart())
+			waitClosed(t, c)
+
+			assert.Eventually(t, func() bool {
+				keys := mr.Keys()
+				return assert.ObjectsAreEqual(len(tt.wantKeys), len(keys))
+			}, time.Second, testCooldown/2)
+			assert.ElementsMatch(t, tt.wantKeys, mr.Keys())
+		})
+	}
+}
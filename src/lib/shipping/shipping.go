@@ -0,0 +1,106 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	CalculatorFlat   = "flat"
+	CalculatorWeight = "weight"
+)
+
+type Interface interface {
+	// Quote prices the delivery of a parcel with every configured courier
+	// service.
+	Quote(ctx context.Context, param QuoteParam) []Quote
+}
+
+type Config struct {
+	// Calculator picks how a rate is priced, flat or weight.
+	Calculator string
+	Rates      []Rate
+}
+
+// Rate is one courier service. The flat calculator charges Price per parcel,
+// the weight calculator charges Price per started kilogram.
+type Rate struct {
+	Courier string
+	Service string
+	Price   int64
+	// EstimatedDays is shown to the customer at checkout.
+	EstimatedDays string
+}
+
+type QuoteParam struct {
+	// Weight of the parcel in grams.
+	Weight      int
+	Destination string
+}
+
+type Quote struct {
+	Courier       string
+	Service       string
+	Cost          int64
+	EstimatedDays string
+}
+
+// calculator prices one rate, new calculators, e.g. a courier api, only need
+// to implement it.
+type calculator interface {
+	cost(ctx context.Context, rate Rate, param QuoteParam) int64
+}
+
+type shipping struct {
+	conf       Config
+	calculator calculator
+}
+
+func Init(cfg Config) Interface {
+	s := &shipping{
+		conf: cfg,
+	}
+
+	switch cfg.Calculator {
+	case CalculatorWeight:
+		s.calculator = weightCalculator{}
+	case CalculatorFlat, "":
+		s.calculator = flatCalculator{}
+	default:
+		panic(fmt.Sprintf("unknown shipping calculator %q", cfg.Calculator))
+	}
+
+	return s
+}
+
+func (s *shipping) Quote(ctx context.Context, param QuoteParam) []Quote {
+	quotes := []Quote{}
+	for _, rate := range s.conf.Rates {
+		quotes = append(quotes, Quote{
+			Courier:       rate.Courier,
+			Service:       rate.Service,
+			Cost:          s.calculator.cost(ctx, rate, param),
+			EstimatedDays: rate.EstimatedDays,
+		})
+	}
+
+	return quotes
+}
+
+type flatCalculator struct{}
+
+func (flatCalculator) cost(ctx context.Context, rate Rate, param QuoteParam) int64 {
+	return rate.Price
+}
+
+type weightCalculator struct{}
+
+// cost charges at least one kilogram, a parcel of 1001 grams pays for two.
+func (weightCalculator) cost(ctx context.Context, rate Rate, param QuoteParam) int64 {
+	kg := int64((param.Weight + 999) / 1000)
+	if kg < 1 {
+		kg = 1
+	}
+
+	return rate.Price * kg
+}
@@ -3,6 +3,8 @@ package sql
 import (
 	"fmt"
 	"go-clean/src/business/entity"
+	"go-clean/src/lib/metrics"
+	"go-clean/src/lib/tracer"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -14,18 +16,31 @@ type Config struct {
 	Password string
 	Port     string
 	Database string
+	// AutoMigrate syncs the schema from the entities on boot, for local
+	// development only. Other environments run the migrate subcommand.
+	AutoMigrate bool
 }
 
-func Init(cfg Config) *gorm.DB {
+func Init(cfg Config, metrics metrics.Interface, tracer tracer.Interface) *gorm.DB {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
 		panic(err)
 	}
 
-	if err := db.AutoMigrate(&entity.User{}, &entity.Category{}, &entity.Product{}, &entity.Cart{}, &entity.Transaction{}, &entity.MidtransTransaction{}); err != nil {
+	if err := db.Use(metrics.GormPlugin()); err != nil {
 		panic(err)
 	}
 
+	if err := db.Use(tracer.GormPlugin()); err != nil {
+		panic(err)
+	}
+
+	if cfg.AutoMigrate {
+		if err := db.AutoMigrate(&entity.User{}, &entity.Category{}, &entity.Product{}, &entity.Cart{}, &entity.Transaction{}, &entity.MidtransTransaction{}, &entity.Address{}, &entity.OutboxEvent{}, &entity.WebhookSubscription{}, &entity.WebhookDelivery{}, &entity.Refund{}, &entity.Shipment{}, &entity.Invoice{}, &entity.InvoiceCounter{}); err != nil {
+			panic(err)
+		}
+	}
+
 	return db
 }
@@ -0,0 +1,33 @@
+package sql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type contextKey string
+
+const txKey contextKey = "SQLTx"
+
+// WithTx runs fn in a db transaction carried by the ctx given to fn, queries
+// made through Conn with that ctx join it. A nested WithTx joins the outer
+// transaction. The transaction is rolled back when fn returns an error.
+func WithTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey, tx))
+	})
+}
+
+// Conn is the transaction of ctx if any, db otherwise.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
@@ -35,6 +35,32 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 	return m.recorder
 }
 
+// CompareDummyPassword mocks base method.
+func (m *MockInterface) CompareDummyPassword(password string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CompareDummyPassword", password)
+}
+
+// CompareDummyPassword indicates an expected call of CompareDummyPassword.
+func (mr *MockInterfaceMockRecorder) CompareDummyPassword(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareDummyPassword", reflect.TypeOf((*MockInterface)(nil).CompareDummyPassword), password)
+}
+
+// ComparePassword mocks base method.
+func (m *MockInterface) ComparePassword(hashedPassword, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ComparePassword", hashedPassword, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ComparePassword indicates an expected call of ComparePassword.
+func (mr *MockInterfaceMockRecorder) ComparePassword(hashedPassword, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ComparePassword", reflect.TypeOf((*MockInterface)(nil).ComparePassword), hashedPassword, password)
+}
+
 // GenerateGuestToken mocks base method.
 func (m *MockInterface) GenerateGuestToken() (string, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +76,51 @@ func (mr *MockInterfaceMockRecorder) GenerateGuestToken() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateGuestToken", reflect.TypeOf((*MockInterface)(nil).GenerateGuestToken))
 }
 
+// GenerateMFAChallengeToken mocks base method.
+func (m *MockInterface) GenerateMFAChallengeToken(user auth.User) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateMFAChallengeToken", user)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateMFAChallengeToken indicates an expected call of GenerateMFAChallengeToken.
+func (mr *MockInterfaceMockRecorder) GenerateMFAChallengeToken(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateMFAChallengeToken", reflect.TypeOf((*MockInterface)(nil).GenerateMFAChallengeToken), user)
+}
+
+// GenerateRecoveryCodes mocks base method.
+func (m *MockInterface) GenerateRecoveryCodes(n int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateRecoveryCodes", n)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRecoveryCodes indicates an expected call of GenerateRecoveryCodes.
+func (mr *MockInterfaceMockRecorder) GenerateRecoveryCodes(n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRecoveryCodes", reflect.TypeOf((*MockInterface)(nil).GenerateRecoveryCodes), n)
+}
+
+// GenerateTOTPKey mocks base method.
+func (m *MockInterface) GenerateTOTPKey(accountName string) (auth.TOTPKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateTOTPKey", accountName)
+	ret0, _ := ret[0].(auth.TOTPKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateTOTPKey indicates an expected call of GenerateTOTPKey.
+func (mr *MockInterfaceMockRecorder) GenerateTOTPKey(accountName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateTOTPKey", reflect.TypeOf((*MockInterface)(nil).GenerateTOTPKey), accountName)
+}
+
 // GenerateToken mocks base method.
 func (m *MockInterface) GenerateToken(user auth.User) (string, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +151,78 @@ func (mr *MockInterfaceMockRecorder) GetUserAuthInfo(ctx interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAuthInfo", reflect.TypeOf((*MockInterface)(nil).GetUserAuthInfo), ctx)
 }
 
+// HashPassword mocks base method.
+func (m *MockInterface) HashPassword(password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashPassword", password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashPassword indicates an expected call of HashPassword.
+func (mr *MockInterfaceMockRecorder) HashPassword(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashPassword", reflect.TypeOf((*MockInterface)(nil).HashPassword), password)
+}
+
+// HashRecoveryCode mocks base method.
+func (m *MockInterface) HashRecoveryCode(code string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashRecoveryCode", code)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// HashRecoveryCode indicates an expected call of HashRecoveryCode.
+func (mr *MockInterfaceMockRecorder) HashRecoveryCode(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashRecoveryCode", reflect.TypeOf((*MockInterface)(nil).HashRecoveryCode), code)
+}
+
+// IsMFARequired mocks base method.
+func (m *MockInterface) IsMFARequired(role string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMFARequired", role)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsMFARequired indicates an expected call of IsMFARequired.
+func (mr *MockInterfaceMockRecorder) IsMFARequired(role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMFARequired", reflect.TypeOf((*MockInterface)(nil).IsMFARequired), role)
+}
+
+// NeedRehash mocks base method.
+func (m *MockInterface) NeedRehash(hashedPassword string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedRehash", hashedPassword)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedRehash indicates an expected call of NeedRehash.
+func (mr *MockInterfaceMockRecorder) NeedRehash(hashedPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedRehash", reflect.TypeOf((*MockInterface)(nil).NeedRehash), hashedPassword)
+}
+
+// ParseMFAChallengeToken mocks base method.
+func (m *MockInterface) ParseMFAChallengeToken(token string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseMFAChallengeToken", token)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseMFAChallengeToken indicates an expected call of ParseMFAChallengeToken.
+func (mr *MockInterfaceMockRecorder) ParseMFAChallengeToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseMFAChallengeToken", reflect.TypeOf((*MockInterface)(nil).ParseMFAChallengeToken), token)
+}
+
 // SetUserAuthInfo mocks base method.
 func (m *MockInterface) SetUserAuthInfo(ctx context.Context, user auth.User, token string) context.Context {
 	m.ctrl.T.Helper()
@@ -93,3 +236,18 @@ func (mr *MockInterfaceMockRecorder) SetUserAuthInfo(ctx, user, token interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserAuthInfo", reflect.TypeOf((*MockInterface)(nil).SetUserAuthInfo), ctx, user, token)
 }
+
+// ValidateTOTP mocks base method.
+func (m *MockInterface) ValidateTOTP(secret, code string) (int64, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateTOTP", secret, code)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// ValidateTOTP indicates an expected call of ValidateTOTP.
+func (mr *MockInterfaceMockRecorder) ValidateTOTP(secret, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateTOTP", reflect.TypeOf((*MockInterface)(nil).ValidateTOTP), secret, code)
+}
@@ -6,6 +6,7 @@ package mock_redis
 
 import (
 	context "context"
+	redis "go-clean/src/lib/redis"
 	reflect "reflect"
 	time "time"
 
@@ -35,6 +36,54 @@ func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
 	return m.recorder
 }
 
+// Del mocks base method.
+func (m *MockInterface) Del(ctx context.Context, keys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Del", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Del indicates an expected call of Del.
+func (mr *MockInterfaceMockRecorder) Del(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockInterface)(nil).Del), varargs...)
+}
+
+// DelByPattern mocks base method.
+func (m *MockInterface) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelByPattern", ctx, pattern)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DelByPattern indicates an expected call of DelByPattern.
+func (mr *MockInterfaceMockRecorder) DelByPattern(ctx, pattern interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelByPattern", reflect.TypeOf((*MockInterface)(nil).DelByPattern), ctx, pattern)
+}
+
+// Expire mocks base method.
+func (m *MockInterface) Expire(ctx context.Context, key string, expTime time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", ctx, key, expTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockInterfaceMockRecorder) Expire(ctx, key, expTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockInterface)(nil).Expire), ctx, key, expTime)
+}
+
 // Get mocks base method.
 func (m *MockInterface) Get(ctx context.Context, key string) (string, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +99,133 @@ func (mr *MockInterfaceMockRecorder) Get(ctx, key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInterface)(nil).Get), ctx, key)
 }
 
+// Incr mocks base method.
+func (m *MockInterface) Incr(ctx context.Context, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", ctx, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockInterfaceMockRecorder) Incr(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockInterface)(nil).Incr), ctx, key)
+}
+
+// IncrEX mocks base method.
+func (m *MockInterface) IncrEX(ctx context.Context, key string, expTime time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrEX", ctx, key, expTime)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrEX indicates an expected call of IncrEX.
+func (mr *MockInterfaceMockRecorder) IncrEX(ctx, key, expTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrEX", reflect.TypeOf((*MockInterface)(nil).IncrEX), ctx, key, expTime)
+}
+
+// InvalidateTags mocks base method.
+func (m *MockInterface) InvalidateTags(ctx context.Context, tags ...string) (int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range tags {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InvalidateTags", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InvalidateTags indicates an expected call of InvalidateTags.
+func (mr *MockInterfaceMockRecorder) InvalidateTags(ctx interface{}, tags ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, tags...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateTags", reflect.TypeOf((*MockInterface)(nil).InvalidateTags), varargs...)
+}
+
+// MGet mocks base method.
+func (m *MockInterface) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "MGet", varargs...)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MGet indicates an expected call of MGet.
+func (mr *MockInterfaceMockRecorder) MGet(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MGet", reflect.TypeOf((*MockInterface)(nil).MGet), varargs...)
+}
+
+// Ping mocks base method.
+func (m *MockInterface) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockInterfaceMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockInterface)(nil).Ping), ctx)
+}
+
+// Pipeline mocks base method.
+func (m *MockInterface) Pipeline(ctx context.Context, fn func(redis.Pipeliner) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pipeline", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pipeline indicates an expected call of Pipeline.
+func (mr *MockInterfaceMockRecorder) Pipeline(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pipeline", reflect.TypeOf((*MockInterface)(nil).Pipeline), ctx, fn)
+}
+
+// Publish mocks base method.
+func (m *MockInterface) Publish(ctx context.Context, channel, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, channel, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockInterfaceMockRecorder) Publish(ctx, channel, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockInterface)(nil).Publish), ctx, channel, message)
+}
+
+// ReadThrough mocks base method.
+func (m *MockInterface) ReadThrough(ctx context.Context, key string, opt redis.ReadThroughOptions, load redis.LoadFunc) (redis.ReadThroughResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadThrough", ctx, key, opt, load)
+	ret0, _ := ret[0].(redis.ReadThroughResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadThrough indicates an expected call of ReadThrough.
+func (mr *MockInterfaceMockRecorder) ReadThrough(ctx, key, opt, load interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadThrough", reflect.TypeOf((*MockInterface)(nil).ReadThrough), ctx, key, opt, load)
+}
+
 // SetEX mocks base method.
 func (m *MockInterface) SetEX(ctx context.Context, key, val string, expTime time.Duration) error {
 	m.ctrl.T.Helper()
@@ -63,3 +239,137 @@ func (mr *MockInterfaceMockRecorder) SetEX(ctx, key, val, expTime interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEX", reflect.TypeOf((*MockInterface)(nil).SetEX), ctx, key, val, expTime)
 }
+
+// SetEXWithTags mocks base method.
+func (m *MockInterface) SetEXWithTags(ctx context.Context, key, val string, expTime time.Duration, tags ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, key, val, expTime}
+	for _, a := range tags {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetEXWithTags", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEXWithTags indicates an expected call of SetEXWithTags.
+func (mr *MockInterfaceMockRecorder) SetEXWithTags(ctx, key, val, expTime interface{}, tags ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, key, val, expTime}, tags...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEXWithTags", reflect.TypeOf((*MockInterface)(nil).SetEXWithTags), varargs...)
+}
+
+// SlidingWindow mocks base method.
+func (m *MockInterface) SlidingWindow(ctx context.Context, key string, limit int64, window time.Duration) (redis.RateLimitResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlidingWindow", ctx, key, limit, window)
+	ret0, _ := ret[0].(redis.RateLimitResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SlidingWindow indicates an expected call of SlidingWindow.
+func (mr *MockInterfaceMockRecorder) SlidingWindow(ctx, key, limit, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlidingWindow", reflect.TypeOf((*MockInterface)(nil).SlidingWindow), ctx, key, limit, window)
+}
+
+// Subscribe mocks base method.
+func (m *MockInterface) Subscribe(ctx context.Context, channels ...string) redis.Subscription {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range channels {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Subscribe", varargs...)
+	ret0, _ := ret[0].(redis.Subscription)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockInterfaceMockRecorder) Subscribe(ctx interface{}, channels ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, channels...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockInterface)(nil).Subscribe), varargs...)
+}
+
+// TTL mocks base method.
+func (m *MockInterface) TTL(ctx context.Context, key string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TTL", ctx, key)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TTL indicates an expected call of TTL.
+func (mr *MockInterfaceMockRecorder) TTL(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TTL", reflect.TypeOf((*MockInterface)(nil).TTL), ctx, key)
+}
+
+// XAdd mocks base method.
+func (m *MockInterface) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XAdd", ctx, stream, maxLen, values)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// XAdd indicates an expected call of XAdd.
+func (mr *MockInterfaceMockRecorder) XAdd(ctx, stream, maxLen, values interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XAdd", reflect.TypeOf((*MockInterface)(nil).XAdd), ctx, stream, maxLen, values)
+}
+
+// MockSubscription is a mock of Subscription interface.
+type MockSubscription struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionMockRecorder
+}
+
+// MockSubscriptionMockRecorder is the mock recorder for MockSubscription.
+type MockSubscriptionMockRecorder struct {
+	mock *MockSubscription
+}
+
+// NewMockSubscription creates a new mock instance.
+func NewMockSubscription(ctrl *gomock.Controller) *MockSubscription {
+	mock := &MockSubscription{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscription) EXPECT() *MockSubscriptionMockRecorder {
+	return m.recorder
+}
+
+// Channel mocks base method.
+func (m *MockSubscription) Channel() <-chan *redis.Message {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Channel")
+	ret0, _ := ret[0].(<-chan *redis.Message)
+	return ret0
+}
+
+// Channel indicates an expected call of Channel.
+func (mr *MockSubscriptionMockRecorder) Channel() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Channel", reflect.TypeOf((*MockSubscription)(nil).Channel))
+}
+
+// Close mocks base method.
+func (m *MockSubscription) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockSubscriptionMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSubscription)(nil).Close))
+}
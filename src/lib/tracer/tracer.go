@@ -0,0 +1,136 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	instrumentationName = "go-clean"
+)
+
+type Interface interface {
+	Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span)
+	Extract(ctx context.Context, header http.Header) context.Context
+	GormPlugin() gorm.Plugin
+	RedisHook() redis.Hook
+	Shutdown(ctx context.Context) error
+}
+
+type Config struct {
+	Enabled     bool
+	ServiceName string
+	// Exporter is either stdout or otlp.
+	Exporter string
+	// SampleRatio is the fraction of new traces that are sampled, traces
+	// started by a sampled parent are always kept.
+	SampleRatio float64
+	OTLP        OTLPConfig
+}
+
+type OTLPConfig struct {
+	Endpoint string
+	Insecure bool
+}
+
+type tracer struct {
+	conf       Config
+	provider   trace.TracerProvider
+	shutdown   func(ctx context.Context) error
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func Init(cfg Config) Interface {
+	t := &tracer{
+		conf:       cfg,
+		provider:   trace.NewNoopTracerProvider(),
+		shutdown:   func(ctx context.Context) error { return nil },
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+
+	if cfg.Enabled {
+		t.connect()
+	}
+
+	t.tracer = t.provider.Tracer(instrumentationName)
+
+	return t
+}
+
+func (t *tracer) connect() {
+	exporter, err := t.newExporter()
+	if err != nil {
+		panic(err)
+	}
+
+	serviceName := t.conf.ServiceName
+	if serviceName == "" {
+		serviceName = instrumentationName
+	}
+
+	sampleRatio := t.conf.SampleRatio
+	if sampleRatio <= 0 || sampleRatio > 1 {
+		sampleRatio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+		)),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(t.propagator)
+
+	t.provider = provider
+	t.shutdown = provider.Shutdown
+}
+
+func (t *tracer) newExporter() (sdktrace.SpanExporter, error) {
+	switch t.conf.Exporter {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(t.conf.OTLP.Endpoint),
+		}
+		if t.conf.OTLP.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(context.Background(), opts...)
+	default:
+		return nil, errors.New("unknown tracer exporter " + t.conf.Exporter)
+	}
+}
+
+func (t *tracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, spanName, opts...)
+}
+
+func (t *tracer) Extract(ctx context.Context, header http.Header) context.Context {
+	return t.propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+func (t *tracer) Shutdown(ctx context.Context) error {
+	return t.shutdown(ctx)
+}
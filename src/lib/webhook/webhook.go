@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-clean/src/lib/metrics"
+	"go-clean/src/lib/tracer"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	HeaderEvent     = "X-Synapsis-Event"
+	HeaderDelivery  = "X-Synapsis-Delivery"
+	HeaderTimestamp = "X-Synapsis-Timestamp"
+	// HeaderSignature is sha256=<hex hmac of "<timestamp>.<body>">, receivers
+	// recompute it with the shared secret and reject stale timestamps.
+	HeaderSignature = "X-Synapsis-Signature"
+
+	// maxResponseBody caps the response body kept for the delivery log.
+	maxResponseBody = 1024
+)
+
+type Interface interface {
+	// Send posts the signed body. A response is returned for every status,
+	// err is only set when no response was received.
+	Send(ctx context.Context, req Request) (Response, error)
+}
+
+type Config struct {
+	Timeout   time.Duration
+	UserAgent string
+}
+
+type Request struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID uint
+	Body       []byte
+}
+
+type Response struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// OK reports whether the receiver accepted the delivery.
+func (r Response) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+type webhook struct {
+	conf    Config
+	metrics metrics.Interface
+	tracer  tracer.Interface
+	client  *http.Client
+}
+
+func Init(cfg Config, metrics metrics.Interface, tracer tracer.Interface) Interface {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = "Synapsis-Webhook/1.0"
+	}
+
+	w := &webhook{
+		conf:    cfg,
+		metrics: metrics,
+		tracer:  tracer,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+
+	return w
+}
+
+// Sign is the signature of body sent at timestamp, in the format of
+// HeaderSignature.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *webhook) Send(ctx context.Context, param Request) (Response, error) {
+	ctx, span := w.tracer.Start(ctx, "webhook.Send", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("webhook.event_type", param.EventType), attribute.Int("webhook.delivery_id", int(param.DeliveryID))))
+	defer span.End()
+
+	result := Response{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, param.URL, bytes.NewReader(param.Body))
+	if err != nil {
+		return result, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", w.conf.UserAgent)
+	req.Header.Set(HeaderEvent, param.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(param.DeliveryID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(param.Secret, timestamp, param.Body))
+
+	start := time.Now()
+	resp, err := w.client.Do(req)
+	result.Duration = time.Since(start)
+	if err == nil {
+		defer resp.Body.Close()
+
+		result.StatusCode = resp.StatusCode
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		result.Body = string(body)
+
+		if !result.OK() {
+			err = fmt.Errorf("webhook receiver answered %d", resp.StatusCode)
+		}
+	}
+
+	w.metrics.ObserveWebhookDelivery(param.EventType, result.Duration, err)
+	span.SetAttributes(attribute.Int("http.status_code", result.StatusCode))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if result.StatusCode == 0 {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -1,18 +1,40 @@
 package config
 
 import (
+	"go-clean/src/business/domain"
+	"go-clean/src/business/usecase"
+	"go-clean/src/lib/auth"
+	"go-clean/src/lib/i18n"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/metrics"
 	"go-clean/src/lib/midtrans"
+	"go-clean/src/lib/migration"
+	"go-clean/src/lib/pdf"
 	"go-clean/src/lib/redis"
+	"go-clean/src/lib/shipping"
 	"go-clean/src/lib/sql"
+	"go-clean/src/lib/tracer"
+	"go-clean/src/lib/webhook"
 	"time"
 )
 
 type Application struct {
-	Meta     ApplicationMeta
-	Gin      GinConfig
-	SQL      sql.Config
-	Midtrans midtrans.Config
-	Redis    redis.Config
+	Meta      ApplicationMeta
+	Log       log.Config
+	Metrics   metrics.Config
+	Tracer    tracer.Config
+	Gin       GinConfig
+	SQL       sql.Config
+	Migration migration.Config
+	Midtrans  midtrans.Config
+	Redis     redis.Config
+	Auth      auth.Config
+	Webhook   webhook.Config
+	Shipping  shipping.Config
+	PDF       pdf.Config
+	I18n      i18n.Config
+	Domain    domain.Config
+	Usecase   usecase.Config
 }
 
 type ApplicationMeta struct {
@@ -28,7 +50,11 @@ type GinConfig struct {
 	Mode            string
 	Timeout         time.Duration
 	ShutdownTimeout time.Duration
+	LogRequest      bool
+	LogResponse     bool
 	CORS            CORSConfig
+	RateLimit       RateLimitConfig
+	Health          HealthConfig
 	Meta            ApplicationMeta
 }
 
@@ -36,6 +62,27 @@ type CORSConfig struct {
 	Mode string
 }
 
+type HealthConfig struct {
+	// Timeout bounds all readiness checks together.
+	Timeout      time.Duration
+	CheckGateway bool
+	// ShutdownDelay is how long readiness fails before the server stops
+	// accepting connections.
+	ShutdownDelay time.Duration
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	// Groups maps a route group name to its rule, the "default" rule applies
+	// to groups that are not listed.
+	Groups map[string]RateLimitRule
+}
+
+type RateLimitRule struct {
+	Limit  int64
+	Window time.Duration
+}
+
 func Init() Application {
 	return Application{}
 }
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"go-clean/src/lib/i18n"
+	"go-clean/src/lib/log"
+	"go-clean/src/lib/shipping"
+	"go-clean/src/lib/tracer"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Validate reports every missing or invalid field at once, so a bad deploy
+// fails at startup with a readable message.
+func (a Application) Validate() error {
+	problems := []string{}
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(oneOf(a.Log.Level, "", "trace", "debug", "info", "warn", "error", "fatal", "disabled"), "Log.Level %q is not a valid level", a.Log.Level)
+	check(oneOf(a.Log.Format, "", log.FormatJSON, log.FormatConsole), "Log.Format must be %s or %s", log.FormatJSON, log.FormatConsole)
+
+	check(oneOf(a.Gin.Mode, "", gin.DebugMode, gin.ReleaseMode, gin.TestMode), "Gin.Mode %q is not a valid mode", a.Gin.Mode)
+	check(a.Gin.ShutdownTimeout >= 0, "Gin.ShutdownTimeout must not be negative")
+	check(a.Gin.Health.ShutdownDelay >= 0, "Gin.Health.ShutdownDelay must not be negative")
+	if a.Gin.RateLimit.Enabled {
+		for group, rule := range a.Gin.RateLimit.Groups {
+			check(rule.Limit > 0 && rule.Window > 0, "Gin.RateLimit.Groups.%s needs a positive Limit and Window", group)
+		}
+	}
+
+	check(a.SQL.Host != "", "SQL.Host is required")
+	check(a.SQL.Port != "", "SQL.Port is required")
+	check(a.SQL.Username != "", "SQL.Username is required")
+	check(a.SQL.Database != "", "SQL.Database is required")
+
+	check(a.Redis.Host != "", "Redis.Host is required")
+	check(a.Redis.Port != "", "Redis.Port is required")
+
+	check(a.Midtrans.ServerKey != "", "Midtrans.ServerKey is required")
+
+	check(oneOf(a.I18n.DefaultLanguage, "", i18n.LanguageEnglish, i18n.LanguageIndonesian), "I18n.DefaultLanguage must be %s or %s", i18n.LanguageEnglish, i18n.LanguageIndonesian)
+
+	check(a.Domain.Product.CacheTTL >= 0 && a.Domain.Product.CacheStaleTTL >= 0, "Domain.Product cache TTLs must not be negative")
+	check(a.Domain.Category.CacheTTL >= 0 && a.Domain.Category.CacheStaleTTL >= 0, "Domain.Category cache TTLs must not be negative")
+
+	check(a.Domain.EventStream.MaxLen >= 0, "Domain.EventStream.MaxLen must not be negative")
+	check(a.Domain.StatusStream.Buffer >= 0, "Domain.StatusStream.Buffer must not be negative")
+	event := a.Usecase.Event
+	check(event.PollInterval >= 0 && event.Lease >= 0 && event.RetryBackoff >= 0 && event.MaxRetryBackoff >= 0, "Usecase.Event durations must not be negative")
+	check(event.BatchSize >= 0 && event.MaxAttempts >= 0, "Usecase.Event.BatchSize and MaxAttempts must not be negative")
+
+	check(a.Webhook.Timeout >= 0, "Webhook.Timeout must not be negative")
+	webhook := a.Usecase.Webhook
+	check(webhook.PollInterval >= 0 && webhook.Lease >= 0 && webhook.RetryBackoff >= 0 && webhook.MaxRetryBackoff >= 0, "Usecase.Webhook durations must not be negative")
+	check(webhook.BatchSize >= 0 && webhook.MaxAttempts >= 0, "Usecase.Webhook.BatchSize and MaxAttempts must not be negative")
+
+	check(oneOf(a.Shipping.Calculator, "", shipping.CalculatorFlat, shipping.CalculatorWeight), "Shipping.Calculator must be %s or %s", shipping.CalculatorFlat, shipping.CalculatorWeight)
+	for i, rate := range a.Shipping.Rates {
+		check(rate.Courier != "" && rate.Service != "", "Shipping.Rates[%d] needs a Courier and a Service", i)
+		check(rate.Price >= 0, "Shipping.Rates[%d].Price must not be negative", i)
+	}
+
+	check(len(a.Domain.Invoice.NumberPrefix) <= 20, "Domain.Invoice.NumberPrefix must not be longer than 20 characters")
+	check(a.Usecase.Invoice.TaxRate >= 0 && a.Usecase.Invoice.TaxRate < 100, "Usecase.Invoice.TaxRate must be a percentage from 0 to less than 100")
+
+	if a.Tracer.Enabled {
+		check(oneOf(a.Tracer.Exporter, tracer.ExporterStdout, tracer.ExporterOTLP), "Tracer.Exporter must be %s or %s", tracer.ExporterStdout, tracer.ExporterOTLP)
+		check(a.Tracer.Exporter != tracer.ExporterOTLP || a.Tracer.OTLP.Endpoint != "", "Tracer.OTLP.Endpoint is required for the otlp exporter")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	return nil
+}
+
+// ValidateReload checks a reloaded config against the running one. Besides
+// Validate it rejects any change of the SQL settings, the connection pool is
+// only opened on start and a partial switch of credentials is never wanted.
+func (a Application) ValidateReload(next Application) error {
+	if err := next.Validate(); err != nil {
+		return err
+	}
+
+	if next.SQL != a.SQL {
+		return errors.New("SQL settings can not be changed without a restart")
+	}
+
+	return nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}